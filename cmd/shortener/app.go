@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/unwale/url-shortener/internal/config"
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/jobs"
+	"github.com/unwale/url-shortener/internal/linkcheck"
+	"github.com/unwale/url-shortener/internal/metadata"
+	"github.com/unwale/url-shortener/internal/outbox"
+	"github.com/unwale/url-shortener/internal/privacy"
+	"github.com/unwale/url-shortener/internal/safehttp"
+	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/targeting"
+	"github.com/unwale/url-shortener/internal/webhook"
+)
+
+type app struct {
+	cfg          *config.Config
+	logger       *slog.Logger
+	pool         *pgxpool.Pool
+	replicas     []*repository.Replica
+	replicaPools []*pgxpool.Pool
+	sqlite       *sql.DB
+	redisClient  *redis.Client
+	urlCache     cache.URLCache
+	urlService   service.URLService
+	transfers    service.TransferService
+	caches       service.CacheService
+	relay        *outbox.Relay
+	webhooks     service.WebhookService
+	dashboards   service.DashboardService
+	accounts     service.AccountService
+	visitors     repository.VisitorRepository
+	anonymizer   *privacy.IPAnonymizer
+	hookWorker   *webhook.Worker
+	routing      service.RoutingService
+	geo          *targeting.GeoDB
+	linkHealth   service.LinkHealthService
+	metadata     *metadata.Fetcher
+	previews     service.PreviewService
+	jobs         *jobs.Runner
+}
+
+func newApp(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*app, error) {
+	a := &app{
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	if err := a.connectRedis(ctx); err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	anonymizer, err := a.newIPAnonymizer()
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	a.anonymizer = anonymizer
+
+	urlRepository, err := a.openRepository(ctx)
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	var urlOpts []service.URLServiceOption
+	var transferOpts []service.TransferServiceOption
+	var webhookRepository repository.WebhookRepository
+	if a.pool != nil && len(cfg.APIKeys) > 0 {
+		webhookRepository = a.setupWebhooks()
+		a.dashboards = service.NewDashboardService(repository.NewDashboardRepository(a.pool, a.replicas...))
+	}
+
+	if len(cfg.OutboxSinks) > 0 || webhookRepository != nil {
+		tx, events, err := a.setupOutbox(webhookRepository)
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		urlOpts = append(urlOpts, service.WithURLEvents(tx, events))
+		transferOpts = append(transferOpts, service.WithTransferEvents(tx, events))
+	}
+
+	if a.pool != nil {
+		if err := a.setupRouting(urlRepository); err != nil {
+			a.Close()
+			return nil, err
+		}
+		urlOpts = append(urlOpts, service.WithRouting(a.routing),
+			service.WithAnalytics(a.setupAnalytics()))
+		transferOpts = append(transferOpts, service.WithTransferTx(repository.NewTxManager(a.pool)))
+		opt, err := a.setupLinkHealth()
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		linkMetadata := repository.NewLinkMetadataRepository(a.pool)
+		urlOpts = append(urlOpts, opt, a.setupLinkMetadata(linkMetadata))
+		a.previews = service.NewPreviewService(repository.NewLinkPreviewRepository(a.pool), urlRepository, linkMetadata)
+	} else if cfg.GeoIPDatabase != "" {
+		logger.Warn("Routing rules require PostgreSQL storage, ignoring GEOIP_DATABASE")
+	}
+
+	if a.redisClient != nil {
+		if opt := a.setupUniqueVisitors(ctx); opt != nil {
+			urlOpts = append(urlOpts, opt)
+		}
+	}
+
+	a.urlCache = a.newURLCache()
+	a.urlService = service.NewURLService(urlRepository, a.urlCache, logger, urlOpts...)
+	a.transfers = service.NewTransferService(urlRepository, a.urlCache, logger, transferOpts...)
+	a.caches = service.NewCacheService(urlRepository, a.urlCache, logger)
+	if a.pool != nil && len(cfg.APIKeys) > 0 {
+		a.accounts = service.NewAccountService(repository.NewAccountRepository(a.pool), a.urlCache, a.visitors, logger)
+	}
+	return a, nil
+}
+
+// newIPAnonymizer anonymizes client addresses as IP_ANONYMIZATION says. Hash
+// salts are shared through Redis when it is configured, so that every
+// instance hashes an address the same way.
+func (a *app) newIPAnonymizer() (*privacy.IPAnonymizer, error) {
+	salts := privacy.NewLocalSalts()
+	if a.redisClient != nil {
+		salts = func(ctx context.Context, period time.Time) ([]byte, error) {
+			return repository.LoadIPSalt(ctx, a.redisClient, period, a.cfg.IPHashSaltRotation)
+		}
+	}
+	return privacy.NewIPAnonymizer(a.cfg.IPAnonymization, a.cfg.IPHashSaltRotation, salts, a.logger)
+}
+
+// connectRedis connects to REDIS_URL when it is set. Links are still served
+// while Redis is unreachable unless they are stored in it.
+func (a *app) connectRedis(ctx context.Context) error {
+	if a.cfg.RedisURL == "" {
+		if a.cfg.StorageBackend == repository.StorageRedis {
+			return fmt.Errorf("REDIS_URL is required when STORAGE_BACKEND is %s", repository.StorageRedis)
+		}
+		a.logger.Info("REDIS_URL is not set, caching is disabled")
+		return nil
+	}
+
+	a.logger.Info("Connecting to Redis cache")
+	a.redisClient = redis.NewClient(&redis.Options{
+		Addr: a.cfg.RedisURL,
+		DB:   0, // use default DB
+	})
+	if err := a.redisClient.Ping(ctx).Err(); err != nil {
+		if a.cfg.StorageBackend == repository.StorageRedis {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		a.logger.Warn("Redis is unavailable, serving without cache until it recovers", "error", err)
+		return nil
+	}
+	a.logger.Info("Connected to Redis cache")
+	return nil
+}
+
+func (a *app) newURLCache() cache.URLCache {
+	if a.redisClient == nil {
+		return cache.NewNoopURLCache()
+	}
+
+	var urlCache cache.URLCache = cache.NewCircuitBreakerURLCache(cache.NewRedisURLCache(a.redisClient),
+		cache.CircuitBreakerOptions{
+			FailureThreshold: a.cfg.CacheBreakerFailures,
+			Cooldown:         a.cfg.CacheBreakerCooldown,
+			Timeout:          a.cfg.CacheTimeout,
+		}, a.logger)
+	if a.cfg.LocalCacheSize > 0 {
+		urlCache = cache.NewLayeredURLCache(
+			cache.NewMemoryURLCache(a.cfg.LocalCacheSize), urlCache, a.redisClient, a.cfg.LocalCacheTTL)
+	}
+	return urlCache
+}
+
+func (a *app) openRepository(ctx context.Context) (repository.URLRepository, error) {
+	switch a.cfg.StorageBackend {
+	case repository.StoragePostgres:
+		if a.cfg.PostgresURL == "" {
+			return nil, fmt.Errorf("POSTGRES_URL is required when STORAGE_BACKEND is %s", repository.StoragePostgres)
+		}
+		a.logger.Info("Connecting to PostgreSQL database")
+		pool, err := pgxpool.New(ctx, a.cfg.PostgresURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		a.pool = pool
+		a.logger.Info("Connected to PostgreSQL database")
+
+		for i, url := range a.cfg.PostgresReplicaURLs {
+			replicaPool, err := pgxpool.New(ctx, url)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to PostgreSQL replica %d: %w", i+1, err)
+			}
+			a.replicaPools = append(a.replicaPools, replicaPool)
+			a.replicas = append(a.replicas, repository.NewReplica(fmt.Sprintf("replica-%d", i+1), replicaPool))
+		}
+		if len(a.replicas) > 0 {
+			a.logger.Info("Routing reads to PostgreSQL replicas", "replicas", len(a.replicas))
+		}
+		return repository.NewURLRepository(pool, a.replicas...), nil
+	case repository.StorageSQLite:
+		a.logger.Info("Opening SQLite database", "path", a.cfg.SQLitePath)
+		conn, err := repository.OpenSQLite(ctx, a.cfg.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+		}
+		a.sqlite = conn
+		return repository.NewSQLiteURLRepository(conn), nil
+	case repository.StorageRedis:
+		a.logger.Info("Storing links in Redis")
+		return repository.NewRedisURLRepository(a.redisClient), nil
+	case repository.StorageMemory:
+		a.logger.Warn("Storing links in memory; they will be lost when the process exits")
+		return repository.NewMemoryURLRepository(), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q, expected %s, %s, %s or %s", a.cfg.StorageBackend,
+			repository.StoragePostgres, repository.StorageSQLite, repository.StorageRedis, repository.StorageMemory)
+	}
+}
+
+// setupRouting lets links send visitors to other destinations by device,
+// operating system, language and, when GEOIP_DATABASE is set, country, and
+// split their traffic between weighted variants.
+func (a *app) setupRouting(urls repository.URLRepository) error {
+	a.routing = service.NewRoutingService(repository.NewRoutingRuleRepository(a.pool), repository.NewVariantRepository(a.pool),
+		urls, repository.NewTxManager(a.pool), a.cfg.RoutingCacheSize, a.cfg.RoutingCacheTTL)
+	if a.cfg.GeoIPDatabase == "" {
+		a.logger.Info("GEOIP_DATABASE is not set, country routing conditions will not match")
+		return nil
+	}
+
+	geo, err := targeting.OpenGeoDB(a.cfg.GeoIPDatabase)
+	if err != nil {
+		return fmt.Errorf("failed to load GeoIP database: %w", err)
+	}
+	a.geo = geo
+	a.logger.Info("Loaded GeoIP database", "path", a.cfg.GeoIPDatabase, "ranges", geo.Len())
+	return nil
+}
+
+// setupAnalytics records raw click events and prepares the jobs that roll
+// them up into the counts stats are served from and prune expired ones.
+func (a *app) setupAnalytics() repository.AnalyticsRepository {
+	analytics := repository.NewAnalyticsRepository(a.pool)
+	clicks := jobs.NewClicks(analytics, jobs.ClickOptions{
+		EventRetention:  a.cfg.ClickEventRetention,
+		HourlyRetention: a.cfg.ClickHourlyRetention,
+	}, a.logger)
+	a.jobs = jobs.NewRunner(a.logger,
+		jobs.Job{Name: "click-rollup", Interval: a.cfg.ClickRollupInterval, Run: clicks.RollUp},
+		jobs.Job{Name: "click-prune", Interval: jobs.PruneInterval, Run: clicks.Prune},
+	)
+	return analytics
+}
+
+// setupLinkHealth reports the health of link destinations and, with
+// LINK_CHECK_INTERVAL, checks them in the background. With LINK_FALLBACK_URL,
+// visitors of broken links are sent there instead.
+func (a *app) setupLinkHealth() (service.URLServiceOption, error) {
+	fallback := a.cfg.LinkFallbackURL
+	if fallback != "" {
+		if u, err := url.Parse(fallback); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("LINK_FALLBACK_URL must be an absolute http(s) URL, got %q", fallback)
+		}
+	}
+
+	linkHealth := repository.NewLinkHealthRepository(a.pool)
+	a.linkHealth = service.NewLinkHealthService(linkHealth)
+	if a.cfg.LinkCheckInterval > 0 {
+		checker := linkcheck.NewChecker(linkHealth, safehttp.NewClient(a.cfg.LinkCheckTimeout), linkcheck.Options{
+			BatchSize:    int32(a.cfg.LinkCheckBatchSize),
+			Concurrency:  a.cfg.LinkCheckConcurrency,
+			HostInterval: a.cfg.LinkCheckHostInterval,
+			Lease:        linkcheck.DefaultLease,
+			MaxAge:       a.cfg.LinkCheckMaxAge,
+		}, a.logger)
+		a.jobs.Add(jobs.Job{Name: "link-check", Interval: a.cfg.LinkCheckInterval, Run: checker.Run})
+	}
+	if fallback != "" {
+		a.jobs.Add(jobs.Job{Name: "broken-links", Interval: service.BrokenLinksReloadInterval, Run: a.linkHealth.LoadBrokenLinks})
+	}
+	return service.WithLinkHealth(a.linkHealth, fallback), nil
+}
+
+// setupLinkMetadata reports the metadata of link destinations and, unless
+// METADATA_FETCH_WORKERS is 0, fetches it when links are created.
+func (a *app) setupLinkMetadata(repo repository.LinkMetadataRepository) service.URLServiceOption {
+	if a.cfg.MetadataFetchWorkers <= 0 {
+		return service.WithLinkMetadata(nil, repo)
+	}
+	a.metadata = metadata.NewFetcher(repo, safehttp.NewClient(a.cfg.MetadataFetchTimeout), metadata.Options{
+		Workers:   a.cfg.MetadataFetchWorkers,
+		QueueSize: metadata.DefaultQueueSize,
+		MaxBytes:  a.cfg.MetadataMaxBytes,
+	}, a.logger)
+	return service.WithLinkMetadata(a.metadata, repo)
+}
+
+// setupUniqueVisitors counts the unique visitors of links in Redis. Without
+// VISITOR_HASH_SECRET the instances share a secret generated in Redis, so
+// visitors are only left uncounted when Redis is unreachable at startup.
+func (a *app) setupUniqueVisitors(ctx context.Context) service.URLServiceOption {
+	secret := a.cfg.VisitorHashSecret
+	if secret == "" {
+		var err error
+		if secret, err = repository.LoadVisitorSecret(ctx, a.redisClient); err != nil {
+			a.logger.Warn("Failed to load visitor hash secret, unique visitors are not counted", "error", err)
+			return nil
+		}
+	}
+	a.visitors = repository.NewRedisVisitorRepository(a.redisClient)
+	return service.WithUniqueVisitors(a.visitors, secret)
+}
+
+// setupWebhooks lets API key owners register webhooks for the events of
+// their links and prepares the worker that delivers them.
+func (a *app) setupWebhooks() repository.WebhookRepository {
+	webhookRepository := repository.NewWebhookRepository(a.pool)
+	a.webhooks = service.NewWebhookService(webhookRepository)
+	client := safehttp.NewClient(a.cfg.WebhookTimeout)
+	// A redirect is reported as a failed delivery rather than followed.
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	a.hookWorker = webhook.NewWorker(webhookRepository, client, webhook.WorkerOptions{
+		BatchSize:    int32(a.cfg.WebhookBatchSize),
+		PollInterval: a.cfg.WebhookPollInterval,
+		Lease:        webhook.DefaultLease,
+		MaxAttempts:  a.cfg.WebhookMaxAttempts,
+	}, a.logger)
+	return webhookRepository
+}
+
+// setupOutbox records link events in the Postgres outbox and prepares the
+// relay that delivers them to OUTBOX_SINKS and, when hooks is set, to the
+// webhooks registered by link owners.
+func (a *app) setupOutbox(hooks repository.WebhookRepository) (repository.TxManager, service.EventPublisher, error) {
+	if a.pool == nil {
+		return nil, nil, fmt.Errorf("OUTBOX_SINKS requires STORAGE_BACKEND %s", repository.StoragePostgres)
+	}
+
+	sinks := make([]outbox.Sink, 0, len(a.cfg.OutboxSinks))
+	for _, name := range a.cfg.OutboxSinks {
+		switch name {
+		case "webhook":
+			if a.cfg.OutboxWebhookURL == "" {
+				return nil, nil, errors.New("OUTBOX_WEBHOOK_URL is required for the webhook outbox sink")
+			}
+			sinks = append(sinks, outbox.NewWebhookSink(a.cfg.OutboxWebhookURL, &http.Client{Timeout: 10 * time.Second}))
+		case "redis":
+			if a.redisClient == nil {
+				return nil, nil, errors.New("REDIS_URL is required for the redis outbox sink")
+			}
+			sinks = append(sinks, outbox.NewRedisStreamSink(a.redisClient, a.cfg.OutboxRedisStream))
+		case "stdout":
+			sinks = append(sinks, outbox.NewWriterSink(os.Stdout))
+		default:
+			return nil, nil, fmt.Errorf("unsupported outbox sink %q, expected webhook, redis or stdout", name)
+		}
+	}
+
+	if hooks != nil {
+		sinks = append(sinks, webhook.NewDispatcher(hooks))
+	}
+	names := make([]string, 0, len(sinks))
+	for _, sink := range sinks {
+		names = append(names, sink.Name())
+	}
+
+	outboxRepository := repository.NewOutboxRepository(a.pool)
+	a.relay = outbox.NewRelay(outboxRepository, sinks, outbox.RelayOptions{
+		BatchSize:    int32(a.cfg.OutboxBatchSize),
+		PollInterval: a.cfg.OutboxPollInterval,
+		Lease:        outbox.DefaultLease,
+		MaxAttempts:  int32(a.cfg.OutboxMaxAttempts),
+		Retention:    a.cfg.OutboxRetention,
+	}, a.logger)
+	a.logger.Info("Publishing link events", "sinks", names)
+	return repository.NewTxManager(a.pool), service.NewOutboxPublisher(outboxRepository), nil
+}
+
+func (a *app) Close() {
+	if a.redisClient != nil {
+		if err := a.redisClient.Close(); err != nil {
+			a.logger.Error("Failed to close Redis connection", "error", err)
+		}
+	}
+	if a.sqlite != nil {
+		if err := a.sqlite.Close(); err != nil {
+			a.logger.Error("Failed to close SQLite database", "error", err)
+		}
+	}
+	for _, pool := range a.replicaPools {
+		pool.Close()
+	}
+	if a.pool != nil {
+		a.pool.Close()
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/config"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/transfer"
+)
+
+type adminFlags struct {
+	fs     *flag.FlagSet
+	output *string
+}
+
+func newAdminFlags(name string) *adminFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &adminFlags{
+		fs:     fs,
+		output: fs.String("o", outputTable, "output format: table or json"),
+	}
+}
+
+func (f *adminFlags) parse(args []string) error {
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if *f.output != outputTable && *f.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q", *f.output)
+	}
+	return nil
+}
+
+func (f *adminFlags) code() (string, error) {
+	if f.fs.NArg() != 1 {
+		return "", errors.New("exactly one short code is required")
+	}
+	return f.fs.Arg(0), nil
+}
+
+func withAdminApp(ctx context.Context, fn func(a *app) error) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+
+	a, err := newApp(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return fn(a)
+}
+
+func runCreate(ctx context.Context, args []string) error {
+	flags := newAdminFlags("create")
+	originalURL := flags.fs.String("url", "", "destination URL")
+	alias := flags.fs.String("alias", "", "custom short code")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	if *originalURL == "" {
+		return errors.New("-url is required")
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		shortURL, err := a.urlService.CreateShortURL(ctx, *originalURL, *alias)
+		if err != nil {
+			return err
+		}
+		return printShortened(os.Stdout, *flags.output, model.ShortenURLResponse{ShortURL: shortURL})
+	})
+}
+
+func runGet(ctx context.Context, args []string) error {
+	flags := newAdminFlags("get")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	code, err := flags.code()
+	if err != nil {
+		return err
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		url, err := a.urlService.GetShortURLStats(ctx, code)
+		if err != nil {
+			return err
+		}
+		return printLink(os.Stdout, *flags.output, toLinkOutput(url))
+	})
+}
+
+func runDelete(ctx context.Context, args []string) error {
+	flags := newAdminFlags("delete")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	code, err := flags.code()
+	if err != nil {
+		return err
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		if err := a.urlService.DeleteShortURL(ctx, code); err != nil {
+			return err
+		}
+		return printDeleted(os.Stdout, *flags.output, code)
+	})
+}
+
+func runList(ctx context.Context, args []string) error {
+	flags := newAdminFlags("list")
+	limit := flags.fs.Int("limit", 50, "maximum number of links to list")
+	offset := flags.fs.Int("offset", 0, "number of links to skip")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		urls, err := a.urlService.ListShortURLs(ctx, *limit, *offset)
+		if err != nil {
+			return err
+		}
+		return printStatsList(os.Stdout, *flags.output, toStatsResponses(urls))
+	})
+}
+
+func runStats(ctx context.Context, args []string) error {
+	flags := newAdminFlags("stats")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	code, err := flags.code()
+	if err != nil {
+		return err
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		url, err := a.urlService.GetShortURLStats(ctx, code)
+		if err != nil {
+			return err
+		}
+		return printStatsList(os.Stdout, *flags.output, toStatsResponses([]*domain.Url{url}))
+	})
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatName := fs.String("format", string(transfer.FormatNDJSON), "file format: csv or ndjson")
+	path := fs.String("file", "-", "file to write to, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, err := transfer.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	return withAdminApp(ctx, func(a *app) error {
+		w, closeFn, err := openOutput(*path)
+		if err != nil {
+			return err
+		}
+		defer closeFn()
+
+		encoder := transfer.NewEncoder(w, format)
+		if err := a.transfers.ExportURLs(ctx, encoder.Encode); err != nil {
+			return err
+		}
+		return encoder.Flush()
+	})
+}
+
+func runImport(ctx context.Context, args []string) error {
+	flags := newAdminFlags("import")
+	formatName := flags.fs.String("format", string(transfer.FormatNDJSON), "file format: csv or ndjson")
+	policyName := flags.fs.String("policy", string(service.ConflictSkip), "conflict policy: skip, overwrite or fail")
+	dryRun := flags.fs.Bool("dry-run", false, "report what would change without writing")
+	path := flags.fs.String("file", "-", "file to read from, - for stdin")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	format, err := transfer.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	policy, err := service.ParseConflictPolicy(*policyName)
+	if err != nil {
+		return err
+	}
+
+	r, closeFn, err := openInput(*path)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	return withAdminApp(ctx, func(a *app) error {
+		report, err := a.transfers.ImportURLs(ctx, transfer.NewDecoder(r, format), service.ImportOptions{
+			Policy: policy,
+			DryRun: *dryRun,
+		})
+		if err != nil {
+			return err
+		}
+		if err := printImportReport(os.Stdout, *flags.output, report); err != nil {
+			return err
+		}
+		if report.Aborted {
+			return errors.New("import aborted on conflicting short code")
+		}
+		return nil
+	})
+}
+
+func toLinkOutput(url *domain.Url) linkOutput {
+	return linkOutput{
+		ShortURL:    url.ShortUrl,
+		OriginalURL: url.OriginalUrl,
+		CreatedAt:   url.CreatedAt,
+	}
+}
+
+func toStatsResponses(urls []*domain.Url) []model.ShortUrlStatsResponse {
+	responses := make([]model.ShortUrlStatsResponse, 0, len(urls))
+	for _, url := range urls {
+		responses = append(responses, model.ShortUrlStatsResponse{
+			ShortURL:    url.ShortUrl,
+			OriginalURL: url.OriginalUrl,
+			ClickCount:  int(url.ClickCount),
+			CreatedAt:   url.CreatedAt,
+			UpdatedAt:   url.UpdatedAt,
+		})
+	}
+	return responses
+}
+
+func openInput(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { _ = f.Close() }, nil
+}
+
+func openOutput(path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return os.Stdout, func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { _ = f.Close() }, nil
+}
@@ -3,99 +3,74 @@ package main
 import (
 	"context"
 	"errors"
-	"log/slog"
-	"net/http"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
+)
 
-	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
 
-	"github.com/unwale/url-shortener/internal/api/handler"
-	"github.com/unwale/url-shortener/internal/api/middleware"
-	"github.com/unwale/url-shortener/internal/config"
-	"github.com/unwale/url-shortener/internal/domain/cache"
-	"github.com/unwale/url-shortener/internal/domain/repository"
-	"github.com/unwale/url-shortener/internal/service"
-)
+var commands = []command{
+	{"serve", "serve", "Run the HTTP server (default)", runServe},
+	{"create", "create -url <url> [-alias <alias>]", "Create a short link", runCreate},
+	{"get", "get <code>", "Show the destination of a short link", runGet},
+	{"delete", "delete <code>", "Delete a short link", runDelete},
+	{"list", "list [-limit n] [-offset n]", "List short links", runList},
+	{"stats", "stats <code>", "Show click statistics of a short link", runStats},
+	{"import", "import [-format f] [-policy p] [-dry-run] [-file path]", "Import links from CSV or NDJSON", runImport},
+	{"export", "export [-format f] [-file path]", "Export links as CSV or NDJSON", runExport},
+}
 
 func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
-	slog.SetDefault(logger)
-	slog.Info("Starting URL Shortener Service")
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	cancel()
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		logger.Error("Failed to load configuration", "error", err)
-		panic(err)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
 	}
-
-	ctx := context.Background()
-
-	logger.Info("Connecting to PostgreSQL database")
-	dbURL := cfg.PostgresURL
-	conn, err := pgxpool.New(ctx, dbURL)
 	if err != nil {
-		logger.Error("Failed to connect to PostgreSQL", "error", err)
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
-	defer conn.Close()
-	logger.Info("Connected to PostgreSQL database")
+}
 
-	logger.Info("Connecting to Redis cache")
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: cfg.RedisURL,
-		DB:   0, // use default DB
-	})
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		logger.Error("Failed to connect to Redis", "error", err)
-		os.Exit(1)
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return runServe(ctx, nil)
 	}
-	defer func() {
-		if err := redisClient.Close(); err != nil {
-			logger.Error("Failed to close Redis connection", "error", err)
-		}
-	}()
-	logger.Info("Connected to Redis cache")
-
-	urlCache := cache.NewRedisURLCache(redisClient)
-	urlRepository := repository.NewURLRepository(conn)
-	urlService := service.NewURLService(urlRepository, urlCache, logger)
-	urlHandler := handler.NewURLHandler(urlService)
-
-	mux := mux.NewRouter()
-	mux.Use(middleware.LoggingMiddleware)
-	urlHandler.RegisterRoutes(mux)
 
-	stopCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
-	httpServer := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return nil
 	}
 
-	go func() {
-		logger.Info("Starting HTTP server on port 8080")
-		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Error("Failed to start HTTP server", "error", err)
-			os.Exit(1)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(ctx, args[1:])
 		}
-	}()
+	}
 
-	<-stopCtx.Done()
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command %q", name)
+}
 
-	logger.Info("Shutting down server gracefully")
-	timeoutCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-	if err := httpServer.Shutdown(timeoutCtx); err != nil {
-		logger.Error("Failed to shutdown HTTP server", "error", err)
-	} else {
-		logger.Info("HTTP server shut down gracefully")
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: url-shortener <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-56s %s\n", cmd.usage, cmd.description)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Admin commands accept -o table|json to choose the output format.")
 }
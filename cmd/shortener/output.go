@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type linkOutput struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	writeRow(header)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return tw.Flush()
+}
+
+func printShortened(w io.Writer, format string, response model.ShortenURLResponse) error {
+	if format == outputJSON {
+		return printJSON(w, response)
+	}
+	return printTable(w, []string{"SHORT URL"}, [][]string{{response.ShortURL}})
+}
+
+func printLink(w io.Writer, format string, link linkOutput) error {
+	if format == outputJSON {
+		return printJSON(w, link)
+	}
+	return printTable(w,
+		[]string{"SHORT URL", "ORIGINAL URL", "CREATED AT"},
+		[][]string{{link.ShortURL, link.OriginalURL, link.CreatedAt}},
+	)
+}
+
+func printDeleted(w io.Writer, format string, shortURL string) error {
+	if format == outputJSON {
+		return printJSON(w, map[string]string{"short_url": shortURL, "status": "deleted"})
+	}
+	return printTable(w, []string{"SHORT URL", "STATUS"}, [][]string{{shortURL, "deleted"}})
+}
+
+func printStatsList(w io.Writer, format string, stats []model.ShortUrlStatsResponse) error {
+	if format == outputJSON {
+		return printJSON(w, stats)
+	}
+
+	rows := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []string{
+			s.ShortURL,
+			s.OriginalURL,
+			fmt.Sprint(s.ClickCount),
+			s.CreatedAt,
+			s.UpdatedAt,
+		})
+	}
+	return printTable(w, []string{"SHORT URL", "ORIGINAL URL", "CLICKS", "CREATED AT", "UPDATED AT"}, rows)
+}
+
+func printImportReport(w io.Writer, format string, report *domain.ImportReport) error {
+	if format == outputJSON {
+		return printJSON(w, handler.NewImportReportResponse(report))
+	}
+
+	err := printTable(w,
+		[]string{"DRY RUN", "TOTAL", "CREATED", "OVERWRITTEN", "SKIPPED", "FAILED", "ABORTED"},
+		[][]string{{
+			fmt.Sprint(report.DryRun),
+			fmt.Sprint(report.Total),
+			fmt.Sprint(report.Created),
+			fmt.Sprint(report.Overwritten),
+			fmt.Sprint(report.Skipped),
+			fmt.Sprint(report.Failed),
+			fmt.Sprint(report.Aborted),
+		}},
+	)
+	if err != nil || len(report.Errors) == 0 {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	rows := make([][]string, 0, len(report.Errors))
+	for _, e := range report.Errors {
+		rows = append(rows, []string{fmt.Sprint(e.Record), e.ShortUrl, e.Message})
+	}
+	return printTable(w, []string{"RECORD", "SHORT URL", "ERROR"}, rows)
+}
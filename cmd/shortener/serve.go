@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/config"
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+func runServe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger, err := newLogger(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	slog.Info("Starting URL Shortener Service")
+
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	shutdownTracing, err := telemetry.SetupTracing(ctx, a.cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	if layered, ok := a.urlCache.(*cache.LayeredURLCache); ok {
+		go func() {
+			if err := layered.Listen(ctx); err != nil {
+				logger.Error("Cache invalidation listener stopped", "error", err)
+			}
+		}()
+	}
+
+	if a.relay != nil {
+		go a.relay.Run(ctx)
+	}
+	if a.hookWorker != nil {
+		go a.hookWorker.Run(ctx)
+	}
+	if a.metadata != nil {
+		go a.metadata.Run(ctx)
+	}
+	if a.jobs != nil {
+		go a.jobs.Run(ctx)
+	}
+
+	if a.cfg.CacheWarmCount > 0 {
+		if _, err := a.caches.WarmTopURLs(ctx, a.cfg.CacheWarmCount); err != nil {
+			logger.Error("Failed to warm URL cache", "error", err)
+		}
+	}
+
+	var healthChecks []handler.HealthCheck
+	if a.pool != nil {
+		healthChecks = append(healthChecks, handler.HealthCheck{Name: "postgres", Check: a.pool.Ping})
+		prometheus.MustRegister(metrics.NewPgxPoolCollector(a.pool, "primary"))
+	}
+	for i, replica := range a.replicas {
+		go replica.Monitor(ctx, a.cfg.ReplicaCheckInterval, a.cfg.ReplicaMaxLag, logger)
+		healthChecks = append(healthChecks, handler.HealthCheck{
+			Name:     "postgres-" + replica.Name(),
+			Check:    a.replicaPools[i].Ping,
+			Optional: true,
+		})
+		prometheus.MustRegister(metrics.NewPgxPoolCollector(a.replicaPools[i], replica.Name()))
+	}
+	if a.sqlite != nil {
+		healthChecks = append(healthChecks, handler.HealthCheck{Name: "sqlite", Check: a.sqlite.PingContext})
+	}
+	if a.redisClient != nil {
+		healthChecks = append(healthChecks, handler.HealthCheck{
+			Name: "redis",
+			Check: func(ctx context.Context) error {
+				return a.redisClient.Ping(ctx).Err()
+			},
+			Optional: a.cfg.StorageBackend != repository.StorageRedis,
+		})
+		prometheus.MustRegister(metrics.NewRedisPoolCollector(a.redisClient))
+	}
+
+	healthHandler := handler.NewHealthHandler(a.cfg.HealthCheckTimeout, healthChecks...)
+	urlHandler := handler.NewURLHandler(a.urlService, handler.WithGeoDB(a.geo), handler.WithPreviews(a.previews))
+	transferHandler := handler.NewTransferHandler(a.transfers)
+	cacheHandler := handler.NewCacheHandler(a.caches)
+
+	mux := mux.NewRouter()
+	mux.Use(middleware.TracingMiddleware)
+	mux.Use(middleware.NewLoggingMiddleware(middleware.LoggingOptions{
+		SkipPaths:      []string{"/healthz", "/readyz", "/metrics"},
+		TrustedProxies: trustedProxies,
+		SampledRoutes:  []string{handler.ResolveRoute},
+		SampleRate:     cfg.LogRedirectSampleRate,
+		IPAnonymizer:   a.anonymizer,
+	}))
+	mux.Use(middleware.MetricsMiddleware)
+	mux.Use(middleware.RecoveryMiddleware)
+	mux.Use(middleware.NewAPIKeyMiddleware(slices.Concat(a.cfg.APIKeys, a.cfg.AdminAPIKeys)))
+	mux.Use(middleware.NewTimeoutMiddleware(map[string]time.Duration{
+		handler.ShortenRoute:     a.cfg.RequestTimeout,
+		handler.ResolveRoute:     a.cfg.RequestTimeout,
+		handler.StatsRoute:       a.cfg.RequestTimeout,
+		handler.DashboardRoute:   a.cfg.RequestTimeout,
+		handler.BrokenLinksRoute: a.cfg.RequestTimeout,
+	}))
+	mux.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	healthHandler.RegisterRoutes(mux)
+	// Admin routes share the router, but only answer requests with an admin key.
+	admin := mux.NewRoute().Subrouter()
+	admin.Use(middleware.NewAdminKeyMiddleware(a.cfg.AdminAPIKeys))
+	transferHandler.RegisterRoutes(admin)
+	cacheHandler.RegisterRoutes(admin)
+	if a.webhooks != nil {
+		handler.NewWebhookHandler(a.webhooks).RegisterRoutes(mux)
+	}
+	if a.dashboards != nil {
+		handler.NewDashboardHandler(a.dashboards).RegisterRoutes(mux)
+	}
+	if a.accounts != nil {
+		handler.NewAccountHandler(a.accounts).RegisterRoutes(mux)
+	}
+	if a.routing != nil {
+		handler.NewRoutingHandler(a.routing).RegisterRoutes(mux)
+	}
+	if a.linkHealth != nil {
+		handler.NewLinkHealthHandler(a.linkHealth).RegisterRoutes(mux)
+	}
+	if a.previews != nil {
+		handler.NewPreviewHandler(a.previews).RegisterRoutes(mux)
+	}
+	urlHandler.RegisterRoutes(mux)
+
+	httpServer := &http.Server{
+		Addr:    *addr,
+		Handler: mux,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting HTTP server", "addr", *addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		logger.Error("Failed to start HTTP server", "error", err)
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down server gracefully")
+	healthHandler.SetShuttingDown()
+	if a.cfg.ShutdownDelay > 0 {
+		logger.Info("Waiting for load balancers to observe readiness change", "delay", a.cfg.ShutdownDelay)
+		time.Sleep(a.cfg.ShutdownDelay)
+	}
+
+	timeoutCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := httpServer.Shutdown(timeoutCtx); err != nil {
+		logger.Error("Failed to shutdown HTTP server", "error", err)
+	} else {
+		logger.Info("HTTP server shut down gracefully")
+	}
+	return nil
+}
+
+func newLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q, expected text or json", format)
+	}
+}
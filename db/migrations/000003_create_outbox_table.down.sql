@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_pending;
+DROP TABLE IF EXISTS outbox;
\ No newline at end of file
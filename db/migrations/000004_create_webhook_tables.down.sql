@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_pending;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhooks_owner;
+DROP TABLE IF EXISTS webhooks;
+ALTER TABLE outbox DROP COLUMN IF EXISTS owner;
+DROP INDEX IF EXISTS idx_urls_owner;
+ALTER TABLE urls DROP COLUMN IF EXISTS owner;
\ No newline at end of file
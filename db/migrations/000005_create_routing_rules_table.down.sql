@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_routing_rules_short_url;
+DROP TABLE IF EXISTS routing_rules;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS routing_rules (
+    id BIGSERIAL PRIMARY KEY,
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    devices TEXT[] NOT NULL DEFAULT '{}',
+    operating_systems TEXT[] NOT NULL DEFAULT '{}',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    countries TEXT[] NOT NULL DEFAULT '{}',
+    destination TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_routing_rules_short_url ON routing_rules (short_url, priority, id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS link_variants;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS link_variants (
+    id BIGSERIAL PRIMARY KEY,
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    destination TEXT NOT NULL,
+    weight INTEGER NOT NULL CHECK (weight > 0),
+    click_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (short_url, destination)
+);
\ No newline at end of file
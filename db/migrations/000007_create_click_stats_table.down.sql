@@ -0,0 +1 @@
+DROP TABLE IF EXISTS click_stats;
\ No newline at end of file
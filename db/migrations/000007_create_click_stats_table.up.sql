@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS click_stats (
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_url, dimension, value)
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_stats_daily;
+DROP TABLE IF EXISTS click_stats_hourly;
+DROP TABLE IF EXISTS click_events;
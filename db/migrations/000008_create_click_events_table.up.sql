@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS click_events (
+    id BIGSERIAL PRIMARY KEY,
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    clicked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    bot BOOLEAN NOT NULL DEFAULT FALSE,
+    referrer TEXT NOT NULL,
+    browser TEXT NOT NULL,
+    os TEXT NOT NULL,
+    device TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_click_events_clicked_at ON click_events (clicked_at);
+
+CREATE TABLE IF NOT EXISTS click_stats_hourly (
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    hour TIMESTAMP NOT NULL,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_url, hour, dimension, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_click_stats_hourly_hour ON click_stats_hourly (hour);
+
+CREATE TABLE IF NOT EXISTS click_stats_daily (
+    short_url VARCHAR(10) NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_url, day, dimension, value)
+);
+
+-- click_rollup_state holds the single watermark up to which click events
+-- have been added to the click_stats tables.
+CREATE TABLE IF NOT EXISTS click_rollup_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rolled_up_to TIMESTAMP NOT NULL
+);
+
+INSERT INTO click_rollup_state (rolled_up_to) VALUES (CURRENT_TIMESTAMP) ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+-- link_checks holds the latest health check of every link's destination.
+-- checked_url is the destination that was checked, so results are ignored
+-- once a link is pointed elsewhere.
+CREATE TABLE IF NOT EXISTS link_checks (
+    short_url VARCHAR(10) PRIMARY KEY REFERENCES urls (short_url) ON DELETE CASCADE,
+    checked_url TEXT,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    failures INTEGER NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP,
+    next_check_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_checks_next_check_at ON link_checks (next_check_at);
+CREATE INDEX IF NOT EXISTS idx_link_checks_failing ON link_checks (failures) WHERE failures > 0;
@@ -0,0 +1,13 @@
+-- link_metadata holds what was read from the page a link points to.
+-- fetched_url is the destination it was read from, so metadata is ignored
+-- once a link is pointed elsewhere.
+CREATE TABLE IF NOT EXISTS link_metadata (
+    short_url VARCHAR(10) PRIMARY KEY REFERENCES urls (short_url) ON DELETE CASCADE,
+    fetched_url TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    favicon_url TEXT NOT NULL DEFAULT '',
+    open_graph JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
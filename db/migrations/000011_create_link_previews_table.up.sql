@@ -0,0 +1,9 @@
+-- link_previews holds the card shown for a link when it is shared on a chat
+-- app or social network. Empty fields are filled from link_metadata.
+CREATE TABLE IF NOT EXISTS link_previews (
+    short_url VARCHAR(10) PRIMARY KEY REFERENCES urls (short_url) ON DELETE CASCADE,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- name: DeleteOwnerOutboxEvents :execrows
+DELETE FROM outbox
+WHERE owner = @owner::text;
+
+-- name: DeleteOwnerUrls :many
+DELETE FROM urls
+WHERE owner = @owner::text
+RETURNING short_url;
+
+-- name: DeleteOwnerWebhooks :execrows
+DELETE FROM webhooks
+WHERE owner = @owner::text;
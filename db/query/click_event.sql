@@ -0,0 +1,21 @@
+-- name: InsertClickEvent :exec
+INSERT INTO click_events (short_url, bot, referrer, browser, os, device)
+VALUES (@short_url, @bot, @referrer, @browser, @os, @device);
+
+-- name: LockClickRollup :one
+SELECT rolled_up_to, LOCALTIMESTAMP::timestamp AS now
+FROM click_rollup_state
+FOR UPDATE;
+
+-- name: UpdateClickRollup :exec
+UPDATE click_rollup_state
+SET rolled_up_to = @rolled_up_to;
+
+-- name: DeleteClickEvents :execrows
+DELETE FROM click_events
+WHERE id IN (
+    SELECT id FROM click_events
+    WHERE clicked_at < LEAST(@before::timestamp, (SELECT rolled_up_to FROM click_rollup_state))
+    ORDER BY clicked_at
+    LIMIT @batch_size::int
+);
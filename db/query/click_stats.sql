@@ -0,0 +1,57 @@
+-- name: RollUpClickEvents :exec
+WITH counts AS (
+    SELECT e.short_url, date_trunc('hour', e.clicked_at) AS hour, d.dimension, d.value, count(*) AS clicks
+    FROM click_events e
+    CROSS JOIN LATERAL (VALUES
+        ('class', CASE WHEN e.bot THEN 'bot' ELSE 'human' END),
+        ('referrer', CASE WHEN NOT e.bot THEN e.referrer END),
+        ('browser', CASE WHEN NOT e.bot THEN e.browser END),
+        ('os', CASE WHEN NOT e.bot THEN e.os END),
+        ('device', CASE WHEN NOT e.bot THEN e.device END)
+    ) AS d (dimension, value)
+    WHERE e.clicked_at > @rolled_up_to::timestamp AND e.clicked_at <= @up_to::timestamp AND d.value IS NOT NULL
+    GROUP BY e.short_url, date_trunc('hour', e.clicked_at), d.dimension, d.value
+), hourly AS (
+    INSERT INTO click_stats_hourly (short_url, hour, dimension, value, count)
+    SELECT short_url, hour, dimension, value, clicks FROM counts
+    ON CONFLICT (short_url, hour, dimension, value) DO UPDATE
+    SET count = click_stats_hourly.count + EXCLUDED.count
+), daily AS (
+    INSERT INTO click_stats_daily (short_url, day, dimension, value, count)
+    SELECT short_url, hour::date, dimension, value, sum(clicks)::bigint FROM counts
+    GROUP BY short_url, hour::date, dimension, value
+    ON CONFLICT (short_url, day, dimension, value) DO UPDATE
+    SET count = click_stats_daily.count + EXCLUDED.count
+)
+INSERT INTO click_stats (short_url, dimension, value, count)
+SELECT short_url, dimension, value, sum(clicks)::bigint FROM counts
+GROUP BY short_url, dimension, value
+ON CONFLICT (short_url, dimension, value) DO UPDATE
+SET count = click_stats.count + EXCLUDED.count;
+
+-- name: ListClickStats :many
+SELECT dimension, value, count
+FROM (
+    SELECT dimension, value, count,
+           row_number() OVER (PARTITION BY dimension ORDER BY count DESC, value) AS rank
+    FROM click_stats
+    WHERE short_url = @short_url
+) ranked
+WHERE rank <= @max_values::int OR dimension = 'class'
+ORDER BY dimension, count DESC, value;
+
+-- name: ListHourlyClicks :many
+SELECT hour, count
+FROM click_stats_hourly
+WHERE short_url = @short_url AND dimension = 'class' AND value = 'human' AND hour >= @since
+ORDER BY hour;
+
+-- name: ListDailyClicks :many
+SELECT day, count
+FROM click_stats_daily
+WHERE short_url = @short_url AND dimension = 'class' AND value = 'human' AND day >= @since
+ORDER BY day;
+
+-- name: DeleteHourlyClickStats :execrows
+DELETE FROM click_stats_hourly
+WHERE hour < @before;
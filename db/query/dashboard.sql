@@ -0,0 +1,38 @@
+-- name: GetOwnerLinkTotals :one
+SELECT count(*) AS links,
+       COALESCE(sum(click_count), 0)::bigint AS clicks,
+       count(*) FILTER (WHERE click_count = 0) AS unclicked_links
+FROM urls
+WHERE owner = @owner::text;
+
+-- name: ListOwnerNewestLinks :many
+SELECT short_url, original_url, click_count, created_at
+FROM urls
+WHERE owner = @owner::text
+ORDER BY created_at DESC, id DESC
+LIMIT @max_results::int;
+
+-- name: ListOwnerTopLinksByDay :many
+SELECT u.short_url, u.original_url, u.click_count, u.created_at, sum(s.count)::bigint AS window_clicks
+FROM click_stats_daily s
+JOIN urls u ON u.short_url = s.short_url
+WHERE u.owner = @owner::text AND s.dimension = 'class' AND s.value = 'human' AND s.day >= @since::date
+GROUP BY u.id
+ORDER BY window_clicks DESC, u.short_url
+LIMIT @max_results::int;
+
+-- name: ListOwnerTopLinksByHour :many
+SELECT u.short_url, u.original_url, u.click_count, u.created_at, sum(s.count)::bigint AS window_clicks
+FROM click_stats_hourly s
+JOIN urls u ON u.short_url = s.short_url
+WHERE u.owner = @owner::text AND s.dimension = 'class' AND s.value = 'human' AND s.hour >= @since::timestamp
+GROUP BY u.id
+ORDER BY window_clicks DESC, u.short_url
+LIMIT @max_results::int;
+
+-- name: ListOwnerUnclickedLinks :many
+SELECT short_url, original_url, click_count, created_at
+FROM urls
+WHERE owner = @owner::text AND click_count = 0
+ORDER BY created_at, id
+LIMIT @max_results::int;
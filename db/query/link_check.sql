@@ -0,0 +1,57 @@
+-- name: ClaimLinkChecks :many
+WITH due AS (
+    SELECT u.short_url, u.original_url
+    FROM urls u
+    LEFT JOIN link_checks c ON c.short_url = u.short_url
+    WHERE c.next_check_at IS NULL OR c.next_check_at <= LOCALTIMESTAMP
+    ORDER BY c.next_check_at NULLS FIRST, u.id
+    LIMIT @batch_size::int
+    FOR UPDATE OF u SKIP LOCKED
+), claimed AS (
+    INSERT INTO link_checks (short_url, next_check_at)
+    SELECT short_url, LOCALTIMESTAMP + make_interval(secs => @lease_seconds::float8)
+    FROM due
+    ON CONFLICT (short_url) DO UPDATE SET next_check_at = EXCLUDED.next_check_at
+    RETURNING short_url
+)
+SELECT due.short_url, due.original_url
+FROM claimed
+JOIN due ON due.short_url = claimed.short_url;
+
+-- name: GetLinkCheck :one
+SELECT c.short_url, u.original_url, c.status_code, c.error, c.failures, c.checked_at
+FROM link_checks c
+JOIN urls u ON u.short_url = c.short_url
+WHERE c.short_url = @short_url
+  AND c.checked_url = u.original_url;
+
+-- name: ListBrokenLinks :many
+SELECT c.short_url, u.original_url, c.status_code, c.error, c.failures, c.checked_at
+FROM link_checks c
+JOIN urls u ON u.short_url = c.short_url
+WHERE u.owner = @owner::text
+  AND c.failures >= @min_failures::int
+  AND c.checked_url = u.original_url
+ORDER BY c.failures DESC, c.short_url
+LIMIT @max_results::int;
+
+-- name: ListBrokenShortUrls :many
+SELECT c.short_url
+FROM link_checks c
+JOIN urls u ON u.short_url = c.short_url
+WHERE c.failures >= @min_failures::int
+  AND c.checked_url = u.original_url;
+
+-- name: RecordLinkCheck :exec
+UPDATE link_checks
+SET checked_url = @checked_url::text,
+    status_code = @status_code,
+    error = @error,
+    failures = CASE
+        WHEN @healthy::boolean THEN 0
+        WHEN checked_url IS DISTINCT FROM @checked_url::text THEN 1
+        ELSE failures + 1
+    END,
+    checked_at = LOCALTIMESTAMP,
+    next_check_at = LOCALTIMESTAMP + make_interval(secs => @recheck_seconds::float8)
+WHERE short_url = @short_url;
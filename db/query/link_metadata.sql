@@ -0,0 +1,18 @@
+-- name: GetLinkMetadata :one
+SELECT m.short_url, m.fetched_url, m.title, m.description, m.favicon_url, m.open_graph, m.error, m.fetched_at
+FROM link_metadata m
+JOIN urls u ON u.short_url = m.short_url
+WHERE m.short_url = @short_url
+  AND m.fetched_url = u.original_url;
+
+-- name: UpsertLinkMetadata :exec
+INSERT INTO link_metadata (short_url, fetched_url, title, description, favicon_url, open_graph, error, fetched_at)
+VALUES (@short_url, @fetched_url, @title, @description, @favicon_url, @open_graph, @error, LOCALTIMESTAMP)
+ON CONFLICT (short_url) DO UPDATE
+SET fetched_url = EXCLUDED.fetched_url,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    favicon_url = EXCLUDED.favicon_url,
+    open_graph = EXCLUDED.open_graph,
+    error = EXCLUDED.error,
+    fetched_at = EXCLUDED.fetched_at;
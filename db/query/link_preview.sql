@@ -0,0 +1,18 @@
+-- name: DeleteLinkPreview :execrows
+DELETE FROM link_previews
+WHERE short_url = $1;
+
+-- name: GetLinkPreview :one
+SELECT short_url, title, description, image_url, updated_at
+FROM link_previews
+WHERE short_url = $1;
+
+-- name: UpsertLinkPreview :one
+INSERT INTO link_previews (short_url, title, description, image_url, updated_at)
+VALUES (@short_url, @title, @description, @image_url, LOCALTIMESTAMP)
+ON CONFLICT (short_url) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    updated_at = EXCLUDED.updated_at
+RETURNING short_url, title, description, image_url, updated_at;
@@ -0,0 +1,21 @@
+-- name: ListLinkVariants :many
+SELECT id, short_url, destination, weight, click_count, created_at
+FROM link_variants
+WHERE short_url = $1
+ORDER BY id;
+
+-- name: UpsertLinkVariant :one
+INSERT INTO link_variants (short_url, destination, weight)
+VALUES ($1, $2, $3)
+ON CONFLICT (short_url, destination) DO UPDATE
+SET weight = EXCLUDED.weight
+RETURNING id, short_url, destination, weight, click_count, created_at;
+
+-- name: DeleteLinkVariantsExcept :execrows
+DELETE FROM link_variants
+WHERE short_url = @short_url AND NOT (destination = ANY(@destinations::text[]));
+
+-- name: IncrementLinkVariantClickCount :exec
+UPDATE link_variants
+SET click_count = click_count + 1
+WHERE id = $1;
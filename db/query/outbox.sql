@@ -0,0 +1,35 @@
+-- name: InsertOutboxEvent :one
+INSERT INTO outbox (event_type, short_url, payload, owner)
+VALUES ($1, $2, $3, (SELECT owner FROM urls WHERE urls.short_url = $2))
+RETURNING id, owner, occurred_at;
+
+-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+WHERE id IN (
+    SELECT id
+    FROM outbox
+    WHERE delivered_at IS NULL
+      AND next_attempt_at <= NOW()
+      AND attempts < sqlc.arg(max_attempts)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_type, short_url, owner, payload, occurred_at, attempts;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox
+SET delivered_at = NOW(), last_error = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET last_error = sqlc.arg(last_error),
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(retry_seconds)::float8)
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM outbox
+WHERE delivered_at < $1;
@@ -0,0 +1,14 @@
+-- name: CreateRoutingRule :one
+INSERT INTO routing_rules (short_url, priority, devices, operating_systems, languages, countries, destination)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, short_url, priority, devices, operating_systems, languages, countries, destination, created_at;
+
+-- name: ListRoutingRules :many
+SELECT id, short_url, priority, devices, operating_systems, languages, countries, destination, created_at
+FROM routing_rules
+WHERE short_url = $1
+ORDER BY priority, id;
+
+-- name: DeleteRoutingRule :execrows
+DELETE FROM routing_rules
+WHERE id = $1 AND short_url = $2;
@@ -1,10 +1,10 @@
 -- name: CreateUrl :one
-INSERT INTO urls (original_url, short_url)
-VALUES ($1, $2)
+INSERT INTO urls (original_url, short_url, owner)
+VALUES ($1, $2, $3)
 RETURNING id, original_url, short_url, created_at, updated_at;
 
 -- name: GetUrlByShort :one
-SELECT id, original_url, short_url, click_count, created_at, updated_at
+SELECT id, original_url, short_url, click_count, created_at, updated_at, owner
 FROM urls
 WHERE short_url = $1;
 
@@ -12,4 +12,42 @@ WHERE short_url = $1;
 UPDATE urls
 SET click_count = click_count + 1, updated_at = NOW()
 WHERE short_url = $1
-RETURNING id, original_url, short_url, click_count, created_at, updated_at;
\ No newline at end of file
+RETURNING id, original_url, short_url, click_count, created_at, updated_at;
+
+-- name: DeleteUrl :execrows
+DELETE FROM urls
+WHERE short_url = $1;
+
+-- name: ListUrls :many
+SELECT id, original_url, short_url, click_count, created_at, updated_at
+FROM urls
+ORDER BY id
+LIMIT $1 OFFSET $2;
+
+-- name: ListUrlsAfter :many
+SELECT id, original_url, short_url, owner, click_count, created_at, updated_at
+FROM urls
+WHERE short_url > $1
+ORDER BY short_url
+LIMIT $2;
+
+-- name: ListTopUrls :many
+SELECT id, original_url, short_url, click_count, created_at, updated_at
+FROM urls
+ORDER BY click_count DESC, short_url
+LIMIT $1;
+
+-- name: InsertUrl :execrows
+INSERT INTO urls (original_url, short_url, click_count, created_at, updated_at, owner)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (short_url) DO NOTHING;
+
+-- name: UpsertUrl :exec
+INSERT INTO urls (original_url, short_url, click_count, created_at, updated_at, owner)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (short_url) DO UPDATE
+SET original_url = EXCLUDED.original_url,
+    click_count = EXCLUDED.click_count,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    owner = COALESCE(EXCLUDED.owner, urls.owner);
@@ -0,0 +1,72 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (owner, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING id, owner, url, secret, event_types, created_at;
+
+-- name: ListWebhooks :many
+SELECT id, owner, url, secret, event_types, created_at
+FROM webhooks
+WHERE owner = $1
+ORDER BY id;
+
+-- name: GetWebhook :one
+SELECT id, owner, url, secret, event_types, created_at
+FROM webhooks
+WHERE id = $1 AND owner = $2;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND owner = $2;
+
+-- name: ListSubscribedWebhooks :many
+SELECT id, owner, url, secret, event_types, created_at
+FROM webhooks
+WHERE owner = sqlc.arg(owner)
+  AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::text = ANY(event_types))
+ORDER BY id;
+
+-- name: EnqueueWebhookDelivery :execrows
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (webhook_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries AS d
+SET attempts = d.attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8),
+    updated_at = NOW()
+FROM webhooks AS w
+WHERE w.id = d.webhook_id
+  AND d.id IN (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending'
+      AND next_attempt_at <= NOW()
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret;
+
+-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(retry_seconds)::float8),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+       response_status, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY id DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND webhook_id = $2 AND status = 'dead';
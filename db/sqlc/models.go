@@ -8,6 +8,108 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ClickEvent struct {
+	ID        int64
+	ShortUrl  string
+	ClickedAt pgtype.Timestamp
+	Bot       bool
+	Referrer  string
+	Browser   string
+	Os        string
+	Device    string
+}
+
+type ClickRollupState struct {
+	ID         bool
+	RolledUpTo pgtype.Timestamp
+}
+
+type ClickStat struct {
+	ShortUrl  string
+	Dimension string
+	Value     string
+	Count     int64
+}
+
+type ClickStatsDaily struct {
+	ShortUrl  string
+	Day       pgtype.Date
+	Dimension string
+	Value     string
+	Count     int64
+}
+
+type ClickStatsHourly struct {
+	ShortUrl  string
+	Hour      pgtype.Timestamp
+	Dimension string
+	Value     string
+	Count     int64
+}
+
+type LinkCheck struct {
+	ShortUrl    string
+	CheckedUrl  pgtype.Text
+	StatusCode  int32
+	Error       string
+	Failures    int32
+	CheckedAt   pgtype.Timestamp
+	NextCheckAt pgtype.Timestamp
+}
+
+type LinkMetadatum struct {
+	ShortUrl    string
+	FetchedUrl  string
+	Title       string
+	Description string
+	FaviconUrl  string
+	OpenGraph   []byte
+	Error       string
+	FetchedAt   pgtype.Timestamp
+}
+
+type LinkPreview struct {
+	ShortUrl    string
+	Title       string
+	Description string
+	ImageUrl    string
+	UpdatedAt   pgtype.Timestamp
+}
+
+type LinkVariant struct {
+	ID          int64
+	ShortUrl    string
+	Destination string
+	Weight      int32
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+}
+
+type Outbox struct {
+	ID            int64
+	EventType     string
+	ShortUrl      string
+	Payload       []byte
+	OccurredAt    pgtype.Timestamp
+	Attempts      int32
+	LastError     pgtype.Text
+	NextAttemptAt pgtype.Timestamp
+	DeliveredAt   pgtype.Timestamp
+	Owner         pgtype.Text
+}
+
+type RoutingRule struct {
+	ID               int64
+	ShortUrl         string
+	Priority         int32
+	Devices          []string
+	OperatingSystems []string
+	Languages        []string
+	Countries        []string
+	Destination      string
+	CreatedAt        pgtype.Timestamp
+}
+
 type Url struct {
 	ID          int32
 	OriginalUrl string
@@ -15,4 +117,29 @@ type Url struct {
 	CreatedAt   pgtype.Timestamp
 	UpdatedAt   pgtype.Timestamp
 	ClickCount  int64
+	Owner       pgtype.Text
+}
+
+type Webhook struct {
+	ID         int64
+	Owner      string
+	Url        string
+	Secret     string
+	EventTypes []string
+	CreatedAt  pgtype.Timestamp
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	EventID        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamp
+	ResponseStatus pgtype.Int4
+	LastError      pgtype.Text
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
 }
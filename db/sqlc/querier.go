@@ -6,12 +6,69 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	ClaimLinkChecks(ctx context.Context, arg ClaimLinkChecksParams) ([]ClaimLinkChecksRow, error)
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CreateRoutingRule(ctx context.Context, arg CreateRoutingRuleParams) (RoutingRule, error)
 	CreateUrl(ctx context.Context, arg CreateUrlParams) (CreateUrlRow, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	DeleteClickEvents(ctx context.Context, arg DeleteClickEventsParams) (int64, error)
+	DeleteDeliveredOutboxEvents(ctx context.Context, deliveredAt pgtype.Timestamp) (int64, error)
+	DeleteHourlyClickStats(ctx context.Context, before pgtype.Timestamp) (int64, error)
+	DeleteLinkPreview(ctx context.Context, shortUrl string) (int64, error)
+	DeleteLinkVariantsExcept(ctx context.Context, arg DeleteLinkVariantsExceptParams) (int64, error)
+	DeleteOwnerOutboxEvents(ctx context.Context, owner string) (int64, error)
+	DeleteOwnerUrls(ctx context.Context, owner string) ([]string, error)
+	DeleteOwnerWebhooks(ctx context.Context, owner string) (int64, error)
+	DeleteRoutingRule(ctx context.Context, arg DeleteRoutingRuleParams) (int64, error)
+	DeleteUrl(ctx context.Context, shortUrl string) (int64, error)
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	EnqueueWebhookDelivery(ctx context.Context, arg EnqueueWebhookDeliveryParams) (int64, error)
+	GetLinkCheck(ctx context.Context, shortUrl string) (GetLinkCheckRow, error)
+	GetLinkMetadata(ctx context.Context, shortUrl string) (GetLinkMetadataRow, error)
+	GetLinkPreview(ctx context.Context, shortUrl string) (LinkPreview, error)
+	GetOwnerLinkTotals(ctx context.Context, owner string) (GetOwnerLinkTotalsRow, error)
 	GetUrlByShort(ctx context.Context, shortUrl string) (GetUrlByShortRow, error)
+	GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error)
 	IncrementClickCount(ctx context.Context, shortUrl string) (IncrementClickCountRow, error)
+	IncrementLinkVariantClickCount(ctx context.Context, id int64) error
+	InsertClickEvent(ctx context.Context, arg InsertClickEventParams) error
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) (InsertOutboxEventRow, error)
+	InsertUrl(ctx context.Context, arg InsertUrlParams) (int64, error)
+	ListBrokenLinks(ctx context.Context, arg ListBrokenLinksParams) ([]ListBrokenLinksRow, error)
+	ListBrokenShortUrls(ctx context.Context, minFailures int32) ([]string, error)
+	ListClickStats(ctx context.Context, arg ListClickStatsParams) ([]ListClickStatsRow, error)
+	ListDailyClicks(ctx context.Context, arg ListDailyClicksParams) ([]ListDailyClicksRow, error)
+	ListHourlyClicks(ctx context.Context, arg ListHourlyClicksParams) ([]ListHourlyClicksRow, error)
+	ListLinkVariants(ctx context.Context, shortUrl string) ([]LinkVariant, error)
+	ListOwnerNewestLinks(ctx context.Context, arg ListOwnerNewestLinksParams) ([]ListOwnerNewestLinksRow, error)
+	ListOwnerTopLinksByDay(ctx context.Context, arg ListOwnerTopLinksByDayParams) ([]ListOwnerTopLinksByDayRow, error)
+	ListOwnerTopLinksByHour(ctx context.Context, arg ListOwnerTopLinksByHourParams) ([]ListOwnerTopLinksByHourRow, error)
+	ListOwnerUnclickedLinks(ctx context.Context, arg ListOwnerUnclickedLinksParams) ([]ListOwnerUnclickedLinksRow, error)
+	ListRoutingRules(ctx context.Context, shortUrl string) ([]RoutingRule, error)
+	ListSubscribedWebhooks(ctx context.Context, arg ListSubscribedWebhooksParams) ([]Webhook, error)
+	ListTopUrls(ctx context.Context, limit int32) ([]ListTopUrlsRow, error)
+	ListUrls(ctx context.Context, arg ListUrlsParams) ([]ListUrlsRow, error)
+	ListUrlsAfter(ctx context.Context, arg ListUrlsAfterParams) ([]ListUrlsAfterRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, owner string) ([]Webhook, error)
+	LockClickRollup(ctx context.Context) (LockClickRollupRow, error)
+	MarkOutboxEventDelivered(ctx context.Context, id int64) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	RecordLinkCheck(ctx context.Context, arg RecordLinkCheckParams) error
+	RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error
+	RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error)
+	RollUpClickEvents(ctx context.Context, arg RollUpClickEventsParams) error
+	UpdateClickRollup(ctx context.Context, rolledUpTo pgtype.Timestamp) error
+	UpsertLinkMetadata(ctx context.Context, arg UpsertLinkMetadataParams) error
+	UpsertLinkPreview(ctx context.Context, arg UpsertLinkPreviewParams) (LinkPreview, error)
+	UpsertLinkVariant(ctx context.Context, arg UpsertLinkVariantParams) (LinkVariant, error)
+	UpsertUrl(ctx context.Context, arg UpsertUrlParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,14 +12,15 @@ import (
 )
 
 const createUrl = `-- name: CreateUrl :one
-INSERT INTO urls (original_url, short_url)
-VALUES ($1, $2)
+INSERT INTO urls (original_url, short_url, owner)
+VALUES ($1, $2, $3)
 RETURNING id, original_url, short_url, created_at, updated_at
 `
 
 type CreateUrlParams struct {
 	OriginalUrl string
 	ShortUrl    string
+	Owner       pgtype.Text
 }
 
 type CreateUrlRow struct {
@@ -31,7 +32,7 @@ type CreateUrlRow struct {
 }
 
 func (q *Queries) CreateUrl(ctx context.Context, arg CreateUrlParams) (CreateUrlRow, error) {
-	row := q.db.QueryRow(ctx, createUrl, arg.OriginalUrl, arg.ShortUrl)
+	row := q.db.QueryRow(ctx, createUrl, arg.OriginalUrl, arg.ShortUrl, arg.Owner)
 	var i CreateUrlRow
 	err := row.Scan(
 		&i.ID,
@@ -43,8 +44,21 @@ func (q *Queries) CreateUrl(ctx context.Context, arg CreateUrlParams) (CreateUrl
 	return i, err
 }
 
+const deleteUrl = `-- name: DeleteUrl :execrows
+DELETE FROM urls
+WHERE short_url = $1
+`
+
+func (q *Queries) DeleteUrl(ctx context.Context, shortUrl string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUrl, shortUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getUrlByShort = `-- name: GetUrlByShort :one
-SELECT id, original_url, short_url, click_count, created_at, updated_at
+SELECT id, original_url, short_url, click_count, created_at, updated_at, owner
 FROM urls
 WHERE short_url = $1
 `
@@ -56,6 +70,7 @@ type GetUrlByShortRow struct {
 	ClickCount  int64
 	CreatedAt   pgtype.Timestamp
 	UpdatedAt   pgtype.Timestamp
+	Owner       pgtype.Text
 }
 
 func (q *Queries) GetUrlByShort(ctx context.Context, shortUrl string) (GetUrlByShortRow, error) {
@@ -68,6 +83,7 @@ func (q *Queries) GetUrlByShort(ctx context.Context, shortUrl string) (GetUrlByS
 		&i.ClickCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Owner,
 	)
 	return i, err
 }
@@ -101,3 +117,207 @@ func (q *Queries) IncrementClickCount(ctx context.Context, shortUrl string) (Inc
 	)
 	return i, err
 }
+
+const insertUrl = `-- name: InsertUrl :execrows
+INSERT INTO urls (original_url, short_url, click_count, created_at, updated_at, owner)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (short_url) DO NOTHING
+`
+
+type InsertUrlParams struct {
+	OriginalUrl string
+	ShortUrl    string
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+	Owner       pgtype.Text
+}
+
+func (q *Queries) InsertUrl(ctx context.Context, arg InsertUrlParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertUrl,
+		arg.OriginalUrl,
+		arg.ShortUrl,
+		arg.ClickCount,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Owner,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listTopUrls = `-- name: ListTopUrls :many
+SELECT id, original_url, short_url, click_count, created_at, updated_at
+FROM urls
+ORDER BY click_count DESC, short_url
+LIMIT $1
+`
+
+type ListTopUrlsRow struct {
+	ID          int32
+	OriginalUrl string
+	ShortUrl    string
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+func (q *Queries) ListTopUrls(ctx context.Context, limit int32) ([]ListTopUrlsRow, error) {
+	rows, err := q.db.Query(ctx, listTopUrls, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopUrlsRow
+	for rows.Next() {
+		var i ListTopUrlsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortUrl,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUrls = `-- name: ListUrls :many
+SELECT id, original_url, short_url, click_count, created_at, updated_at
+FROM urls
+ORDER BY id
+LIMIT $1 OFFSET $2
+`
+
+type ListUrlsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type ListUrlsRow struct {
+	ID          int32
+	OriginalUrl string
+	ShortUrl    string
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+func (q *Queries) ListUrls(ctx context.Context, arg ListUrlsParams) ([]ListUrlsRow, error) {
+	rows, err := q.db.Query(ctx, listUrls, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUrlsRow
+	for rows.Next() {
+		var i ListUrlsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortUrl,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUrlsAfter = `-- name: ListUrlsAfter :many
+SELECT id, original_url, short_url, owner, click_count, created_at, updated_at
+FROM urls
+WHERE short_url > $1
+ORDER BY short_url
+LIMIT $2
+`
+
+type ListUrlsAfterParams struct {
+	ShortUrl string
+	Limit    int32
+}
+
+type ListUrlsAfterRow struct {
+	ID          int32
+	OriginalUrl string
+	ShortUrl    string
+	Owner       pgtype.Text
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+func (q *Queries) ListUrlsAfter(ctx context.Context, arg ListUrlsAfterParams) ([]ListUrlsAfterRow, error) {
+	rows, err := q.db.Query(ctx, listUrlsAfter, arg.ShortUrl, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUrlsAfterRow
+	for rows.Next() {
+		var i ListUrlsAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortUrl,
+			&i.Owner,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertUrl = `-- name: UpsertUrl :exec
+INSERT INTO urls (original_url, short_url, click_count, created_at, updated_at, owner)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (short_url) DO UPDATE
+SET original_url = EXCLUDED.original_url,
+    click_count = EXCLUDED.click_count,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    owner = COALESCE(EXCLUDED.owner, urls.owner)
+`
+
+type UpsertUrlParams struct {
+	OriginalUrl string
+	ShortUrl    string
+	ClickCount  int64
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+	Owner       pgtype.Text
+}
+
+func (q *Queries) UpsertUrl(ctx context.Context, arg UpsertUrlParams) error {
+	_, err := q.db.Exec(ctx, upsertUrl,
+		arg.OriginalUrl,
+		arg.ShortUrl,
+		arg.ClickCount,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Owner,
+	)
+	return err
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+}
+
+func NewAccountHandler(s service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		service: s,
+	}
+}
+
+func (h *AccountHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/account/data", h.DeleteDataHandler).Methods("DELETE")
+}
+
+// DeleteDataHandler deletes all links and webhooks of the caller's API key.
+func (h *AccountHandler) DeleteDataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "AccountHandler.DeleteDataHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.service.DeleteOwnerData(ctx, owner)
+	if err != nil {
+		middleware.GetLoggerFromContext(ctx).Error("Failed to delete owner data", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, model.OwnerDeletionResponse{
+		DeletedLinks:    len(deletion.ShortUrls),
+		DeletedWebhooks: deletion.Webhooks,
+	})
+}
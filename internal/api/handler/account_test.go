@@ -0,0 +1,78 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) DeleteOwnerData(ctx context.Context, owner string) (*domain.OwnerDeletion, error) {
+	args := m.Called(ctx, owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OwnerDeletion), args.Error(1)
+}
+
+func newAccountRouter(s service.AccountService) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey}))
+	handler.NewAccountHandler(s).RegisterRoutes(router)
+	return router
+}
+
+func TestDeleteDataHandler(t *testing.T) {
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		mockService.On("DeleteOwnerData", mock.Anything, owner).
+			Return(&domain.OwnerDeletion{ShortUrls: []string{"ab", "cd"}, Webhooks: 1, Events: 4}, nil)
+
+		rr := httptest.NewRecorder()
+		newAccountRouter(mockService).ServeHTTP(rr, newWebhookRequest("DELETE", "/api/account/data", ""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.OwnerDeletionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, model.OwnerDeletionResponse{DeletedLinks: 2, DeletedWebhooks: 1}, response)
+	})
+
+	t.Run("without api key", func(t *testing.T) {
+		mockService := new(MockAccountService)
+
+		rr := httptest.NewRecorder()
+		newAccountRouter(mockService).ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/account/data", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockService.AssertNotCalled(t, "DeleteOwnerData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		mockService.On("DeleteOwnerData", mock.Anything, owner).Return(nil, errors.New("connection refused"))
+
+		rr := httptest.NewRecorder()
+		newAccountRouter(mockService).ServeHTTP(rr, newWebhookRequest("DELETE", "/api/account/data", ""))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
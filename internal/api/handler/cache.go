@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+const DefaultWarmCount = 100
+
+type CacheHandler struct {
+	service service.CacheService
+}
+
+func NewCacheHandler(s service.CacheService) *CacheHandler {
+	return &CacheHandler{
+		service: s,
+	}
+}
+
+func (h *CacheHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/cache/warm", h.WarmHandler).Methods("POST")
+	router.HandleFunc("/api/admin/cache/{shortened}", h.PurgeHandler).Methods("DELETE")
+}
+
+func (h *CacheHandler) PurgeHandler(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.GetLoggerFromContext(r.Context())
+
+	shortened := mux.Vars(r)["shortened"]
+	if err := h.service.PurgeShortURL(r.Context(), shortened); err != nil {
+		logger.Error("Failed to purge short URL from cache", "shortened", shortened, "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidShortCode) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CacheHandler) WarmHandler(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.GetLoggerFromContext(r.Context())
+
+	count := DefaultWarmCount
+	if value := r.URL.Query().Get("count"); value != "" {
+		var err error
+		if count, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "count must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.service.WarmTopURLs(r.Context(), count)
+	if err != nil {
+		logger.Error("Failed to warm cache", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidWarmCount) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(model.CacheWarmResponse{
+		Loaded: report.Loaded,
+		Warmed: report.Warmed,
+	}); err != nil {
+		logger.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type MockCacheService struct {
+	mock.Mock
+}
+
+func (m *MockCacheService) PurgeShortURL(ctx context.Context, shortURL string) error {
+	args := m.Called(ctx, shortURL)
+	return args.Error(0)
+}
+
+func (m *MockCacheService) WarmTopURLs(ctx context.Context, count int) (*domain.CacheWarmReport, error) {
+	args := m.Called(ctx, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CacheWarmReport), args.Error(1)
+}
+
+func newCacheRouter(s service.CacheService) *mux.Router {
+	router := mux.NewRouter()
+	handler.NewCacheHandler(s).RegisterRoutes(router)
+	return router
+}
+
+func TestPurgeHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockCacheService)
+		mockService.On("PurgeShortURL", mock.Anything, "abc123").Return(nil)
+
+		rr := httptest.NewRecorder()
+		newCacheRouter(mockService).ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/admin/cache/abc123", nil))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("cache error", func(t *testing.T) {
+		mockService := new(MockCacheService)
+		mockService.On("PurgeShortURL", mock.Anything, "abc123").Return(errors.New("redis down"))
+
+		rr := httptest.NewRecorder()
+		newCacheRouter(mockService).ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/admin/cache/abc123", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestWarmHandler(t *testing.T) {
+	t.Run("default count", func(t *testing.T) {
+		mockService := new(MockCacheService)
+		mockService.On("WarmTopURLs", mock.Anything, handler.DefaultWarmCount).
+			Return(&domain.CacheWarmReport{Loaded: 80, Warmed: 30}, nil)
+
+		rr := httptest.NewRecorder()
+		newCacheRouter(mockService).ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/cache/warm", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.CacheWarmResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, model.CacheWarmResponse{Loaded: 80, Warmed: 30}, response)
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		mockService := new(MockCacheService)
+		mockService.On("WarmTopURLs", mock.Anything, 0).Return(nil, service.ErrInvalidWarmCount)
+
+		rr := httptest.NewRecorder()
+		newCacheRouter(mockService).ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/cache/warm?count=0", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("non numeric count", func(t *testing.T) {
+		mockService := new(MockCacheService)
+
+		rr := httptest.NewRecorder()
+		newCacheRouter(mockService).ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/cache/warm?count=many", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "WarmTopURLs", mock.Anything, mock.Anything)
+	})
+}
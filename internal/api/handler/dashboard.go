@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+const DashboardRoute = "/api/stats"
+
+type DashboardHandler struct {
+	service service.DashboardService
+}
+
+func NewDashboardHandler(s service.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		service: s,
+	}
+}
+
+func (h *DashboardHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc(DashboardRoute, h.DashboardHandler).Methods("GET")
+}
+
+func (h *DashboardHandler) DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "DashboardHandler.DashboardHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dashboard, err := h.service.GetDashboard(ctx, owner, query.Get("window"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidDashboardWindow),
+			errors.Is(err, service.ErrInvalidDashboardLimit):
+			status = http.StatusBadRequest
+		default:
+			middleware.GetLoggerFromContext(ctx).Error("Failed to get dashboard", "error", err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	response := model.DashboardResponse{
+		Window:             dashboard.Window,
+		TotalLinks:         dashboard.TotalLinks,
+		TotalClicks:        dashboard.TotalClicks,
+		ZeroClickLinkCount: dashboard.ZeroClickLinkCount,
+		TopLinks:           make([]model.TopLinkResponse, 0, len(dashboard.TopLinks)),
+		NewestLinks:        toLinkSummaryResponses(dashboard.NewestLinks),
+		ZeroClickLinks:     toLinkSummaryResponses(dashboard.ZeroClickLinks),
+	}
+	for _, link := range dashboard.TopLinks {
+		response.TopLinks = append(response.TopLinks, model.TopLinkResponse{
+			ShortURL:     link.ShortUrl,
+			OriginalURL:  link.OriginalUrl,
+			WindowClicks: link.WindowClicks,
+			ClickCount:   link.ClickCount,
+			CreatedAt:    link.CreatedAt,
+		})
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func toLinkSummaryResponses(links []*domain.Url) []model.LinkSummaryResponse {
+	response := make([]model.LinkSummaryResponse, 0, len(links))
+	for _, link := range links {
+		response = append(response, model.LinkSummaryResponse{
+			ShortURL:    link.ShortUrl,
+			OriginalURL: link.OriginalUrl,
+			ClickCount:  link.ClickCount,
+			CreatedAt:   link.CreatedAt,
+		})
+	}
+	return response
+}
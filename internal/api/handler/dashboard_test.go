@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type MockDashboardService struct {
+	mock.Mock
+}
+
+func (m *MockDashboardService) GetDashboard(ctx context.Context, owner, window string, limit int) (*domain.Dashboard, error) {
+	args := m.Called(ctx, owner, window, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Dashboard), args.Error(1)
+}
+
+func newDashboardRouter(s service.DashboardService) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey}))
+	handler.NewDashboardHandler(s).RegisterRoutes(router)
+	return router
+}
+
+func TestDashboardHandler(t *testing.T) {
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockDashboardService)
+		mockService.On("GetDashboard", mock.Anything, owner, "24h", 5).Return(&domain.Dashboard{
+			Window:             "24h",
+			TotalLinks:         2,
+			TotalClicks:        7,
+			ZeroClickLinkCount: 1,
+			TopLinks: []*domain.TopLink{{
+				Url:          domain.Url{ShortUrl: "top", OriginalUrl: "https://example.com/top", ClickCount: 7, CreatedAt: "2025-01-01T00:00:00Z"},
+				WindowClicks: 4,
+			}},
+			NewestLinks: []*domain.Url{
+				{ShortUrl: "new", OriginalUrl: "https://example.com/new", CreatedAt: "2025-01-02T00:00:00Z"},
+				{ShortUrl: "top", OriginalUrl: "https://example.com/top", ClickCount: 7, CreatedAt: "2025-01-01T00:00:00Z"},
+			},
+			ZeroClickLinks: []*domain.Url{
+				{ShortUrl: "new", OriginalUrl: "https://example.com/new", CreatedAt: "2025-01-02T00:00:00Z"},
+			},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		newDashboardRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/stats?window=24h&limit=5", ""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.DashboardResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, model.DashboardResponse{
+			Window:             "24h",
+			TotalLinks:         2,
+			TotalClicks:        7,
+			ZeroClickLinkCount: 1,
+			TopLinks: []model.TopLinkResponse{
+				{ShortURL: "top", OriginalURL: "https://example.com/top", WindowClicks: 4, ClickCount: 7, CreatedAt: "2025-01-01T00:00:00Z"},
+			},
+			NewestLinks: []model.LinkSummaryResponse{
+				{ShortURL: "new", OriginalURL: "https://example.com/new", CreatedAt: "2025-01-02T00:00:00Z"},
+				{ShortURL: "top", OriginalURL: "https://example.com/top", ClickCount: 7, CreatedAt: "2025-01-01T00:00:00Z"},
+			},
+			ZeroClickLinks: []model.LinkSummaryResponse{
+				{ShortURL: "new", OriginalURL: "https://example.com/new", CreatedAt: "2025-01-02T00:00:00Z"},
+			},
+		}, response)
+	})
+
+	t.Run("without api key", func(t *testing.T) {
+		mockService := new(MockDashboardService)
+
+		rr := httptest.NewRecorder()
+		newDashboardRouter(mockService).ServeHTTP(rr, httptest.NewRequest("GET", "/api/stats", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockService.AssertNotCalled(t, "GetDashboard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		mockService := new(MockDashboardService)
+		mockService.On("GetDashboard", mock.Anything, owner, "1y", 0).Return(nil, service.ErrInvalidDashboardWindow)
+
+		rr := httptest.NewRecorder()
+		newDashboardRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/stats?window=1y", ""))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockService := new(MockDashboardService)
+
+		rr := httptest.NewRecorder()
+		newDashboardRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/stats?limit=ten", ""))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "GetDashboard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
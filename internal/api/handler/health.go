@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+)
+
+const (
+	DefaultHealthCheckTimeout = 2 * time.Second
+
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+	healthStatusDegraded    = "degraded"
+	healthStatusError       = "error"
+	healthStatusShutdown    = "shutting_down"
+)
+
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Optional checks are reported but only degrade the service instead of
+	// failing readiness, for dependencies it can serve without.
+	Optional bool
+}
+
+type HealthHandler struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/healthz", h.LivenessHandler).Methods("GET")
+	router.HandleFunc("/readyz", h.ReadinessHandler).Methods("GET")
+}
+
+// SetShuttingDown makes the readiness probe fail so that load balancers stop
+// routing new traffic while in-flight requests are drained.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *HealthHandler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, r, http.StatusOK, model.HealthResponse{Status: healthStatusOK})
+}
+
+func (h *HealthHandler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	response := model.HealthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]model.HealthCheckResponse, len(h.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			result := model.HealthCheckResponse{
+				Status:   healthStatusOK,
+				Duration: time.Since(start).String(),
+			}
+			if err != nil {
+				result.Status = healthStatusError
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			response.Checks[check.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for _, check := range h.checks {
+		if response.Checks[check.Name].Status == healthStatusOK {
+			continue
+		}
+		if !check.Optional {
+			response.Status = healthStatusUnavailable
+			status = http.StatusServiceUnavailable
+		} else if status == http.StatusOK {
+			response.Status = healthStatusDegraded
+		}
+	}
+	if h.shuttingDown.Load() {
+		response.Status = healthStatusShutdown
+		status = http.StatusServiceUnavailable
+	}
+
+	writeHealthResponse(w, r, status, response)
+}
+
+func writeHealthResponse(w http.ResponseWriter, r *http.Request, status int, response model.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to encode response", "error", err)
+	}
+}
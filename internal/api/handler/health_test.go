@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/model"
+)
+
+func okCheck(name string) handler.HealthCheck {
+	return handler.HealthCheck{Name: name, Check: func(ctx context.Context) error { return nil }}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	healthHandler := handler.NewHealthHandler(time.Second, handler.HealthCheck{
+		Name:  "postgres",
+		Check: func(ctx context.Context) error { return errors.New("down") },
+	})
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	rr := httptest.NewRecorder()
+
+	healthHandler.LivenessHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Run("all dependencies healthy", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(time.Second, okCheck("postgres"), okCheck("redis"))
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		rr := httptest.NewRecorder()
+
+		healthHandler.ReadinessHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "ok", response.Status)
+		assert.Equal(t, "ok", response.Checks["postgres"].Status)
+		assert.Equal(t, "ok", response.Checks["redis"].Status)
+	})
+
+	t.Run("dependency failing", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(time.Second, okCheck("postgres"), handler.HealthCheck{
+			Name:  "redis",
+			Check: func(ctx context.Context) error { return errors.New("connection refused") },
+		})
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		rr := httptest.NewRecorder()
+
+		healthHandler.ReadinessHandler(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+		var response model.HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "unavailable", response.Status)
+		assert.Equal(t, "ok", response.Checks["postgres"].Status)
+		assert.Equal(t, "error", response.Checks["redis"].Status)
+		assert.Equal(t, "connection refused", response.Checks["redis"].Error)
+	})
+
+	t.Run("optional dependency failing", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(time.Second, okCheck("postgres"), handler.HealthCheck{
+			Name:     "redis",
+			Check:    func(ctx context.Context) error { return errors.New("connection refused") },
+			Optional: true,
+		})
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		rr := httptest.NewRecorder()
+
+		healthHandler.ReadinessHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "degraded", response.Status)
+		assert.Equal(t, "error", response.Checks["redis"].Status)
+	})
+
+	t.Run("dependency timing out", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(10*time.Millisecond, handler.HealthCheck{
+			Name: "postgres",
+			Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		rr := httptest.NewRecorder()
+
+		healthHandler.ReadinessHandler(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(time.Second, okCheck("postgres"))
+		healthHandler.SetShuttingDown()
+
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		rr := httptest.NewRecorder()
+
+		healthHandler.ReadinessHandler(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+		var response model.HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "shutting_down", response.Status)
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+const BrokenLinksRoute = "/api/links/broken"
+
+type LinkHealthHandler struct {
+	service service.LinkHealthService
+}
+
+func NewLinkHealthHandler(s service.LinkHealthService) *LinkHealthHandler {
+	return &LinkHealthHandler{
+		service: s,
+	}
+}
+
+func (h *LinkHealthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc(BrokenLinksRoute, h.BrokenLinksHandler).Methods("GET")
+}
+
+func (h *LinkHealthHandler) BrokenLinksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "LinkHealthHandler.BrokenLinksHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	links, err := h.service.ListBrokenLinks(ctx, owner, limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidBrokenLinksLimit) {
+			status = http.StatusBadRequest
+		} else {
+			middleware.GetLoggerFromContext(ctx).Error("Failed to list broken links", "error", err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	response := model.BrokenLinksResponse{
+		Links: make([]model.LinkHealthResponse, 0, len(links)),
+	}
+	for _, link := range links {
+		response.Links = append(response.Links, toLinkHealthResponse(link))
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func toLinkHealthResponse(health *domain.LinkHealth) model.LinkHealthResponse {
+	return model.LinkHealthResponse{
+		ShortURL:    health.ShortUrl,
+		OriginalURL: health.OriginalUrl,
+		StatusCode:  health.StatusCode,
+		Error:       health.Error,
+		Failures:    health.Failures,
+		Broken:      health.Broken,
+		CheckedAt:   health.CheckedAt,
+	}
+}
@@ -0,0 +1,146 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type MockLinkHealthService struct {
+	mock.Mock
+}
+
+func (m *MockLinkHealthService) GetLinkHealth(ctx context.Context, shortURL string) (*domain.LinkHealth, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LinkHealth), args.Error(1)
+}
+
+func (m *MockLinkHealthService) ListBrokenLinks(ctx context.Context, owner string, limit int) ([]*domain.LinkHealth, error) {
+	args := m.Called(ctx, owner, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LinkHealth), args.Error(1)
+}
+
+func (m *MockLinkHealthService) IsBroken(shortURL string) bool {
+	return m.Called(shortURL).Bool(0)
+}
+
+func (m *MockLinkHealthService) LoadBrokenLinks(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestBrokenLinksHandler(t *testing.T) {
+	newRouter := func(s service.LinkHealthService) *mux.Router {
+		router := mux.NewRouter()
+		router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey}))
+		handler.NewLinkHealthHandler(s).RegisterRoutes(router)
+		return router
+	}
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockLinkHealthService)
+		mockService.On("ListBrokenLinks", mock.Anything, owner, 20).Return([]*domain.LinkHealth{{
+			ShortUrl:    "gone",
+			OriginalUrl: "https://example.com/gone",
+			StatusCode:  http.StatusNotFound,
+			Error:       "Not Found",
+			Failures:    3,
+			Broken:      true,
+			CheckedAt:   "2026-10-19T12:00:00Z",
+		}}, nil)
+
+		rr := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/links/broken?limit=20", ""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.BrokenLinksResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []model.LinkHealthResponse{{
+			ShortURL:    "gone",
+			OriginalURL: "https://example.com/gone",
+			StatusCode:  http.StatusNotFound,
+			Error:       "Not Found",
+			Failures:    3,
+			Broken:      true,
+			CheckedAt:   "2026-10-19T12:00:00Z",
+		}}, response.Links)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockService := new(MockLinkHealthService)
+		mockService.On("ListBrokenLinks", mock.Anything, owner, 5000).Return(nil, service.ErrInvalidBrokenLinksLimit)
+
+		for _, target := range []string{"/api/links/broken?limit=many", "/api/links/broken?limit=5000"} {
+			rr := httptest.NewRecorder()
+			newRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", target, ""))
+			assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService := new(MockLinkHealthService)
+		mockService.On("ListBrokenLinks", mock.Anything, owner, 0).Return(nil, errors.New("database is down"))
+
+		rr := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/links/broken", ""))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("without api key", func(t *testing.T) {
+		mockService := new(MockLinkHealthService)
+
+		rr := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(rr, httptest.NewRequest("GET", "/api/links/broken", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockService.AssertNotCalled(t, "ListBrokenLinks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestResolveShortURLHandler_Fallback(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: "gone", OriginalUrl: "https://example.com/gone"})
+	require.NoError(t, err)
+	health := new(MockLinkHealthService)
+	health.On("IsBroken", "gone").Return(true)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger,
+		service.WithLinkHealth(health, "https://example.com/sorry"))
+	router := mux.NewRouter()
+	handler.NewURLHandler(urlService).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/gone", nil))
+
+	assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
+	assert.Equal(t, "https://example.com/sorry", rr.Header().Get("Location"))
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Empty(t, rr.Result().Cookies())
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+// cardPage is served to link unfurlers instead of a redirect. It leaves out
+// og:url, which would make some unfurlers read the card of that URL instead,
+// and sends anyone else who gets it on to the destination.
+var cardPage = template.Must(template.New("card").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+{{- with .Title}}
+<meta property="og:title" content="{{.}}">
+{{- end}}
+{{- with .Description}}
+<meta name="description" content="{{.}}">
+<meta property="og:description" content="{{.}}">
+{{- end}}
+{{- with .ImageURL}}
+<meta property="og:image" content="{{.}}">
+<meta name="twitter:card" content="summary_large_image">
+{{- else}}
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta http-equiv="refresh" content="0; url={{.Destination}}">
+</head>
+<body><a href="{{.Destination}}">{{.Destination}}</a></body>
+</html>
+`))
+
+type card struct {
+	Title       string
+	Description string
+	ImageURL    string
+	Destination string
+}
+
+type PreviewHandler struct {
+	service service.PreviewService
+}
+
+func NewPreviewHandler(s service.PreviewService) *PreviewHandler {
+	return &PreviewHandler{
+		service: s,
+	}
+}
+
+func (h *PreviewHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/links/{shortened}/preview", h.SetPreviewHandler).Methods("PUT")
+	router.HandleFunc("/api/links/{shortened}/preview", h.GetPreviewHandler).Methods("GET")
+	router.HandleFunc("/api/links/{shortened}/preview", h.DeletePreviewHandler).Methods("DELETE")
+}
+
+func (h *PreviewHandler) SetPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "PreviewHandler.SetPreviewHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	var request model.LinkPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := h.service.SetPreview(ctx, owner, &domain.LinkPreview{
+		ShortUrl:    mux.Vars(r)["shortened"],
+		Title:       request.Title,
+		Description: request.Description,
+		ImageUrl:    request.ImageURL,
+	})
+	if err != nil {
+		writePreviewError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toLinkPreviewResponse(preview))
+}
+
+func (h *PreviewHandler) GetPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "PreviewHandler.GetPreviewHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	preview, err := h.service.GetPreview(ctx, owner, mux.Vars(r)["shortened"])
+	if err != nil {
+		writePreviewError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toLinkPreviewResponse(preview))
+}
+
+func (h *PreviewHandler) DeletePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "PreviewHandler.DeletePreviewHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeletePreview(ctx, owner, mux.Vars(r)["shortened"]); err != nil {
+		writePreviewError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCard serves the preview card of shortened, if it has one, to a link
+// unfurler that would otherwise be redirected to destination.
+func writeCard(w http.ResponseWriter, r *http.Request, previews service.PreviewService, shortened, destination string) bool {
+	preview, err := previews.Card(r.Context(), shortened)
+	if err != nil {
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to get link preview", "shortened", shortened, "error", err)
+		return false
+	}
+	if preview == nil {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// The same URL redirects everyone else.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	err = cardPage.Execute(w, card{
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageUrl,
+		Destination: destination,
+	})
+	if err != nil {
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to write link preview", "shortened", shortened, "error", err)
+	}
+	return true
+}
+
+func writePreviewError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrEmptyPreview),
+		errors.Is(err, service.ErrPreviewTooLong),
+		errors.Is(err, service.ErrInvalidPreviewImage):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrURLNotFound),
+		errors.Is(err, repository.ErrLinkPreviewNotFound):
+		status = http.StatusNotFound
+	default:
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to handle link preview request", "error", err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func toLinkPreviewResponse(preview *domain.LinkPreview) model.LinkPreviewResponse {
+	return model.LinkPreviewResponse{
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageUrl,
+		UpdatedAt:   preview.UpdatedAt,
+	}
+}
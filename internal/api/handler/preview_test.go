@@ -0,0 +1,217 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+const slackbot = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+
+type MockPreviewService struct {
+	mock.Mock
+}
+
+func (m *MockPreviewService) SetPreview(ctx context.Context, owner string, preview *domain.LinkPreview) (*domain.LinkPreview, error) {
+	args := m.Called(ctx, owner, preview)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LinkPreview), args.Error(1)
+}
+
+func (m *MockPreviewService) GetPreview(ctx context.Context, owner, shortURL string) (*domain.LinkPreview, error) {
+	args := m.Called(ctx, owner, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LinkPreview), args.Error(1)
+}
+
+func (m *MockPreviewService) DeletePreview(ctx context.Context, owner, shortURL string) error {
+	return m.Called(ctx, owner, shortURL).Error(0)
+}
+
+func (m *MockPreviewService) Card(ctx context.Context, shortURL string) (*domain.LinkPreview, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LinkPreview), args.Error(1)
+}
+
+func newPreviewRouter(s service.PreviewService) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey, otherAPIKey}))
+	handler.NewPreviewHandler(s).RegisterRoutes(router)
+	return router
+}
+
+func TestPreviewHandler(t *testing.T) {
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("set", func(t *testing.T) {
+		mockService := new(MockPreviewService)
+		mockService.On("SetPreview", mock.Anything, owner, &domain.LinkPreview{
+			ShortUrl: "launch",
+			Title:    "We launched",
+			ImageUrl: "https://example.com/launch.png",
+		}).Return(&domain.LinkPreview{
+			ShortUrl:  "launch",
+			Title:     "We launched",
+			ImageUrl:  "https://example.com/launch.png",
+			UpdatedAt: "2026-10-19T12:00:00Z",
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		body := `{"title": "We launched", "image_url": "https://example.com/launch.png"}`
+		newPreviewRouter(mockService).ServeHTTP(rr, newWebhookRequest("PUT", "/api/links/launch/preview", body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.LinkPreviewResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, model.LinkPreviewResponse{
+			Title:     "We launched",
+			ImageURL:  "https://example.com/launch.png",
+			UpdatedAt: "2026-10-19T12:00:00Z",
+		}, response)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mockService := new(MockPreviewService)
+		mockService.On("SetPreview", mock.Anything, owner, mock.Anything).Return(nil, service.ErrInvalidPreviewImage).Once()
+		mockService.On("SetPreview", mock.Anything, owner, mock.Anything).Return(nil, repository.ErrURLNotFound).Once()
+		mockService.On("GetPreview", mock.Anything, owner, "launch").Return(nil, repository.ErrLinkPreviewNotFound)
+		mockService.On("DeletePreview", mock.Anything, owner, "launch").Return(errors.New("database is down"))
+
+		for _, tt := range []struct {
+			method string
+			body   string
+			status int
+		}{
+			{"PUT", "not json", http.StatusBadRequest},
+			{"PUT", `{"image_url": "/launch.png"}`, http.StatusBadRequest},
+			{"PUT", `{"title": "We launched"}`, http.StatusNotFound},
+			{"GET", "", http.StatusNotFound},
+			{"DELETE", "", http.StatusInternalServerError},
+		} {
+			rr := httptest.NewRecorder()
+			newPreviewRouter(mockService).ServeHTTP(rr, newWebhookRequest(tt.method, "/api/links/launch/preview", tt.body))
+			assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.body)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockService := new(MockPreviewService)
+		mockService.On("DeletePreview", mock.Anything, owner, "launch").Return(nil)
+
+		rr := httptest.NewRecorder()
+		newPreviewRouter(mockService).ServeHTTP(rr, newWebhookRequest("DELETE", "/api/links/launch/preview", ""))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
+
+func TestPreviewHandler_RequiresLinkOwner(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{
+		OriginalUrl: "https://example.com",
+		ShortUrl:    "launch",
+		Owner:       pgtype.Text{String: middleware.OwnerID(testAPIKey), Valid: true},
+	})
+	require.NoError(t, err)
+	// Previews are never reached, so the service has no repository for them.
+	router := newPreviewRouter(service.NewPreviewService(nil, urls, nil))
+
+	for _, method := range []string{"PUT", "GET", "DELETE"} {
+		body := `{"title": "Log in again", "image_url": "https://attacker.example/login.png"}`
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, "/api/links/launch/preview", strings.NewReader(body)))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, method+" anonymous")
+
+		req := httptest.NewRequest(method, "/api/links/launch/preview", strings.NewReader(body))
+		req.Header.Set(middleware.APIKeyHeader, otherAPIKey)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code, method+" another key")
+	}
+}
+
+func TestResolveShortURLHandler_PreviewCard(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	for code, destination := range map[string]string{"launch": "https://example.com/launch?a=1&b=2", "plain": "https://example.com/plain"} {
+		_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: code, OriginalUrl: destination})
+		require.NoError(t, err)
+	}
+	previews := new(MockPreviewService)
+	previews.On("Card", mock.Anything, "launch").Return(&domain.LinkPreview{
+		Title:       `Tips & "Tricks"`,
+		Description: "<b>We launched</b>",
+		ImageUrl:    "https://example.com/launch.png",
+	}, nil)
+	previews.On("Card", mock.Anything, "plain").Return(nil, nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger)
+	router := mux.NewRouter()
+	handler.NewURLHandler(urlService, handler.WithPreviews(previews)).RegisterRoutes(router)
+
+	resolve := func(code, userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/"+code, nil)
+		req.Header.Set("User-Agent", userAgent)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("unfurler", func(t *testing.T) {
+		rr := resolve("launch", slackbot)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		assert.Empty(t, rr.Header().Get("Location"))
+		body := rr.Body.String()
+		assert.Contains(t, body, `<meta property="og:title" content="Tips &amp; &#34;Tricks&#34;">`)
+		assert.Contains(t, body, `<meta property="og:description" content="&lt;b&gt;We launched&lt;/b&gt;">`)
+		assert.Contains(t, body, `<meta property="og:image" content="https://example.com/launch.png">`)
+		assert.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+		assert.Contains(t, body, `<meta http-equiv="refresh" content="0; url=https://example.com/launch?a=1&amp;b=2">`)
+		assert.NotContains(t, body, "og:url")
+	})
+
+	t.Run("visitor", func(t *testing.T) {
+		rr := resolve("launch", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36")
+
+		assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+		assert.Equal(t, "https://example.com/launch?a=1&b=2", rr.Header().Get("Location"))
+	})
+
+	t.Run("link without a preview", func(t *testing.T) {
+		rr := resolve("plain", slackbot)
+
+		assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+		assert.Equal(t, "https://example.com/plain", rr.Header().Get("Location"))
+	})
+}
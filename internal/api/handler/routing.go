@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type RoutingHandler struct {
+	service service.RoutingService
+}
+
+func NewRoutingHandler(s service.RoutingService) *RoutingHandler {
+	return &RoutingHandler{
+		service: s,
+	}
+}
+
+func (h *RoutingHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/links/{shortened}/rules", h.CreateRuleHandler).Methods("POST")
+	router.HandleFunc("/api/links/{shortened}/rules", h.ListRulesHandler).Methods("GET")
+	router.HandleFunc("/api/links/{shortened}/rules/{id:[0-9]+}", h.DeleteRuleHandler).Methods("DELETE")
+	router.HandleFunc("/api/links/{shortened}/variants", h.SetVariantsHandler).Methods("PUT")
+	router.HandleFunc("/api/links/{shortened}/variants", h.DeleteVariantsHandler).Methods("DELETE")
+}
+
+func (h *RoutingHandler) CreateRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "RoutingHandler.CreateRuleHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	var request model.RoutingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.CreateRule(ctx, owner, &domain.RoutingRule{
+		ShortUrl:    mux.Vars(r)["shortened"],
+		Priority:    request.Priority,
+		Devices:     request.Devices,
+		OSes:        request.OperatingSystems,
+		Languages:   request.Languages,
+		Countries:   request.Countries,
+		Destination: request.Destination,
+	})
+	if err != nil {
+		writeRoutingError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, toRoutingRuleResponse(rule))
+}
+
+func (h *RoutingHandler) ListRulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "RoutingHandler.ListRulesHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	rules, err := h.service.ListRules(ctx, owner, mux.Vars(r)["shortened"])
+	if err != nil {
+		writeRoutingError(w, r, err)
+		return
+	}
+
+	response := make([]model.RoutingRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, toRoutingRuleResponse(rule))
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *RoutingHandler) DeleteRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "RoutingHandler.DeleteRuleHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+
+	if err := h.service.DeleteRule(ctx, owner, vars["shortened"], id); err != nil {
+		writeRoutingError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoutingHandler) SetVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "RoutingHandler.SetVariantsHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	var request model.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	variants := make([]*domain.Variant, 0, len(request.Variants))
+	for _, variant := range request.Variants {
+		variants = append(variants, &domain.Variant{
+			Destination: variant.Destination,
+			Weight:      variant.Weight,
+		})
+	}
+	variants, err := h.service.SetVariants(ctx, owner, mux.Vars(r)["shortened"], variants)
+	if err != nil {
+		writeRoutingError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toVariantResponses(variants))
+}
+
+func (h *RoutingHandler) DeleteVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "RoutingHandler.DeleteVariantsHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteVariants(ctx, owner, mux.Vars(r)["shortened"]); err != nil {
+		writeRoutingError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeRoutingError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrInvalidDestination),
+		errors.Is(err, service.ErrRuleWithoutConditions),
+		errors.Is(err, service.ErrInvalidRuleCondition),
+		errors.Is(err, service.ErrInvalidVariantCount),
+		errors.Is(err, service.ErrDuplicateVariant),
+		errors.Is(err, service.ErrInvalidWeights):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrURLNotFound),
+		errors.Is(err, repository.ErrRoutingRuleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrTooManyRules):
+		status = http.StatusConflict
+	default:
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to handle routing rule request", "error", err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func toRoutingRuleResponse(rule *domain.RoutingRule) model.RoutingRuleResponse {
+	return model.RoutingRuleResponse{
+		ID:               rule.ID,
+		Priority:         rule.Priority,
+		Devices:          nonNil(rule.Devices),
+		OperatingSystems: nonNil(rule.OSes),
+		Languages:        nonNil(rule.Languages),
+		Countries:        nonNil(rule.Countries),
+		Destination:      rule.Destination,
+		CreatedAt:        rule.CreatedAt,
+	}
+}
+
+func toVariantResponses(variants []*domain.Variant) []model.VariantResponse {
+	response := make([]model.VariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		response = append(response, model.VariantResponse{
+			ID:          variant.ID,
+			Destination: variant.Destination,
+			Weight:      variant.Weight,
+			ClickCount:  variant.ClickCount,
+			CreatedAt:   variant.CreatedAt,
+		})
+	}
+	return response
+}
+
+// nonNil makes empty conditions encode as [] rather than null.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,402 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/privacy"
+	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/targeting"
+)
+
+type MockRoutingService struct {
+	mock.Mock
+}
+
+func (m *MockRoutingService) CreateRule(ctx context.Context, owner string, rule *domain.RoutingRule) (*domain.RoutingRule, error) {
+	args := m.Called(ctx, owner, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoutingRule), args.Error(1)
+}
+
+func (m *MockRoutingService) ListRules(ctx context.Context, owner, shortURL string) ([]*domain.RoutingRule, error) {
+	args := m.Called(ctx, owner, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RoutingRule), args.Error(1)
+}
+
+func (m *MockRoutingService) DeleteRule(ctx context.Context, owner, shortURL string, id int64) error {
+	args := m.Called(ctx, owner, shortURL, id)
+	return args.Error(0)
+}
+
+func (m *MockRoutingService) SetVariants(ctx context.Context, owner, shortURL string, variants []*domain.Variant) ([]*domain.Variant, error) {
+	args := m.Called(ctx, owner, shortURL, variants)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Variant), args.Error(1)
+}
+
+func (m *MockRoutingService) ListVariants(ctx context.Context, shortURL string) ([]*domain.Variant, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Variant), args.Error(1)
+}
+
+func (m *MockRoutingService) DeleteVariants(ctx context.Context, owner, shortURL string) error {
+	args := m.Called(ctx, owner, shortURL)
+	return args.Error(0)
+}
+
+func (m *MockRoutingService) RecordVariantClick(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRoutingService) Route(ctx context.Context, shortURL string, visitor *domain.Visitor) (*domain.Route, error) {
+	args := m.Called(ctx, shortURL, visitor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Route), args.Error(1)
+}
+
+func newRoutingRouter(s service.RoutingService) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey, otherAPIKey}))
+	handler.NewRoutingHandler(s).RegisterRoutes(router)
+	return router
+}
+
+// otherAPIKey belongs to someone who does not own the links of the tests.
+const otherAPIKey = "other-key"
+
+func TestCreateRuleHandler(t *testing.T) {
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockRoutingService)
+		mockService.On("CreateRule", mock.Anything, owner, &domain.RoutingRule{
+			ShortUrl:    "app",
+			Priority:    10,
+			OSes:        []string{"ios"},
+			Destination: "https://apps.apple.com/app/id1",
+		}).Return(&domain.RoutingRule{
+			ID:          7,
+			ShortUrl:    "app",
+			Priority:    10,
+			OSes:        []string{"ios"},
+			Destination: "https://apps.apple.com/app/id1",
+			CreatedAt:   "2025-01-02T03:04:05Z",
+		}, nil)
+
+		req := newWebhookRequest("POST", "/api/links/app/rules",
+			`{"priority":10,"operating_systems":["ios"],"destination":"https://apps.apple.com/app/id1"}`)
+		rr := httptest.NewRecorder()
+		newRoutingRouter(mockService).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"id":7,"priority":10,"devices":[],"operating_systems":["ios"],"languages":[],"countries":[],
+			"destination":"https://apps.apple.com/app/id1","created_at":"2025-01-02T03:04:05Z"}`, rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"invalid condition", service.ErrInvalidRuleCondition, http.StatusBadRequest},
+		{"unknown link", repository.ErrURLNotFound, http.StatusNotFound},
+		{"too many rules", service.ErrTooManyRules, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRoutingService)
+			mockService.On("CreateRule", mock.Anything, owner, mock.Anything).Return(nil, tt.err)
+
+			req := newWebhookRequest("POST", "/api/links/app/rules", `{"devices":["watch"]}`)
+			rr := httptest.NewRecorder()
+			newRoutingRouter(mockService).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expected, rr.Code)
+		})
+	}
+}
+
+func TestListRulesHandler(t *testing.T) {
+	mockService := new(MockRoutingService)
+	mockService.On("ListRules", mock.Anything, middleware.OwnerID(testAPIKey), "app").Return([]*domain.RoutingRule{
+		{ID: 1, Countries: []string{"AT", "DE"}, Destination: "https://example.com/de"},
+	}, nil)
+
+	req := newWebhookRequest("GET", "/api/links/app/rules", "")
+	rr := httptest.NewRecorder()
+	newRoutingRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []model.RoutingRuleResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	assert.Equal(t, []string{"AT", "DE"}, response[0].Countries)
+	assert.Equal(t, []string{}, response[0].Devices)
+}
+
+func TestDeleteRuleHandler(t *testing.T) {
+	mockService := new(MockRoutingService)
+	owner := middleware.OwnerID(testAPIKey)
+	mockService.On("DeleteRule", mock.Anything, owner, "app", int64(1)).Return(nil)
+	mockService.On("DeleteRule", mock.Anything, owner, "app", int64(2)).Return(repository.ErrRoutingRuleNotFound)
+	router := newRoutingRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, newWebhookRequest("DELETE", "/api/links/app/rules/1", ""))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, newWebhookRequest("DELETE", "/api/links/app/rules/2", ""))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestResolveShortURLHandler_TargetedRedirect(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: "app", OriginalUrl: "https://example.com"})
+	require.NoError(t, err)
+	geo, err := targeting.ReadGeoDB(strings.NewReader("192.0.2.0/24,DE\n"))
+	require.NoError(t, err)
+
+	routing := new(MockRoutingService)
+	routing.On("Route", mock.Anything, "app", mock.MatchedBy(func(v *domain.Visitor) bool {
+		return v.Device == domain.DeviceMobile && v.OS == domain.OSiOS && v.Language == "de-at" && v.Country == "DE"
+	})).Return(&domain.Route{Destination: "https://apps.apple.com/app/id1", Targeted: true}, nil)
+	routing.On("Route", mock.Anything, "app", mock.Anything).Return(&domain.Route{}, nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger, service.WithRouting(routing))
+	router := mux.NewRouter()
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingOptions{}))
+	handler.NewURLHandler(urlService, handler.WithGeoDB(geo)).RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/app", nil)
+	req.RemoteAddr = "192.0.2.10:5555"
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148")
+	req.Header.Set("Accept-Language", "de-AT,de;q=0.9,en;q=0.8")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
+	assert.Equal(t, "https://apps.apple.com/app/id1", rr.Header().Get("Location"))
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, handler.VisitorCookie, cookies[0].Name)
+	assert.Len(t, cookies[0].Value, 32)
+
+	req = httptest.NewRequest("GET", "/app", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusPermanentRedirect, rr.Code, "links without rules for the visitor stay cacheable")
+	assert.Equal(t, "https://example.com", rr.Header().Get("Location"))
+}
+
+func TestResolveShortURLHandler_VisitorKey(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com"})
+	require.NoError(t, err)
+
+	var keys []string
+	routing := new(MockRoutingService)
+	routing.On("Route", mock.Anything, "ab", mock.Anything).Run(func(args mock.Arguments) {
+		keys = append(keys, args.Get(2).(*domain.Visitor).Key)
+	}).Return(&domain.Route{Destination: "https://example.com/b", VariantID: 2, Targeted: true}, nil)
+	routing.On("RecordVariantClick", mock.Anything, int64(2)).Return(nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger, service.WithRouting(routing))
+	router := mux.NewRouter()
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingOptions{}))
+	handler.NewURLHandler(urlService).RegisterRoutes(router)
+
+	resolve := func(remoteAddr string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/ab", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := resolve("192.0.2.10:5555", nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, first.Code)
+	assert.Equal(t, "https://example.com/b", first.Header().Get("Location"))
+	cookie := first.Result().Cookies()[0]
+
+	resolve("192.0.2.10:6666", nil)
+	resolve("198.51.100.7:5555", cookie)
+	resolve("198.51.100.7:5555", &http.Cookie{Name: handler.VisitorCookie, Value: "not-a-key"})
+
+	require.Len(t, keys, 4)
+	assert.Equal(t, cookie.Value, keys[0])
+	assert.Equal(t, keys[0], keys[1], "the same client gets the same key")
+	assert.Equal(t, keys[0], keys[2], "the cookie keeps the key when the address changes")
+	assert.NotEqual(t, keys[0], keys[3], "malformed cookies are ignored")
+}
+
+func TestResolveShortURLHandler_Privacy(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com"})
+	require.NoError(t, err)
+
+	var visitors []*domain.Visitor
+	routing := new(MockRoutingService)
+	routing.On("Route", mock.Anything, "ab", mock.Anything).Run(func(args mock.Arguments) {
+		visitors = append(visitors, args.Get(2).(*domain.Visitor))
+	}).Return(&domain.Route{Destination: "https://example.com/b", VariantID: 2, Targeted: true}, nil)
+	routing.On("RecordVariantClick", mock.Anything, int64(2)).Return(nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	anonymizer, err := privacy.NewIPAnonymizer(privacy.IPModeTruncate, 0, privacy.NewLocalSalts(), logger)
+	require.NoError(t, err)
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger, service.WithRouting(routing))
+	router := mux.NewRouter()
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingOptions{IPAnonymizer: anonymizer}))
+	handler.NewURLHandler(urlService).RegisterRoutes(router)
+
+	resolve := func(remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/ab", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header = header
+		req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	resolve("192.0.2.10:5555", http.Header{})
+	resolve("192.0.2.99:5555", http.Header{})
+	rr := resolve("192.0.2.10:5555", http.Header{"Dnt": {"1"}})
+	resolve("192.0.2.10:5555", http.Header{"Sec-Gpc": {"1"}})
+
+	require.Len(t, visitors, 4)
+	assert.Equal(t, visitors[0].Key, visitors[1].Key, "visitors are told apart by their anonymized address")
+	assert.False(t, visitors[0].OptedOut)
+	assert.True(t, visitors[2].OptedOut)
+	assert.True(t, visitors[3].OptedOut)
+	assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
+	assert.Equal(t, "https://example.com/b", rr.Header().Get("Location"))
+	assert.Empty(t, rr.Result().Cookies(), "visitors who opt out get no cookie")
+}
+
+func TestSetVariantsHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockRoutingService)
+		mockService.On("SetVariants", mock.Anything, middleware.OwnerID(testAPIKey), "ab", []*domain.Variant{
+			{Destination: "https://example.com/a", Weight: 70},
+			{Destination: "https://example.com/b", Weight: 30},
+		}).Return([]*domain.Variant{
+			{ID: 1, Destination: "https://example.com/a", Weight: 70, ClickCount: 12, CreatedAt: "2025-01-02T03:04:05Z"},
+			{ID: 2, Destination: "https://example.com/b", Weight: 30, CreatedAt: "2025-01-02T03:04:05Z"},
+		}, nil)
+
+		req := newWebhookRequest("PUT", "/api/links/ab/variants",
+			`{"variants":[{"destination":"https://example.com/a","weight":70},{"destination":"https://example.com/b","weight":30}]}`)
+		rr := httptest.NewRecorder()
+		newRoutingRouter(mockService).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response []model.VariantResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response, 2)
+		assert.Equal(t, int64(12), response[0].ClickCount)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid weights", func(t *testing.T) {
+		mockService := new(MockRoutingService)
+		mockService.On("SetVariants", mock.Anything, middleware.OwnerID(testAPIKey), "ab", mock.Anything).Return(nil, service.ErrInvalidWeights)
+
+		req := newWebhookRequest("PUT", "/api/links/ab/variants", `{"variants":[]}`)
+		rr := httptest.NewRecorder()
+		newRoutingRouter(mockService).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteVariantsHandler(t *testing.T) {
+	mockService := new(MockRoutingService)
+	mockService.On("DeleteVariants", mock.Anything, middleware.OwnerID(testAPIKey), "ab").Return(nil)
+
+	rr := httptest.NewRecorder()
+	newRoutingRouter(mockService).ServeHTTP(rr, newWebhookRequest("DELETE", "/api/links/ab/variants", ""))
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRoutingHandler_RequiresLinkOwner(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{
+		OriginalUrl: "https://example.com",
+		ShortUrl:    "app",
+		Owner:       pgtype.Text{String: middleware.OwnerID(testAPIKey), Valid: true},
+	})
+	require.NoError(t, err)
+	// Rules and variants are never reached, so the service has no repositories
+	// for them.
+	routing := service.NewRoutingService(nil, nil, urls, repository.NewNoopTxManager(), 10, time.Minute)
+	router := newRoutingRouter(routing)
+
+	requests := []struct{ method, target, body string }{
+		{"POST", "/api/links/app/rules", `{"operating_systems":["ios"],"destination":"https://attacker.example"}`},
+		{"GET", "/api/links/app/rules", ""},
+		{"DELETE", "/api/links/app/rules/1", ""},
+		{"PUT", "/api/links/app/variants", `{"variants":[{"destination":"https://attacker.example/a","weight":50},` +
+			`{"destination":"https://attacker.example/b","weight":50}]}`},
+		{"DELETE", "/api/links/app/variants", ""},
+	}
+	for _, r := range requests {
+		t.Run(r.method+" "+r.target, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(r.method, r.target, strings.NewReader(r.body)))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code, "anonymous")
+
+			req := httptest.NewRequest(r.method, r.target, strings.NewReader(r.body))
+			req.Header.Set(middleware.APIKeyHeader, otherAPIKey)
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusNotFound, rr.Code, "another key")
+		})
+	}
+}
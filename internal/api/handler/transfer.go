@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/transfer"
+)
+
+const MaxImportBodySize = 256 << 20
+
+type TransferHandler struct {
+	service service.TransferService
+}
+
+func NewTransferHandler(s service.TransferService) *TransferHandler {
+	return &TransferHandler{
+		service: s,
+	}
+}
+
+func (h *TransferHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/export", h.ExportHandler).Methods("GET")
+	router.HandleFunc("/api/import", h.ImportHandler).Methods("POST")
+}
+
+func (h *TransferHandler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.GetLoggerFromContext(r.Context())
+
+	format := transfer.FormatNDJSON
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = transfer.ParseFormat(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	encoder := transfer.NewEncoder(w, format)
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="links.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	err := h.service.ExportURLs(r.Context(), func(url *domain.Url) error {
+		if !started {
+			start()
+		}
+		return encoder.Encode(url)
+	})
+	if err != nil && !started {
+		logger.Error("Failed to export URLs", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !started {
+		start()
+	}
+	if err == nil {
+		err = encoder.Flush()
+	}
+	if err != nil {
+		// The status line is already on the wire, so the client can only
+		// notice the failure through the truncated body.
+		logger.Error("Failed to export URLs", "error", err)
+	}
+}
+
+func (h *TransferHandler) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.GetLoggerFromContext(r.Context())
+	query := r.URL.Query()
+
+	format, err := importFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	policy, err := service.ParseConflictPolicy(query.Get("policy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "dry_run must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxImportBodySize)
+	report, err := h.service.ImportURLs(r.Context(), transfer.NewDecoder(body, format), service.ImportOptions{
+		Policy: policy,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		logger.Error("Failed to import URLs", "error", err)
+		status := http.StatusInternalServerError
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Aborted {
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(NewImportReportResponse(report)); err != nil {
+		logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+func importFormat(r *http.Request) (transfer.Format, error) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		return transfer.ParseFormat(value)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return transfer.FormatNDJSON, nil
+	}
+	switch mediaType {
+	case "text/csv":
+		return transfer.FormatCSV, nil
+	default:
+		return transfer.FormatNDJSON, nil
+	}
+}
+
+// NewImportReportResponse converts an import report into its JSON form, shared
+// by the API and the command line.
+func NewImportReportResponse(report *domain.ImportReport) model.ImportReportResponse {
+	errs := make([]model.ImportErrorResponse, 0, len(report.Errors))
+	for _, e := range report.Errors {
+		errs = append(errs, model.ImportErrorResponse{
+			Record:   e.Record,
+			ShortURL: e.ShortUrl,
+			Message:  e.Message,
+		})
+	}
+
+	return model.ImportReportResponse{
+		DryRun:      report.DryRun,
+		Aborted:     report.Aborted,
+		Total:       report.Total,
+		Created:     report.Created,
+		Overwritten: report.Overwritten,
+		Skipped:     report.Skipped,
+		Failed:      report.Failed,
+		Errors:      errs,
+	}
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/transfer"
+)
+
+type MockTransferService struct {
+	mock.Mock
+}
+
+func (m *MockTransferService) ExportURLs(ctx context.Context, fn func(url *domain.Url) error) error {
+	args := m.Called(ctx, fn)
+	if urls, ok := args.Get(0).([]*domain.Url); ok {
+		for _, url := range urls {
+			if err := fn(url); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTransferService) ImportURLs(ctx context.Context, decoder transfer.Decoder, opts service.ImportOptions) (*domain.ImportReport, error) {
+	args := m.Called(ctx, decoder, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportReport), args.Error(1)
+}
+
+func TestExportHandler(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		urls := []*domain.Url{{ShortUrl: "ggl", OriginalUrl: "https://google.com", ClickCount: 3}}
+		mockService.On("ExportURLs", mock.Anything, mock.Anything).Return(urls, nil)
+
+		req := httptest.NewRequest("GET", "/api/export?format=csv", nil)
+		rr := httptest.NewRecorder()
+
+		transferHandler.ExportHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "short_url,original_url,click_count,created_at,updated_at,owner\nggl,https://google.com,3,,,\n", rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		req := httptest.NewRequest("GET", "/api/export?format=xml", nil)
+		rr := httptest.NewRecorder()
+
+		transferHandler.ExportHandler(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "ExportURLs")
+	})
+
+	t.Run("service fails before first record", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		mockService.On("ExportURLs", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+		req := httptest.NewRequest("GET", "/api/export", nil)
+		rr := httptest.NewRecorder()
+
+		transferHandler.ExportHandler(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestImportHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		report := &domain.ImportReport{DryRun: true, Total: 2, Created: 1, Skipped: 1}
+		mockService.On("ImportURLs", mock.Anything, mock.Anything, service.ImportOptions{
+			Policy: service.ConflictOverwrite,
+			DryRun: true,
+		}).Return(report, nil)
+
+		req := httptest.NewRequest("POST", "/api/import?policy=overwrite&dry_run=true", strings.NewReader("short_url,original_url\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+
+		transferHandler.ImportHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.ImportReportResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.True(t, response.DryRun)
+		assert.Equal(t, 1, response.Created)
+		assert.Equal(t, 1, response.Skipped)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("aborted on conflict", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		report := &domain.ImportReport{Aborted: true, Total: 1, Failed: 1}
+		mockService.On("ImportURLs", mock.Anything, mock.Anything, mock.Anything).Return(report, nil)
+
+		req := httptest.NewRequest("POST", "/api/import?policy=fail", strings.NewReader("{}"))
+		rr := httptest.NewRecorder()
+
+		transferHandler.ImportHandler(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		req := httptest.NewRequest("POST", "/api/import?policy=merge", strings.NewReader("{}"))
+		rr := httptest.NewRecorder()
+
+		transferHandler.ImportHandler(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "ImportURLs")
+	})
+
+	t.Run("service returns error", func(t *testing.T) {
+		mockService := new(MockTransferService)
+		transferHandler := handler.NewTransferHandler(mockService)
+
+		mockService.On("ImportURLs", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+		req := httptest.NewRequest("POST", "/api/import", strings.NewReader("{}"))
+		rr := httptest.NewRecorder()
+
+		transferHandler.ImportHandler(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -1,34 +1,87 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"net/netip"
+	"regexp"
+	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
 
 	"github.com/unwale/url-shortener/internal/api/middleware"
 	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/metrics"
 	"github.com/unwale/url-shortener/internal/service"
+	"github.com/unwale/url-shortener/internal/targeting"
+	"github.com/unwale/url-shortener/internal/telemetry"
 )
 
+// VisitorCookie keeps the variant a visitor of a split link is assigned to
+// when their address or browser changes.
+const VisitorCookie = "visitor"
+
+// Route templates served by URLHandler.
+const (
+	ShortenRoute = "/api/shorten"
+	ResolveRoute = "/{shortened}"
+	StatsRoute   = "/api/stats/{shortened}"
+)
+
+var visitorKey = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+var tracer = otel.Tracer("github.com/unwale/url-shortener/internal/api/handler")
+
 type URLHandler struct {
-	service service.URLService
+	service  service.URLService
+	geo      *targeting.GeoDB
+	previews service.PreviewService
+}
+
+type URLHandlerOption func(*URLHandler)
+
+// WithGeoDB resolves the country of visitors for routing rules. Without it,
+// country conditions never match.
+func WithGeoDB(geo *targeting.GeoDB) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.geo = geo
+	}
+}
+
+// WithPreviews serves link unfurlers the preview card of links that have one
+// instead of redirecting them.
+func WithPreviews(previews service.PreviewService) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.previews = previews
+	}
 }
 
-func NewURLHandler(s service.URLService) *URLHandler {
-	return &URLHandler{
+func NewURLHandler(s service.URLService, opts ...URLHandlerOption) *URLHandler {
+	h := &URLHandler{
 		service: s,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *URLHandler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/api/shorten", h.ShortenURLHandler).Methods("POST")
-	router.HandleFunc("/{shortened}", h.ResolveShortURLHandler).Methods("GET")
-	router.HandleFunc("/api/stats/{shortened}", h.StatsHandler).Methods("GET")
+	router.HandleFunc(ShortenRoute, h.ShortenURLHandler).Methods("POST")
+	router.HandleFunc(ResolveRoute, h.ResolveShortURLHandler).Methods("GET", "HEAD")
+	router.HandleFunc(StatsRoute, h.StatsHandler).Methods("GET")
 }
 
 func (h *URLHandler) ShortenURLHandler(w http.ResponseWriter, r *http.Request) {
-	logger := middleware.GetLoggerFromContext(r.Context())
+	ctx, span := tracer.Start(r.Context(), "URLHandler.ShortenURLHandler")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	logger := middleware.GetLoggerFromContext(ctx)
 
 	var request model.ShortenURLRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -37,9 +90,11 @@ func (h *URLHandler) ShortenURLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	shornetedURL, err := h.service.CreateShortURL(r.Context(), request.URL, request.Alias)
+	ctx = service.WithOwner(ctx, middleware.GetOwnerFromContext(ctx))
+	shornetedURL, err := h.service.CreateShortURL(ctx, request.URL, request.Alias)
 	if err != nil {
 		logger.Error("Failed to create short URL", "error", err)
+		telemetry.RecordError(span, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -58,7 +113,11 @@ func (h *URLHandler) ShortenURLHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *URLHandler) ResolveShortURLHandler(w http.ResponseWriter, r *http.Request) {
-	logger := middleware.GetLoggerFromContext(r.Context())
+	ctx, span := tracer.Start(r.Context(), "URLHandler.ResolveShortURLHandler")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	logger := middleware.GetLoggerFromContext(ctx)
 
 	vars := mux.Vars(r)
 	shortened := vars["shortened"]
@@ -68,20 +127,79 @@ func (h *URLHandler) ResolveShortURLHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	originalURL, err := h.service.ResolveShortURL(r.Context(), shortened)
+	visitor := h.visitor(r)
+	originalURL, err := h.service.ResolveShortURL(service.WithVisitor(ctx, visitor), shortened)
 	if err != nil {
 		logger.Error("Failed to resolve short URL", "error", err)
+		telemetry.RecordError(span, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if h.previews != nil && targeting.IsUnfurler(r.UserAgent()) && writeCard(w, r, h.previews, shortened, originalURL) {
+		metrics.PreviewCardsTotal.Inc()
+		return
+	}
+
+	metrics.RedirectsTotal.Inc()
 	logger.Info("Redirecting to original URL", "shortened", shortened, "originalURL", originalURL)
 	w.Header().Set("Location", originalURL)
+	if visitor.Routed || visitor.Fallback {
+		if visitor.Routed && !visitor.OptedOut {
+			http.SetCookie(w, &http.Cookie{
+				Name:     VisitorCookie,
+				Value:    visitor.Key,
+				Path:     "/",
+				MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		// Caching the redirect would send the next visitor, or this one from
+		// another country, to the same destination, and keep sending visitors
+		// to the fallback once the link is repaired.
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusTemporaryRedirect)
+		return
+	}
 	w.WriteHeader(http.StatusPermanentRedirect)
 }
 
+// visitor describes the client of r for the routing rules and analytics of
+// a link. HEAD requests and prefetches are counted as bots, since nobody
+// followed the link. Visitors are told apart by their anonymized address, and
+// their country is looked up without keeping the address.
+func (h *URLHandler) visitor(r *http.Request) *domain.Visitor {
+	device, os := targeting.ParseUserAgent(r.UserAgent())
+	visitor := &domain.Visitor{
+		Device:   device,
+		OS:       os,
+		Browser:  targeting.ParseBrowser(r.UserAgent()),
+		Language: targeting.PreferredLanguage(r.Header.Get("Accept-Language")),
+		Referrer: targeting.ReferrerDomain(r.Referer()),
+		Bot:      device == domain.DeviceBot || r.Method == http.MethodHead || targeting.IsPrefetch(r.Header),
+		OptedOut: targeting.OptsOut(r.Header),
+	}
+	clientIP := middleware.GetClientIPFromContext(r.Context())
+	if addr, err := netip.ParseAddr(clientIP); err == nil {
+		visitor.Country = h.geo.Country(addr)
+	}
+
+	if cookie, err := r.Cookie(VisitorCookie); err == nil && visitorKey.MatchString(cookie.Value) {
+		visitor.Key = cookie.Value
+	} else {
+		sum := sha256.Sum256([]byte(middleware.GetAnonymizedIPFromContext(r.Context()) + "\x00" + r.UserAgent()))
+		visitor.Key = hex.EncodeToString(sum[:16])
+	}
+	return visitor
+}
+
 func (h *URLHandler) StatsHandler(w http.ResponseWriter, r *http.Request) {
-	logger := middleware.GetLoggerFromContext(r.Context())
+	ctx, span := tracer.Start(r.Context(), "URLHandler.StatsHandler")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	logger := middleware.GetLoggerFromContext(ctx)
 
 	vars := mux.Vars(r)
 	shortened := vars["shortened"]
@@ -94,6 +212,7 @@ func (h *URLHandler) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.service.GetShortURLStats(r.Context(), shortened)
 	if err != nil {
 		logger.Error("Failed to get short URL stats", "error", err)
+		telemetry.RecordError(span, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -104,6 +223,42 @@ func (h *URLHandler) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		ClickCount:  int(stats.ClickCount),
 		CreatedAt:   stats.CreatedAt,
 		UpdatedAt:   stats.UpdatedAt,
+		Variants:    toVariantResponses(stats.Variants),
+	}
+	if analytics := stats.Analytics; analytics != nil {
+		response.BotClickCount = &analytics.BotClicks
+		response.Analytics = &model.ClickAnalyticsResponse{
+			Referrers:        toValueCountResponses(analytics.Referrers),
+			Browsers:         toValueCountResponses(analytics.Browsers),
+			OperatingSystems: toValueCountResponses(analytics.OSes),
+			Devices:          toValueCountResponses(analytics.Devices),
+			Hourly:           toHourlyCountResponses(analytics.Hourly),
+			Daily:            toDailyCountResponses(analytics.Daily),
+		}
+	}
+	if visitors := stats.UniqueVisitors; visitors != nil {
+		response.UniqueVisitors = &model.UniqueVisitorsResponse{
+			Total:      visitors.Total,
+			Last7Days:  visitors.Last7Days,
+			Last30Days: visitors.Last30Days,
+			Daily:      toDailyCountResponses(visitors.Daily),
+		}
+	}
+	if stats.Health != nil {
+		health := toLinkHealthResponse(stats.Health)
+		// The link is already described by the rest of the response.
+		health.ShortURL, health.OriginalURL = "", ""
+		response.Health = &health
+	}
+	if metadata := stats.Metadata; metadata != nil {
+		response.Metadata = &model.LinkMetadataResponse{
+			Title:       metadata.Title,
+			Description: metadata.Description,
+			FaviconURL:  metadata.FaviconUrl,
+			OpenGraph:   metadata.OpenGraph,
+			Error:       metadata.Error,
+			FetchedAt:   metadata.FetchedAt,
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -114,3 +269,27 @@ func (h *URLHandler) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func toHourlyCountResponses(counts []domain.HourlyCount) []model.HourlyCountResponse {
+	response := make([]model.HourlyCountResponse, 0, len(counts))
+	for _, count := range counts {
+		response = append(response, model.HourlyCountResponse{Hour: count.Hour, Count: count.Count})
+	}
+	return response
+}
+
+func toDailyCountResponses(counts []domain.DailyCount) []model.DailyCountResponse {
+	response := make([]model.DailyCountResponse, 0, len(counts))
+	for _, count := range counts {
+		response = append(response, model.DailyCountResponse{Date: count.Date, Count: count.Count})
+	}
+	return response
+}
+
+func toValueCountResponses(counts []domain.ValueCount) []model.ValueCountResponse {
+	response := make([]model.ValueCountResponse, 0, len(counts))
+	for _, count := range counts {
+		response = append(response, model.ValueCountResponse{Value: count.Value, Count: count.Count})
+	}
+	return response
+}
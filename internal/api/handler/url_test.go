@@ -4,18 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	db "github.com/unwale/url-shortener/db/sqlc"
 	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
 	"github.com/unwale/url-shortener/internal/api/model"
+	"github.com/unwale/url-shortener/internal/domain/cache"
 	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
 )
 
 type MockURLService struct {
@@ -40,6 +49,19 @@ func (m *MockURLService) GetShortURLStats(ctx context.Context, shortenedURL stri
 	return args.Get(0).(*domain.Url), args.Error(1)
 }
 
+func (m *MockURLService) DeleteShortURL(ctx context.Context, shortenedURL string) error {
+	args := m.Called(ctx, shortenedURL)
+	return args.Error(0)
+}
+
+func (m *MockURLService) ListShortURLs(ctx context.Context, limit, offset int) ([]*domain.Url, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Url), args.Error(1)
+}
+
 func TestShortenURLHandler(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockService := new(MockURLService)
@@ -97,6 +119,101 @@ func TestShortenURLHandler(t *testing.T) {
 
 }
 
+type clickRecorder struct {
+	clicks chan *domain.Click
+}
+
+func (r *clickRecorder) RecordClick(_ context.Context, click *domain.Click) error {
+	r.clicks <- click
+	return nil
+}
+
+func (r *clickRecorder) GetClickAnalytics(context.Context, string, int32) (*domain.ClickAnalytics, error) {
+	return &domain.ClickAnalytics{}, nil
+}
+
+func (r *clickRecorder) RollUpClicks(context.Context, time.Duration, time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (r *clickRecorder) DeleteClickEvents(context.Context, time.Time, int32) (int64, error) {
+	return 0, nil
+}
+
+func (r *clickRecorder) DeleteHourlyClickStats(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestResolveShortURLHandler_ClassifiesClicks(t *testing.T) {
+	urls := repository.NewMemoryURLRepository()
+	_, err := urls.CreateURL(context.Background(), &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com"})
+	require.NoError(t, err)
+
+	recorder := &clickRecorder{clicks: make(chan *domain.Click, 1)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	urlService := service.NewURLService(urls, cache.NewNoopURLCache(), logger, service.WithAnalytics(recorder))
+	router := mux.NewRouter()
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingOptions{}))
+	handler.NewURLHandler(urlService).RegisterRoutes(router)
+
+	browser := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    domain.Click
+	}{
+		{
+			name:    "browser",
+			method:  "GET",
+			headers: map[string]string{"User-Agent": browser, "Referer": "https://www.news.example.org/front"},
+			want: domain.Click{ShortUrl: "ab", Referrer: "news.example.org", Browser: domain.BrowserChrome,
+				OS: domain.OSWindows, Device: domain.DeviceDesktop},
+		},
+		{
+			name:    "crawler",
+			method:  "GET",
+			headers: map[string]string{"User-Agent": "Mozilla/5.0 (compatible; bingbot/2.0)"},
+			want:    domain.Click{ShortUrl: "ab", Bot: true},
+		},
+		{
+			name:    "head request",
+			method:  "HEAD",
+			headers: map[string]string{"User-Agent": browser},
+			want:    domain.Click{ShortUrl: "ab", Bot: true},
+		},
+		{
+			name:    "prefetch",
+			method:  "GET",
+			headers: map[string]string{"User-Agent": browser, "Sec-Purpose": "prefetch;prerender"},
+			want:    domain.Click{ShortUrl: "ab", Bot: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/ab", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+			click := <-recorder.clicks
+			if tt.want.Bot {
+				assert.True(t, click.Bot)
+			} else {
+				assert.Equal(t, tt.want, *click)
+			}
+		})
+	}
+
+	stats, err := urls.GetURLByShortened(context.Background(), "ab")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.ClickCount, "only the browser click is counted")
+}
+
 func TestResolveShortURLHandler(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockService := new(MockURLService)
@@ -119,6 +236,48 @@ func TestResolveShortURLHandler(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("includes link metadata", func(t *testing.T) {
+		mockService := new(MockURLService)
+		urlHandler := handler.NewURLHandler(mockService)
+
+		shortened := "123xyz"
+		stats := &domain.Url{
+			ShortUrl:    shortened,
+			OriginalUrl: "https://example.com",
+			Metadata: &domain.LinkMetadata{
+				ShortUrl:    shortened,
+				FetchedUrl:  "https://example.com",
+				Title:       "Example Domain",
+				Description: "For use in examples",
+				FaviconUrl:  "https://example.com/favicon.ico",
+				OpenGraph:   map[string]string{"image": "https://example.com/cover.png"},
+				FetchedAt:   "2026-10-19T12:00:00Z",
+			},
+		}
+
+		mockService.On("GetShortURLStats", mock.Anything, shortened).Return(stats, nil)
+
+		req := httptest.NewRequest("GET", "/api/stats/"+shortened, nil)
+		req = mux.SetURLVars(req, map[string]string{"shortened": shortened})
+
+		rr := httptest.NewRecorder()
+
+		urlHandler.StatsHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.ShortUrlStatsResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.LinkMetadataResponse{
+			Title:       "Example Domain",
+			Description: "For use in examples",
+			FaviconURL:  "https://example.com/favicon.ico",
+			OpenGraph:   map[string]string{"image": "https://example.com/cover.png"},
+			FetchedAt:   "2026-10-19T12:00:00Z",
+		}, response.Metadata)
+	})
+
 	t.Run("shortened URL empty", func(t *testing.T) {
 		mockService := new(MockURLService)
 		urlHandler := handler.NewURLHandler(mockService)
@@ -184,6 +343,128 @@ func TestStatsHandler(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("includes click analytics", func(t *testing.T) {
+		mockService := new(MockURLService)
+		urlHandler := handler.NewURLHandler(mockService)
+
+		shortened := "123xyz"
+		stats := &domain.Url{
+			ShortUrl:   shortened,
+			ClickCount: 10,
+			Analytics: &domain.ClickAnalytics{
+				BotClicks: 3,
+				Referrers: []domain.ValueCount{{Value: "direct", Count: 6}, {Value: "news.example.org", Count: 4}},
+				Browsers:  []domain.ValueCount{{Value: domain.BrowserFirefox, Count: 10}},
+				Hourly:    []domain.HourlyCount{{Hour: "2026-10-19T14:00:00Z", Count: 2}},
+				Daily:     []domain.DailyCount{{Date: "2026-10-19", Count: 10}},
+			},
+		}
+
+		mockService.On("GetShortURLStats", mock.Anything, shortened).Return(stats, nil)
+
+		req := httptest.NewRequest("GET", "/api/stats/"+shortened, nil)
+		req = mux.SetURLVars(req, map[string]string{"shortened": shortened})
+
+		rr := httptest.NewRecorder()
+
+		urlHandler.StatsHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.ShortUrlStatsResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		require.NotNil(t, response.BotClickCount)
+		assert.EqualValues(t, 3, *response.BotClickCount)
+		require.NotNil(t, response.Analytics)
+		assert.Equal(t, []model.ValueCountResponse{{Value: "direct", Count: 6}, {Value: "news.example.org", Count: 4}},
+			response.Analytics.Referrers)
+		assert.Equal(t, []model.ValueCountResponse{{Value: domain.BrowserFirefox, Count: 10}}, response.Analytics.Browsers)
+		assert.Empty(t, response.Analytics.Devices)
+		assert.Equal(t, []model.HourlyCountResponse{{Hour: "2026-10-19T14:00:00Z", Count: 2}}, response.Analytics.Hourly)
+		assert.Equal(t, []model.DailyCountResponse{{Date: "2026-10-19", Count: 10}}, response.Analytics.Daily)
+	})
+
+	t.Run("includes unique visitors", func(t *testing.T) {
+		mockService := new(MockURLService)
+		urlHandler := handler.NewURLHandler(mockService)
+
+		shortened := "123xyz"
+		stats := &domain.Url{
+			ShortUrl:   shortened,
+			ClickCount: 10,
+			UniqueVisitors: &domain.UniqueVisitors{
+				Total:      6,
+				Last7Days:  4,
+				Last30Days: 6,
+				Daily:      []domain.DailyCount{{Date: "2026-10-18", Count: 1}, {Date: "2026-10-19", Count: 3}},
+			},
+		}
+
+		mockService.On("GetShortURLStats", mock.Anything, shortened).Return(stats, nil)
+
+		req := httptest.NewRequest("GET", "/api/stats/"+shortened, nil)
+		req = mux.SetURLVars(req, map[string]string{"shortened": shortened})
+
+		rr := httptest.NewRecorder()
+
+		urlHandler.StatsHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.ShortUrlStatsResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.UniqueVisitorsResponse{
+			Total:      6,
+			Last7Days:  4,
+			Last30Days: 6,
+			Daily:      []model.DailyCountResponse{{Date: "2026-10-18", Count: 1}, {Date: "2026-10-19", Count: 3}},
+		}, response.UniqueVisitors)
+	})
+
+	t.Run("includes link health", func(t *testing.T) {
+		mockService := new(MockURLService)
+		urlHandler := handler.NewURLHandler(mockService)
+
+		shortened := "123xyz"
+		stats := &domain.Url{
+			ShortUrl:    shortened,
+			OriginalUrl: "https://example.com/gone",
+			Health: &domain.LinkHealth{
+				ShortUrl:    shortened,
+				OriginalUrl: "https://example.com/gone",
+				StatusCode:  http.StatusNotFound,
+				Error:       "Not Found",
+				Failures:    3,
+				Broken:      true,
+				CheckedAt:   "2026-10-19T12:00:00Z",
+			},
+		}
+
+		mockService.On("GetShortURLStats", mock.Anything, shortened).Return(stats, nil)
+
+		req := httptest.NewRequest("GET", "/api/stats/"+shortened, nil)
+		req = mux.SetURLVars(req, map[string]string{"shortened": shortened})
+
+		rr := httptest.NewRecorder()
+
+		urlHandler.StatsHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.ShortUrlStatsResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.LinkHealthResponse{
+			StatusCode: http.StatusNotFound,
+			Error:      "Not Found",
+			Failures:   3,
+			Broken:     true,
+			CheckedAt:  "2026-10-19T12:00:00Z",
+		}, response.Health)
+	})
+
 	t.Run("shortened URL empty", func(t *testing.T) {
 		mockService := new(MockURLService)
 		urlHandler := handler.NewURLHandler(mockService)
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: s,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/webhooks", h.CreateWebhookHandler).Methods("POST")
+	router.HandleFunc("/api/webhooks", h.ListWebhooksHandler).Methods("GET")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}", h.GetWebhookHandler).Methods("GET")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}", h.DeleteWebhookHandler).Methods("DELETE")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}/deliveries", h.ListDeliveriesHandler).Methods("GET")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}/deliveries/{delivery:[0-9]+}/retry", h.RetryDeliveryHandler).Methods("POST")
+}
+
+func (h *WebhookHandler) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.CreateWebhookHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var request model.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := h.service.CreateWebhook(ctx, owner, request.URL, request.EventTypes)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+
+	response := toWebhookResponse(hook)
+	response.Secret = hook.Secret
+	writeJSON(w, r, http.StatusCreated, response)
+}
+
+func (h *WebhookHandler) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.ListWebhooksHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+
+	hooks, err := h.service.ListWebhooks(ctx, owner)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+
+	response := make([]model.WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		response = append(response, toWebhookResponse(hook))
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *WebhookHandler) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.GetWebhookHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+	hook, err := h.service.GetWebhook(ctx, owner, id)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toWebhookResponse(hook))
+}
+
+func (h *WebhookHandler) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.DeleteWebhookHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+	if err := h.service.DeleteWebhook(ctx, owner, id); err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.ListDeliveriesHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := h.service.ListDeliveries(ctx, owner, id, query.Get("status"), limit)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+
+	response := make([]model.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		item := model.WebhookDeliveryResponse{
+			ID:             delivery.ID,
+			EventID:        delivery.EventID,
+			EventType:      delivery.EventType,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt,
+			UpdatedAt:      delivery.UpdatedAt,
+		}
+		if delivery.Status == domain.DeliveryPending {
+			item.NextAttemptAt = delivery.NextAttemptAt
+		}
+		response = append(response, item)
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *WebhookHandler) RetryDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebhookHandler.RetryDeliveryHandler")
+	defer span.End()
+
+	owner, ok := requireOwner(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	deliveryID, _ := strconv.ParseInt(vars["delivery"], 10, 64)
+
+	if err := h.service.RetryDelivery(ctx, owner, id, deliveryID); err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// requireOwner rejects anonymous requests, since webhooks and the settings
+// of links belong to the API key that registered them.
+func requireOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner := middleware.GetOwnerFromContext(r.Context())
+	if owner == "" {
+		http.Error(w, "An API key is required", http.StatusUnauthorized)
+		return "", false
+	}
+	return owner, true
+}
+
+func writeWebhookError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookURL),
+		errors.Is(err, service.ErrWebhookURLNotPublic),
+		errors.Is(err, service.ErrInvalidEventType),
+		errors.Is(err, service.ErrInvalidDeliveryStatus),
+		errors.Is(err, service.ErrInvalidPagination):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrDeliveryNotRetryable):
+		status = http.StatusConflict
+	default:
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to handle webhook request", "error", err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func toWebhookResponse(hook *domain.Webhook) model.WebhookResponse {
+	return model.WebhookResponse{
+		ID:         hook.ID,
+		URL:        hook.URL,
+		EventTypes: nonNil(hook.EventTypes),
+		CreatedAt:  hook.CreatedAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		middleware.GetLoggerFromContext(r.Context()).Error("Failed to encode response", "error", err)
+	}
+}
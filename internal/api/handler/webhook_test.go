@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/api/handler"
+	"github.com/unwale/url-shortener/internal/api/middleware"
+	"github.com/unwale/url-shortener/internal/api/model"
+	domain "github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/service"
+)
+
+const testAPIKey = "test-key"
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, owner, url string, eventTypes []string) (*domain.Webhook, error) {
+	args := m.Called(ctx, owner, url, eventTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context, owner string) ([]*domain.Webhook, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, owner string, id int64) (*domain.Webhook, error) {
+	args := m.Called(ctx, owner, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, owner string, id int64) error {
+	args := m.Called(ctx, owner, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, owner string, webhookID int64, status string, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, owner, webhookID, status, limit)
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RetryDelivery(ctx context.Context, owner string, webhookID, deliveryID int64) error {
+	args := m.Called(ctx, owner, webhookID, deliveryID)
+	return args.Error(0)
+}
+
+func newWebhookRouter(s service.WebhookService) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.NewAPIKeyMiddleware([]string{testAPIKey}))
+	handler.NewWebhookHandler(s).RegisterRoutes(router)
+	return router
+}
+
+func newWebhookRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(middleware.APIKeyHeader, testAPIKey)
+	return req
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	owner := middleware.OwnerID(testAPIKey)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		mockService.On("CreateWebhook", mock.Anything, owner, "https://example.com/hook", []string{"link.created"}).
+			Return(&domain.Webhook{ID: 7, URL: "https://example.com/hook", Secret: "whsec_abc",
+				EventTypes: []string{"link.created"}, CreatedAt: "2025-01-02T03:04:05Z"}, nil)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("POST", "/api/webhooks",
+			`{"url":"https://example.com/hook","event_types":["link.created"]}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var response model.WebhookResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, model.WebhookResponse{ID: 7, URL: "https://example.com/hook", EventTypes: []string{"link.created"},
+			Secret: "whsec_abc", CreatedAt: "2025-01-02T03:04:05Z"}, response)
+	})
+
+	t.Run("without api key", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(mockService).ServeHTTP(rr, httptest.NewRequest("POST", "/api/webhooks", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockService.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		mockService.On("CreateWebhook", mock.Anything, owner, "nope", []string(nil)).Return(nil, service.ErrInvalidWebhookURL)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("POST", "/api/webhooks", `{"url":"nope"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestListWebhooksHandler_HidesSecrets(t *testing.T) {
+	mockService := new(MockWebhookService)
+	mockService.On("ListWebhooks", mock.Anything, middleware.OwnerID(testAPIKey)).
+		Return([]*domain.Webhook{{ID: 7, URL: "https://example.com/hook", Secret: "whsec_abc"}}, nil)
+
+	rr := httptest.NewRecorder()
+	newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/webhooks", ""))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "whsec_abc")
+	assert.Contains(t, rr.Body.String(), `"event_types":[]`)
+}
+
+func TestGetWebhookHandler_NotFound(t *testing.T) {
+	mockService := new(MockWebhookService)
+	mockService.On("GetWebhook", mock.Anything, middleware.OwnerID(testAPIKey), int64(7)).Return(nil, repository.ErrWebhookNotFound)
+
+	rr := httptest.NewRecorder()
+	newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/webhooks/7", ""))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestListDeliveriesHandler(t *testing.T) {
+	mockService := new(MockWebhookService)
+	mockService.On("ListDeliveries", mock.Anything, middleware.OwnerID(testAPIKey), int64(7), "dead", 20).
+		Return([]*domain.WebhookDelivery{{
+			ID: 3, EventID: 42, EventType: "link.created", Status: "dead", Attempts: 8,
+			ResponseStatus: 503, LastError: "webhook responded with status 503", NextAttemptAt: "2025-01-02T03:04:05Z",
+			CreatedAt: "2025-01-02T03:04:05Z", UpdatedAt: "2025-01-02T04:04:05Z",
+		}}, nil)
+
+	rr := httptest.NewRecorder()
+	newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("GET", "/api/webhooks/7/deliveries?status=dead&limit=20", ""))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []model.WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	assert.Equal(t, model.WebhookDeliveryResponse{
+		ID: 3, EventID: 42, EventType: "link.created", Status: "dead", Attempts: 8,
+		ResponseStatus: 503, LastError: "webhook responded with status 503",
+		CreatedAt: "2025-01-02T03:04:05Z", UpdatedAt: "2025-01-02T04:04:05Z",
+	}, response[0])
+}
+
+func TestRetryDeliveryHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		mockService.On("RetryDelivery", mock.Anything, middleware.OwnerID(testAPIKey), int64(7), int64(3)).Return(nil)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("POST", "/api/webhooks/7/deliveries/3/retry", ""))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+	})
+
+	t.Run("not dead", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		mockService.On("RetryDelivery", mock.Anything, middleware.OwnerID(testAPIKey), int64(7), int64(3)).
+			Return(repository.ErrDeliveryNotRetryable)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(mockService).ServeHTTP(rr, newWebhookRequest("POST", "/api/webhooks/7/deliveries/3/retry", ""))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
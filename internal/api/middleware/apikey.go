@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	ownerKey contextKey = "owner"
+
+	APIKeyHeader = "X-API-Key"
+)
+
+// OwnerID derives the stable identifier that resources created with key are
+// attributed to, so that keys themselves are never stored.
+func OwnerID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// NewAPIKeyMiddleware identifies clients by the X-API-Key header. Requests
+// without a key pass through anonymously; requests with a key that is not in
+// keys are rejected with a 401 problem response.
+func NewAPIKeyMiddleware(keys []string) func(http.Handler) http.Handler {
+	owners := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			owners[OwnerID(key)] = struct{}{}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			owner := OwnerID(key)
+			if _, ok := owners[owner]; !ok {
+				writeProblem(w, r, http.StatusUnauthorized, "The API key is not valid")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ownerKey, owner)))
+		})
+	}
+}
+
+// NewAdminKeyMiddleware only lets requests through whose X-API-Key is one of
+// keys, and rejects all others with a 401 problem response. With no keys,
+// every request is rejected.
+func NewAdminKeyMiddleware(keys []string) func(http.Handler) http.Handler {
+	admins := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			admins[OwnerID(key)] = struct{}{}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if _, ok := admins[OwnerID(key)]; key == "" || !ok {
+				writeProblem(w, r, http.StatusUnauthorized, "An admin API key is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetOwnerFromContext returns the owner identified by the request's API key,
+// or "" for anonymous requests.
+func GetOwnerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey).(string)
+	return owner
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	var owner string
+	handler := NewAPIKeyMiddleware([]string{"secret-key"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner = GetOwnerFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		key      string
+		status   int
+		expected string
+	}{
+		{"anonymous", "", http.StatusOK, ""},
+		{"known key", "secret-key", http.StatusOK, OwnerID("secret-key")},
+		{"unknown key", "guess", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner = ""
+			req := httptest.NewRequest("GET", "/api/webhooks", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.expected, owner)
+		})
+	}
+
+	assert.NotContains(t, OwnerID("secret-key"), "secret")
+	assert.Len(t, OwnerID("secret-key"), 16)
+}
+
+func TestAdminKeyMiddleware(t *testing.T) {
+	handler := NewAdminKeyMiddleware([]string{"admin-key"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"admin key", "admin-key", http.StatusOK},
+		{"other key", "secret-key", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/admin/cache/warm", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	NewAdminKeyMiddleware(nil)(http.NotFoundHandler()).ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/cache/warm", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
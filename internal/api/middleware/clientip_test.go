@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.5:1234", "198.51.100.1", "198.51.100.2", "203.0.113.5"},
+		{"trusted peer uses forwarded for", "10.0.0.1:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed entries are skipped", "10.0.0.1:1234", "1.1.1.1, 198.51.100.1, 10.0.0.2", "", "198.51.100.1"},
+		{"trusted peer falls back to real ip", "192.0.2.1:1234", "", "198.51.100.2", "198.51.100.2"},
+		{"trusted peer without headers", "10.0.0.1:1234", "", "", "10.0.0.1"},
+		{"invalid forwarded entry stops walk", "10.0.0.1:1234", "garbage, 10.0.0.3", "", "10.0.0.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.expected, ClientIP(req, trusted))
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
@@ -3,40 +3,134 @@ package middleware
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/unwale/url-shortener/internal/privacy"
 )
 
 type contextKey string
 
 const (
-	loggerKey contextKey = "logger"
+	loggerKey    contextKey = "logger"
+	requestIDKey contextKey = "request_id"
+	clientIPKey  contextKey = "client_ip"
+	anonIPKey    contextKey = "anonymized_ip"
+
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
 )
 
+type LoggingOptions struct {
+	// SkipPaths lists request paths, such as health probes, that are served
+	// without emitting access log lines.
+	SkipPaths []string
+	// TrustedProxies are the networks whose X-Forwarded-For and X-Real-IP
+	// headers are believed when determining the client address.
+	TrustedProxies []netip.Prefix
+	// SampledRoutes are route templates whose successful requests are only
+	// logged with probability SampleRate. Failed requests are always logged.
+	SampledRoutes []string
+	SampleRate    float64
+	// IPAnonymizer anonymizes client addresses in log lines and in
+	// GetAnonymizedIPFromContext.
+	IPAnonymizer *privacy.IPAnonymizer
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		reqId := uuid.New().String()
+	return NewLoggingMiddleware(LoggingOptions{})(next)
+}
 
-		logger := slog.With(
-			"request_id", reqId,
-			"method", r.Method,
-			"url", r.URL.String(),
-		)
-		ctx := context.WithValue(r.Context(), loggerKey, logger)
+func NewLoggingMiddleware(opts LoggingOptions) func(http.Handler) http.Handler {
+	skip := make(map[string]struct{}, len(opts.SkipPaths))
+	for _, path := range opts.SkipPaths {
+		skip[path] = struct{}{}
+	}
+	sampled := make(map[string]struct{}, len(opts.SampledRoutes))
+	for _, route := range opts.SampledRoutes {
+		sampled[route] = struct{}{}
+	}
 
-		logger.Info("Received request")
-		next.ServeHTTP(w, r.WithContext(ctx))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
 
-		duration := time.Since(start)
-		slog.Info("Processed request",
-			"method", r.Method,
-			"url", r.URL.String(),
-			"duration", duration,
-		)
-	})
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			clientIP := ClientIP(r, opts.TrustedProxies)
+			anonymizedIP := opts.IPAnonymizer.Anonymize(r.Context(), clientIP)
+			route := routeTemplate(r)
+
+			logger := slog.With(
+				"request_id", requestID,
+				"method", r.Method,
+				"url", r.URL.String(),
+				"route", route,
+				"client_ip", anonymizedIP,
+			)
+			if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+				logger = logger.With("trace_id", spanContext.TraceID().String())
+			}
+
+			ctx := context.WithValue(r.Context(), loggerKey, logger)
+			ctx = context.WithValue(ctx, requestIDKey, requestID)
+			ctx = context.WithValue(ctx, clientIPKey, clientIP)
+			ctx = context.WithValue(ctx, anonIPKey, anonymizedIP)
+
+			recorder := newResponseRecorder(w)
+			logger.Debug("Received request")
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			if _, ok := skip[r.URL.Path]; ok {
+				return
+			}
+			if _, ok := sampled[route]; ok && recorder.status < http.StatusBadRequest && rand.Float64() >= opts.SampleRate {
+				return
+			}
+
+			logger.LogAttrs(ctx, levelForStatus(recorder.status), "Processed request",
+				slog.Int("status", recorder.status),
+				slog.Int64("bytes", recorder.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("referer", r.Referer()),
+			)
+		})
+	}
+}
+
+func levelForStatus(status int) slog.Level {
+	if status >= http.StatusInternalServerError {
+		return slog.LevelError
+	}
+	if status >= http.StatusBadRequest {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// validRequestID accepts upstream request IDs that are short and printable so
+// that they can be echoed and logged safely.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
 }
 
 func GetLoggerFromContext(ctx context.Context) *slog.Logger {
@@ -46,3 +140,22 @@ func GetLoggerFromContext(ctx context.Context) *slog.Logger {
 	}
 	return logger
 }
+
+func GetRequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// GetClientIPFromContext returns the address of the client. It must not be
+// recorded; record GetAnonymizedIPFromContext instead.
+func GetClientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPKey).(string)
+	return clientIP
+}
+
+// GetAnonymizedIPFromContext returns the address of the client as anonymized
+// by LoggingOptions.IPAnonymizer.
+func GetAnonymizedIPFromContext(ctx context.Context) string {
+	anonymizedIP, _ := ctx.Value(anonIPKey).(string)
+	return anonymizedIP
+}
@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/privacy"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -38,3 +44,130 @@ func TestGetLoggerFromContext(t *testing.T) {
 	logger = GetLoggerFromContext(ctx)
 	assert.Equal(t, slog.Default(), logger)
 }
+
+func TestNewLoggingMiddleware_SkipPaths(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	mockHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewLoggingMiddleware(LoggingOptions{SkipPaths: []string{"/healthz"}})(mockHandler)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, buf.String())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/abc123", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, buf.String(), "Processed request")
+}
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestNewLoggingMiddleware_RequestID(t *testing.T) {
+	captureLogs(t)
+
+	var fromContext string
+	handler := LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = GetRequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "upstream-id-42")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "upstream-id-42", rr.Header().Get(RequestIDHeader))
+	assert.Equal(t, "upstream-id-42", fromContext)
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.NotEqual(t, "bad id\n", rr.Header().Get(RequestIDHeader))
+	assert.NotEmpty(t, rr.Header().Get(RequestIDHeader))
+	assert.Equal(t, rr.Header().Get(RequestIDHeader), fromContext)
+}
+
+func TestNewLoggingMiddleware_AccessLogFields(t *testing.T) {
+	buf := captureLogs(t)
+
+	router := mux.NewRouter()
+	router.Use(NewLoggingMiddleware(LoggingOptions{}))
+	router.HandleFunc("/api/stats/{shortened}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})
+
+	req := httptest.NewRequest("GET", "/api/stats/abc123", nil)
+	req.RemoteAddr = "192.0.2.10:5555"
+	req.Header.Set("User-Agent", "test-agent")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "Processed request", entry["msg"])
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "/api/stats/{shortened}", entry["route"])
+	assert.Equal(t, "192.0.2.10", entry["client_ip"])
+	assert.Equal(t, "test-agent", entry["user_agent"])
+	assert.EqualValues(t, http.StatusNotFound, entry["status"])
+	assert.EqualValues(t, len("not found"), entry["bytes"])
+	assert.NotEmpty(t, entry["request_id"])
+}
+
+func TestNewLoggingMiddleware_Sampling(t *testing.T) {
+	buf := captureLogs(t)
+
+	status := http.StatusFound
+	router := mux.NewRouter()
+	router.Use(NewLoggingMiddleware(LoggingOptions{
+		SampledRoutes: []string{"/{shortened}"},
+		SampleRate:    0,
+	}))
+	router.HandleFunc("/{shortened}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abc123", nil))
+	assert.Empty(t, buf.String())
+
+	status = http.StatusNotFound
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abc123", nil))
+	assert.Contains(t, buf.String(), "Processed request")
+}
+
+func TestNewLoggingMiddleware_AnonymizesClientIP(t *testing.T) {
+	buf := captureLogs(t)
+
+	anonymizer, err := privacy.NewIPAnonymizer(privacy.IPModeTruncate, 0, privacy.NewLocalSalts(), slog.Default())
+	require.NoError(t, err)
+	var clientIP, anonymizedIP string
+	handler := NewLoggingMiddleware(LoggingOptions{IPAnonymizer: anonymizer})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = GetClientIPFromContext(r.Context())
+		anonymizedIP = GetAnonymizedIPFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/abc123", nil)
+	req.RemoteAddr = "192.0.2.10:5555"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "192.0.2.0", entry["client_ip"])
+	assert.NotContains(t, buf.String(), "192.0.2.10")
+	assert.Equal(t, "192.0.2.10", clientIP)
+	assert.Equal(t, "192.0.2.0", anonymizedIP)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+const unmatchedRoute = "unmatched"
+
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		route := routeTemplate(r)
+		status := strconv.Itoa(recorder.status)
+
+		metrics.HTTPRequestsTotal.WithLabelValues(route, r.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(MetricsMiddleware)
+	router.HandleFunc("/api/stats/{shortened}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues("/api/stats/{shortened}", "GET", "404")
+	before := testutil.ToFloat64(counter)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/stats/abc123", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestResponseRecorder_DefaultsToOK(t *testing.T) {
+	rr := httptest.NewRecorder()
+	recorder := newResponseRecorder(rr)
+
+	_, err := recorder.Write([]byte("hello"))
+	assert.NoError(t, err)
+	recorder.WriteHeader(http.StatusTeapot)
+
+	assert.Equal(t, http.StatusOK, recorder.status)
+}
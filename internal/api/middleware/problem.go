@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/unwale/url-shortener/internal/api/model"
+)
+
+const ProblemContentType = "application/problem+json"
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(model.ProblemResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: GetRequestIDFromContext(r.Context()),
+	})
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// handlerPanic carries a panic raised on another goroutine together with the
+// stack trace captured where it happened.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// RecoveryMiddleware turns a panicking handler into a 500 problem response and
+// logs the panic with its stack trace through the request logger.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := newResponseRecorder(w)
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			stack := debug.Stack()
+			if hp, ok := value.(handlerPanic); ok {
+				value, stack = hp.value, hp.stack
+			}
+			if err, ok := value.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(value)
+			}
+
+			metrics.PanicsRecoveredTotal.Inc()
+			GetLoggerFromContext(r.Context()).Error("Recovered from panic",
+				"panic", fmt.Sprint(value),
+				"stack", string(stack),
+			)
+			if !recorder.wroteHeader {
+				writeProblem(recorder, r, http.StatusInternalServerError, "The server encountered an unexpected error")
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// NewTimeoutMiddleware bounds the time spent serving the routes listed in
+// timeouts, keyed by route template. The request context is cancelled when the
+// deadline passes and, if the handler has not started its response by then,
+// the client receives a 503 problem response. Routes that are not listed, such
+// as streaming exports, are served without a deadline.
+func NewTimeoutMiddleware(timeouts map[string]time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := timeouts[routeTemplate(r)]
+			if !ok || timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{ctx: ctx, w: w, header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan handlerPanic, 1)
+			go func() {
+				defer func() {
+					if value := recover(); value != nil {
+						panicked <- handlerPanic{value: value, stack: debug.Stack()}
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case hp := <-panicked:
+				panic(hp)
+			case <-ctx.Done():
+			}
+
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if ctx.Err() == nil {
+				return
+			}
+			tw.timedOut = true
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				GetLoggerFromContext(ctx).Warn("Request timed out", "timeout", timeout)
+			}
+			if !tw.wroteHeader {
+				writeProblem(w, r, http.StatusServiceUnavailable, "The request took too long to complete")
+			}
+		})
+	}
+}
+
+// timeoutWriter forwards writes to the underlying ResponseWriter until the
+// request context is done, after which writes fail with
+// http.ErrHandlerTimeout. The handler gets its own header map so that a late
+// handler cannot race with the timeout response.
+type timeoutWriter struct {
+	ctx    context.Context
+	w      http.ResponseWriter
+	header http.Header
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.ctx.Err() != nil {
+		return
+	}
+	tw.writeHeaderLocked(status)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.ctx.Err() != nil {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.ctx.Err() != nil {
+		return
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	if flusher, ok := tw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+	tw.w.WriteHeader(status)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/api/model"
+)
+
+func newTimeoutRouter(handler http.HandlerFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(RecoveryMiddleware)
+	router.Use(NewTimeoutMiddleware(map[string]time.Duration{
+		"/slow": 20 * time.Millisecond,
+	}))
+	router.HandleFunc("/slow", handler)
+	router.HandleFunc("/untimed", handler)
+	return router
+}
+
+func TestTimeoutMiddleware_TimesOut(t *testing.T) {
+	router := newTimeoutRouter(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+	var problem model.ProblemResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusServiceUnavailable, problem.Status)
+}
+
+func TestTimeoutMiddleware_CompletesInTime(t *testing.T) {
+	router := newTimeoutRouter(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		w.Header().Set("X-Has-Deadline", map[bool]string{true: "yes", false: "no"}[hasDeadline])
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "yes", rr.Header().Get("X-Has-Deadline"))
+	assert.Equal(t, "ok", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/untimed", nil))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "no", rr.Header().Get("X-Has-Deadline"))
+}
+
+func TestTimeoutMiddleware_PropagatesPanic(t *testing.T) {
+	router := newTimeoutRouter(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+}
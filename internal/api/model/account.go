@@ -0,0 +1,6 @@
+package model
+
+type OwnerDeletionResponse struct {
+	DeletedLinks    int   `json:"deleted_links"`
+	DeletedWebhooks int64 `json:"deleted_webhooks"`
+}
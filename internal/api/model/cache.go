@@ -0,0 +1,6 @@
+package model
+
+type CacheWarmResponse struct {
+	Loaded int `json:"loaded"`
+	Warmed int `json:"warmed"`
+}
@@ -0,0 +1,26 @@
+package model
+
+type DashboardResponse struct {
+	Window             string                `json:"window"`
+	TotalLinks         int64                 `json:"total_links"`
+	TotalClicks        int64                 `json:"total_clicks"`
+	ZeroClickLinkCount int64                 `json:"zero_click_link_count"`
+	TopLinks           []TopLinkResponse     `json:"top_links"`
+	NewestLinks        []LinkSummaryResponse `json:"newest_links"`
+	ZeroClickLinks     []LinkSummaryResponse `json:"zero_click_links"`
+}
+
+type TopLinkResponse struct {
+	ShortURL     string `json:"short_url"`
+	OriginalURL  string `json:"original_url"`
+	WindowClicks int64  `json:"window_clicks"`
+	ClickCount   int64  `json:"click_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type LinkSummaryResponse struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	ClickCount  int64  `json:"click_count"`
+	CreatedAt   string `json:"created_at"`
+}
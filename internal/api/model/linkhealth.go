@@ -0,0 +1,15 @@
+package model
+
+type LinkHealthResponse struct {
+	ShortURL    string `json:"short_url,omitempty"`
+	OriginalURL string `json:"original_url,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Failures    int    `json:"failures"`
+	Broken      bool   `json:"broken"`
+	CheckedAt   string `json:"checked_at"`
+}
+
+type BrokenLinksResponse struct {
+	Links []LinkHealthResponse `json:"links"`
+}
@@ -0,0 +1,10 @@
+package model
+
+type LinkMetadataResponse struct {
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	FaviconURL  string            `json:"favicon_url,omitempty"`
+	OpenGraph   map[string]string `json:"open_graph,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	FetchedAt   string            `json:"fetched_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+type LinkPreviewRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+}
+
+type LinkPreviewResponse struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	UpdatedAt   string `json:"updated_at"`
+}
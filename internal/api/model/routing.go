@@ -0,0 +1,38 @@
+package model
+
+type RoutingRuleRequest struct {
+	Priority         int      `json:"priority"`
+	Devices          []string `json:"devices,omitempty"`
+	OperatingSystems []string `json:"operating_systems,omitempty"`
+	Languages        []string `json:"languages,omitempty"`
+	Countries        []string `json:"countries,omitempty"`
+	Destination      string   `json:"destination"`
+}
+
+type RoutingRuleResponse struct {
+	ID               int64    `json:"id"`
+	Priority         int      `json:"priority"`
+	Devices          []string `json:"devices"`
+	OperatingSystems []string `json:"operating_systems"`
+	Languages        []string `json:"languages"`
+	Countries        []string `json:"countries"`
+	Destination      string   `json:"destination"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+type VariantRequest struct {
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+type SplitRequest struct {
+	Variants []VariantRequest `json:"variants"`
+}
+
+type VariantResponse struct {
+	ID          int64  `json:"id"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+	ClickCount  int64  `json:"click_count"`
+	CreatedAt   string `json:"created_at"`
+}
@@ -18,9 +18,63 @@ type ShortUrlStataRequest struct {
 }
 
 type ShortUrlStatsResponse struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
-	ClickCount  int    `json:"click_count"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ShortURL       string                  `json:"short_url"`
+	OriginalURL    string                  `json:"original_url"`
+	ClickCount     int                     `json:"click_count"`
+	BotClickCount  *int64                  `json:"bot_click_count,omitempty"`
+	CreatedAt      string                  `json:"created_at"`
+	UpdatedAt      string                  `json:"updated_at"`
+	Variants       []VariantResponse       `json:"variants,omitempty"`
+	Analytics      *ClickAnalyticsResponse `json:"analytics,omitempty"`
+	UniqueVisitors *UniqueVisitorsResponse `json:"unique_visitors,omitempty"`
+	Health         *LinkHealthResponse     `json:"health,omitempty"`
+	Metadata       *LinkMetadataResponse   `json:"metadata,omitempty"`
+}
+
+type UniqueVisitorsResponse struct {
+	Total      int64                `json:"total"`
+	Last7Days  int64                `json:"last_7_days"`
+	Last30Days int64                `json:"last_30_days"`
+	Daily      []DailyCountResponse `json:"daily"`
+}
+
+type DailyCountResponse struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type ClickAnalyticsResponse struct {
+	Referrers        []ValueCountResponse  `json:"referrers"`
+	Browsers         []ValueCountResponse  `json:"browsers"`
+	OperatingSystems []ValueCountResponse  `json:"operating_systems"`
+	Devices          []ValueCountResponse  `json:"devices"`
+	Hourly           []HourlyCountResponse `json:"hourly"`
+	Daily            []DailyCountResponse  `json:"daily"`
+}
+
+type HourlyCountResponse struct {
+	Hour  string `json:"hour"`
+	Count int64  `json:"count"`
+}
+
+type ValueCountResponse struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type ImportErrorResponse struct {
+	Record   int    `json:"record"`
+	ShortURL string `json:"short_url,omitempty"`
+	Message  string `json:"message"`
+}
+
+type ImportReportResponse struct {
+	DryRun      bool                  `json:"dry_run"`
+	Aborted     bool                  `json:"aborted"`
+	Total       int                   `json:"total"`
+	Created     int                   `json:"created"`
+	Overwritten int                   `json:"overwritten"`
+	Skipped     int                   `json:"skipped"`
+	Failed      int                   `json:"failed"`
+	Errors      []ImportErrorResponse `json:"errors"`
 }
@@ -1,12 +1,80 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v10"
 )
 
 type Config struct {
-	PostgresURL string `env:"POSTGRES_URL,required"`
-	RedisURL    string `env:"REDIS_URL,required"`
+	StorageBackend string `env:"STORAGE_BACKEND" envDefault:"postgres"`
+	PostgresURL    string `env:"POSTGRES_URL"`
+	SQLitePath     string `env:"SQLITE_PATH" envDefault:"url-shortener.db"`
+	RedisURL       string `env:"REDIS_URL"`
+
+	PostgresReplicaURLs  []string      `env:"POSTGRES_REPLICA_URLS" envSeparator:","`
+	ReplicaMaxLag        time.Duration `env:"REPLICA_MAX_LAG" envDefault:"5s"`
+	ReplicaCheckInterval time.Duration `env:"REPLICA_CHECK_INTERVAL" envDefault:"5s"`
+
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	ShutdownDelay      time.Duration `env:"SHUTDOWN_DELAY" envDefault:"0s"`
+	RequestTimeout     time.Duration `env:"REQUEST_TIMEOUT" envDefault:"5s"`
+
+	LocalCacheSize int           `env:"LOCAL_CACHE_SIZE" envDefault:"10000"`
+	LocalCacheTTL  time.Duration `env:"LOCAL_CACHE_TTL" envDefault:"1m"`
+	CacheWarmCount int           `env:"CACHE_WARM_COUNT" envDefault:"0"`
+
+	CacheTimeout         time.Duration `env:"CACHE_TIMEOUT" envDefault:"100ms"`
+	CacheBreakerFailures int           `env:"CACHE_BREAKER_FAILURES" envDefault:"5"`
+	CacheBreakerCooldown time.Duration `env:"CACHE_BREAKER_COOLDOWN" envDefault:"10s"`
+
+	OutboxSinks        []string      `env:"OUTBOX_SINKS" envSeparator:","`
+	OutboxWebhookURL   string        `env:"OUTBOX_WEBHOOK_URL"`
+	OutboxRedisStream  string        `env:"OUTBOX_REDIS_STREAM" envDefault:"url-shortener:events"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	OutboxMaxAttempts  int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	OutboxRetention    time.Duration `env:"OUTBOX_RETENTION" envDefault:"168h"`
+
+	APIKeys             []string      `env:"API_KEYS" envSeparator:","`
+	AdminAPIKeys        []string      `env:"ADMIN_API_KEYS" envSeparator:","`
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"1s"`
+	WebhookBatchSize    int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"50"`
+	WebhookMaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+
+	GeoIPDatabase    string        `env:"GEOIP_DATABASE"`
+	RoutingCacheSize int           `env:"ROUTING_CACHE_SIZE" envDefault:"10000"`
+	RoutingCacheTTL  time.Duration `env:"ROUTING_CACHE_TTL" envDefault:"30s"`
+
+	VisitorHashSecret  string        `env:"VISITOR_HASH_SECRET"`
+	IPAnonymization    string        `env:"IP_ANONYMIZATION" envDefault:"none"`
+	IPHashSaltRotation time.Duration `env:"IP_HASH_SALT_ROTATION" envDefault:"24h"`
+
+	ClickRollupInterval  time.Duration `env:"CLICK_ROLLUP_INTERVAL" envDefault:"1m"`
+	ClickEventRetention  time.Duration `env:"CLICK_EVENT_RETENTION" envDefault:"720h"`
+	ClickHourlyRetention time.Duration `env:"CLICK_HOURLY_RETENTION" envDefault:"2160h"`
+
+	LinkCheckInterval     time.Duration `env:"LINK_CHECK_INTERVAL" envDefault:"0s"`
+	LinkCheckMaxAge       time.Duration `env:"LINK_CHECK_MAX_AGE" envDefault:"24h"`
+	LinkCheckBatchSize    int           `env:"LINK_CHECK_BATCH_SIZE" envDefault:"200"`
+	LinkCheckConcurrency  int           `env:"LINK_CHECK_CONCURRENCY" envDefault:"8"`
+	LinkCheckHostInterval time.Duration `env:"LINK_CHECK_HOST_INTERVAL" envDefault:"1s"`
+	LinkCheckTimeout      time.Duration `env:"LINK_CHECK_TIMEOUT" envDefault:"10s"`
+	LinkFallbackURL       string        `env:"LINK_FALLBACK_URL"`
+
+	MetadataFetchWorkers int           `env:"METADATA_FETCH_WORKERS" envDefault:"2"`
+	MetadataFetchTimeout time.Duration `env:"METADATA_FETCH_TIMEOUT" envDefault:"5s"`
+	MetadataMaxBytes     int64         `env:"METADATA_MAX_BYTES" envDefault:"524288"`
+
+	TracingExporter    string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingEndpoint    string  `env:"TRACING_OTLP_ENDPOINT"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+
+	LogFormat             string   `env:"LOG_FORMAT" envDefault:"text"`
+	LogLevel              string   `env:"LOG_LEVEL" envDefault:"info"`
+	LogRedirectSampleRate float64  `env:"LOG_REDIRECT_SAMPLE_RATE" envDefault:"1"`
+	TrustedProxies        []string `env:"TRUSTED_PROXIES" envSeparator:","`
 }
 
 func LoadConfig() (*Config, error) {
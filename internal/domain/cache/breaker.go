@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// MaxPendingDeletes bounds how many keys whose eviction failed are remembered
+// until the cache is reachable again.
+const MaxPendingDeletes = 10000
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a single probe call
+	// is let through.
+	Cooldown time.Duration
+	// Timeout bounds every call to the wrapped cache; zero leaves calls to the
+	// caller's deadline.
+	Timeout time.Duration
+}
+
+// CircuitBreakerURLCache stops calling a failing cache for a while so that an
+// unreachable Redis does not add its timeouts to every request. While the
+// circuit is open calls fail fast with ErrCacheUnavailable. Keys that could
+// not be evicted are deleted once the cache recovers, so it does not keep
+// serving links that changed in the meantime.
+type CircuitBreakerURLCache struct {
+	next   URLCache
+	opts   CircuitBreakerOptions
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu             sync.Mutex
+	state          circuitState
+	failures       int
+	openedAt       time.Time
+	probing        bool
+	pendingDeletes map[string]struct{}
+}
+
+func NewCircuitBreakerURLCache(next URLCache, opts CircuitBreakerOptions, logger *slog.Logger) *CircuitBreakerURLCache {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+	metrics.CacheCircuitState.Set(float64(circuitClosed))
+	return &CircuitBreakerURLCache{
+		next:           next,
+		opts:           opts,
+		logger:         logger,
+		now:            time.Now,
+		pendingDeletes: make(map[string]struct{}),
+	}
+}
+
+func (c *CircuitBreakerURLCache) Get(ctx context.Context, key string) (value *string, err error) {
+	err = c.call(ctx, func(ctx context.Context) error {
+		value, err = c.next.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (c *CircuitBreakerURLCache) MGet(ctx context.Context, keys []string) (found map[string]string, err error) {
+	err = c.call(ctx, func(ctx context.Context) error {
+		found, err = c.next.MGet(ctx, keys)
+		return err
+	})
+	return found, err
+}
+
+func (c *CircuitBreakerURLCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return c.call(ctx, func(ctx context.Context) error {
+		return c.next.Set(ctx, key, value, expiration)
+	})
+}
+
+func (c *CircuitBreakerURLCache) MSet(ctx context.Context, entries map[string]string, expiration time.Duration) error {
+	return c.call(ctx, func(ctx context.Context) error {
+		return c.next.MSet(ctx, entries, expiration)
+	})
+}
+
+func (c *CircuitBreakerURLCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	return c.call(ctx, func(ctx context.Context) error {
+		return c.next.SetNotFound(ctx, key, expiration)
+	})
+}
+
+func (c *CircuitBreakerURLCache) Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (warmed int, err error) {
+	err = c.call(ctx, func(ctx context.Context) error {
+		warmed, err = c.next.Warm(ctx, entries, expiration)
+		return err
+	})
+	return warmed, err
+}
+
+func (c *CircuitBreakerURLCache) Delete(ctx context.Context, key string) error {
+	err := c.call(ctx, func(ctx context.Context) error {
+		return c.next.Delete(ctx, key)
+	})
+	if err != nil && ctx.Err() == nil {
+		c.mu.Lock()
+		if len(c.pendingDeletes) < MaxPendingDeletes {
+			c.pendingDeletes[key] = struct{}{}
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *CircuitBreakerURLCache) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !c.allow() {
+		metrics.CacheCircuitRejectionsTotal.Inc()
+		return ErrCacheUnavailable
+	}
+
+	callCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.opts.Timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+	}
+	err := fn(callCtx)
+	cancel()
+
+	c.record(ctx, err)
+	return err
+}
+
+func (c *CircuitBreakerURLCache) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case circuitOpen:
+		if c.now().Sub(c.openedAt) < c.opts.Cooldown {
+			return false
+		}
+		c.setState(circuitHalfOpen)
+		c.probing = true
+		return true
+	case circuitHalfOpen:
+		if c.probing {
+			return false
+		}
+		c.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (c *CircuitBreakerURLCache) record(ctx context.Context, err error) {
+	healthy := err == nil || errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrCachedNotFound)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	switch {
+	case healthy:
+		c.failures = 0
+		if c.state != circuitClosed {
+			c.setState(circuitClosed)
+			c.replayDeletes()
+		}
+	case ctx.Err() != nil:
+		// The caller gave up, which says nothing about the cache.
+	default:
+		c.failures++
+		if c.state == circuitHalfOpen || c.failures >= c.opts.FailureThreshold {
+			if c.state != circuitOpen {
+				c.logger.Warn("Cache unavailable, bypassing it", "cooldown", c.opts.Cooldown, "error", err)
+			}
+			c.openedAt = c.now()
+			c.setState(circuitOpen)
+		}
+	}
+}
+
+// replayDeletes retries the evictions that failed while the cache was down.
+// It must be called with c.mu held.
+func (c *CircuitBreakerURLCache) replayDeletes() {
+	c.logger.Info("Cache available again", "pendingDeletes", len(c.pendingDeletes))
+	if len(c.pendingDeletes) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(c.pendingDeletes))
+	for key := range c.pendingDeletes {
+		keys = append(keys, key)
+	}
+	clear(c.pendingDeletes)
+
+	go func() {
+		for _, key := range keys {
+			// Failed deletes are queued again by Delete itself.
+			_ = c.Delete(context.Background(), key)
+		}
+	}()
+}
+
+func (c *CircuitBreakerURLCache) setState(state circuitState) {
+	c.state = state
+	metrics.CacheCircuitState.Set(float64(state))
+}
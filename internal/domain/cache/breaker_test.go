@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyURLCache fails every call with err while it is set.
+type flakyURLCache struct {
+	*MemoryURLCache
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (c *flakyURLCache) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *flakyURLCache) check() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.err
+}
+
+func (c *flakyURLCache) Get(ctx context.Context, key string) (*string, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.MemoryURLCache.Get(ctx, key)
+}
+
+func (c *flakyURLCache) Delete(ctx context.Context, key string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.MemoryURLCache.Delete(ctx, key)
+}
+
+func newTestBreaker(next URLCache) (*CircuitBreakerURLCache, *time.Time) {
+	now := time.Now()
+	c := NewCircuitBreakerURLCache(next, CircuitBreakerOptions{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCircuitBreakerURLCache_OpensAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	remote := &flakyURLCache{MemoryURLCache: NewMemoryURLCache(10)}
+	c, _ := newTestBreaker(remote)
+	down := errors.New("connection refused")
+	remote.fail(down)
+
+	_, err := c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, down)
+	_, err = c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, down)
+
+	_, err = c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheUnavailable)
+	assert.Equal(t, 2, remote.calls)
+}
+
+func TestCircuitBreakerURLCache_MissesAreHealthy(t *testing.T) {
+	ctx := context.Background()
+	remote := &flakyURLCache{MemoryURLCache: NewMemoryURLCache(10)}
+	c, _ := newTestBreaker(remote)
+
+	for range 5 {
+		_, err := c.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrCacheMiss)
+	}
+	assert.Equal(t, circuitClosed, c.state)
+}
+
+func TestCircuitBreakerURLCache_CallerCancellationIsNotAFailure(t *testing.T) {
+	remote := &flakyURLCache{MemoryURLCache: NewMemoryURLCache(10)}
+	c, _ := newTestBreaker(remote)
+	remote.fail(context.Canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 5 {
+		_, err := c.Get(ctx, "exmpl")
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	assert.Equal(t, circuitClosed, c.state)
+}
+
+func TestCircuitBreakerURLCache_RecoversAfterCooldown(t *testing.T) {
+	ctx := context.Background()
+	remote := &flakyURLCache{MemoryURLCache: NewMemoryURLCache(10)}
+	c, now := newTestBreaker(remote)
+	require.NoError(t, remote.Set(ctx, "exmpl", "https://google.com", time.Hour))
+	remote.fail(errors.New("connection refused"))
+
+	_, _ = c.Get(ctx, "exmpl")
+	_, _ = c.Get(ctx, "exmpl")
+	assert.Equal(t, circuitOpen, c.state)
+
+	// A failed probe opens the circuit for another cooldown.
+	*now = now.Add(time.Minute)
+	_, err := c.Get(ctx, "exmpl")
+	assert.NotErrorIs(t, err, ErrCacheUnavailable)
+	_, err = c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheUnavailable)
+
+	remote.fail(nil)
+	*now = now.Add(time.Minute)
+	val, err := c.Get(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", *val)
+	assert.Equal(t, circuitClosed, c.state)
+}
+
+func TestCircuitBreakerURLCache_ReplaysDeletesOnRecovery(t *testing.T) {
+	ctx := context.Background()
+	remote := &flakyURLCache{MemoryURLCache: NewMemoryURLCache(10)}
+	c, now := newTestBreaker(remote)
+	require.NoError(t, remote.Set(ctx, "exmpl", "https://google.com", time.Hour))
+	remote.fail(errors.New("connection refused"))
+
+	assert.Error(t, c.Delete(ctx, "exmpl"))
+	assert.Error(t, c.Delete(ctx, "exmpl"))
+	assert.ErrorIs(t, c.Delete(ctx, "exmpl"), ErrCacheUnavailable)
+
+	remote.fail(nil)
+	*now = now.Add(time.Minute)
+	_, err := c.Get(ctx, "other")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	assert.Eventually(t, func() bool {
+		_, err := remote.MemoryURLCache.Get(ctx, "exmpl")
+		return errors.Is(err, ErrCacheMiss)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNoopURLCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewNoopURLCache()
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Hour))
+	_, err := c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	warmed, err := c.Warm(ctx, map[string]string{"exmpl": "https://google.com"}, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, warmed)
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		got := jitter(time.Hour)
+		assert.GreaterOrEqual(t, got, time.Hour)
+		assert.LessOrEqual(t, got, time.Hour+6*time.Minute)
+	}
+
+	assert.Equal(t, time.Duration(0), jitter(0))
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// InvalidationChannel is the Redis pub/sub channel on which deleted or
+// changed keys are announced to every instance.
+const InvalidationChannel = "url-shortener:cache:invalidate"
+
+const (
+	minListenBackoff = time.Second
+	maxListenBackoff = 30 * time.Second
+)
+
+// LayeredURLCache keeps recently used entries in process memory in front of a
+// shared remote cache. Local entries live for at most localTTL, which bounds
+// how stale an instance can get if it misses an invalidation message.
+type LayeredURLCache struct {
+	local    URLCache
+	remote   URLCache
+	client   *redis.Client
+	localTTL time.Duration
+}
+
+// NewLayeredURLCache layers local over remote. Deletes are announced on
+// InvalidationChannel through client, which may be nil when no other instance
+// keeps a local cache.
+func NewLayeredURLCache(local, remote URLCache, client *redis.Client, localTTL time.Duration) *LayeredURLCache {
+	return &LayeredURLCache{
+		local:    local,
+		remote:   remote,
+		client:   client,
+		localTTL: localTTL,
+	}
+}
+
+func (c *LayeredURLCache) Get(ctx context.Context, key string) (*string, error) {
+	value, err := c.local.Get(ctx, key)
+	if err == nil || errors.Is(err, ErrCachedNotFound) {
+		metrics.LocalCacheRequestsTotal.WithLabelValues(metrics.CacheHit).Inc()
+		return value, err
+	}
+	metrics.LocalCacheRequestsTotal.WithLabelValues(metrics.CacheMiss).Inc()
+
+	value, err = c.remote.Get(ctx, key)
+	switch {
+	case err == nil:
+		_ = c.local.Set(ctx, key, *value, c.localTTL)
+	case errors.Is(err, ErrCachedNotFound):
+		_ = c.local.SetNotFound(ctx, key, c.localTTL)
+	}
+	return value, err
+}
+
+func (c *LayeredURLCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	found, _ := c.local.MGet(ctx, keys)
+
+	missing := make([]string, 0, len(keys)-len(found))
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	remote, err := c.remote.MGet(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.local.MSet(ctx, remote, c.localTTL)
+	for key, value := range remote {
+		found[key] = value
+	}
+	return found, nil
+}
+
+func (c *LayeredURLCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+	return c.local.Set(ctx, key, value, c.localExpiration(expiration))
+}
+
+func (c *LayeredURLCache) MSet(ctx context.Context, entries map[string]string, expiration time.Duration) error {
+	if err := c.remote.MSet(ctx, entries, expiration); err != nil {
+		return err
+	}
+	return c.local.MSet(ctx, entries, c.localExpiration(expiration))
+}
+
+// Warm only warms the remote tier; the local tier fills itself as links are
+// resolved on each instance.
+func (c *LayeredURLCache) Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (int, error) {
+	return c.remote.Warm(ctx, entries, expiration)
+}
+
+func (c *LayeredURLCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	if err := c.remote.SetNotFound(ctx, key, expiration); err != nil {
+		return err
+	}
+	return c.local.SetNotFound(ctx, key, c.localExpiration(expiration))
+}
+
+// Delete removes key from both tiers and tells other instances to drop their
+// local copy.
+func (c *LayeredURLCache) Delete(ctx context.Context, key string) error {
+	_ = c.local.Delete(ctx, key)
+	err := c.remote.Delete(ctx, key)
+	// Publishing is pointless while the breaker in front of Redis is open.
+	if c.client != nil && !errors.Is(err, ErrCacheUnavailable) {
+		if pubErr := c.client.Publish(ctx, InvalidationChannel, key).Err(); pubErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to publish invalidation: %w", pubErr))
+		}
+	}
+	return err
+}
+
+// Listen evicts keys announced by other instances from the local tier until
+// ctx is cancelled. While Redis is unreachable it keeps trying to subscribe,
+// backing off up to maxListenBackoff; invalidations sent in the meantime are
+// lost, so local entries may be stale for up to the local TTL.
+func (c *LayeredURLCache) Listen(ctx context.Context) error {
+	if c.client == nil {
+		return errors.New("layered cache has no Redis client to listen on")
+	}
+
+	backoff := minListenBackoff
+	for {
+		err := c.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			backoff = minListenBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxListenBackoff)
+	}
+}
+
+// listen returns an error when subscribing fails and nil when an established
+// subscription ends.
+func (c *LayeredURLCache) listen(ctx context.Context) error {
+	pubsub := c.client.Subscribe(ctx, InvalidationChannel)
+	defer pubsub.Close() //nolint:errcheck
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", InvalidationChannel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			_ = c.local.Delete(ctx, msg.Payload)
+			metrics.LocalCacheInvalidationsTotal.Inc()
+		}
+	}
+}
+
+func (c *LayeredURLCache) localExpiration(expiration time.Duration) time.Duration {
+	if expiration <= 0 {
+		return c.localTTL
+	}
+	return min(expiration, c.localTTL)
+}
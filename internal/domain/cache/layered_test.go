@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayeredURLCache_ReadsThroughToRemote(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryURLCache(10)
+	remote := NewMemoryURLCache(10)
+	c := NewLayeredURLCache(local, remote, nil, time.Minute)
+
+	require.NoError(t, remote.Set(ctx, "exmpl", "https://google.com", time.Hour))
+	require.NoError(t, remote.SetNotFound(ctx, "missing", time.Hour))
+
+	val, err := c.Get(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", *val)
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCachedNotFound)
+
+	val, err = local.Get(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", *val)
+	_, err = local.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCachedNotFound)
+}
+
+func TestLayeredURLCache_LocalExpirationIsBounded(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	local := NewMemoryURLCache(10)
+	local.lru.now = func() time.Time { return now }
+	remote := NewMemoryURLCache(10)
+	c := NewLayeredURLCache(local, remote, nil, time.Minute)
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Hour))
+
+	now = now.Add(time.Minute)
+	_, err := local.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	val, err := c.Get(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", *val)
+}
+
+func TestLayeredURLCache_DeleteClearsBothTiers(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryURLCache(10)
+	remote := NewMemoryURLCache(10)
+	c := NewLayeredURLCache(local, remote, nil, time.Minute)
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Hour))
+	require.NoError(t, c.Delete(ctx, "exmpl"))
+
+	_, err := local.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	_, err = remote.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestLayeredURLCache_MGet(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryURLCache(10)
+	remote := NewMemoryURLCache(10)
+	c := NewLayeredURLCache(local, remote, nil, time.Minute)
+
+	require.NoError(t, local.Set(ctx, "a", "https://a.com", time.Minute))
+	require.NoError(t, remote.Set(ctx, "b", "https://b.com", time.Hour))
+
+	found, err := c.MGet(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "https://a.com", "b": "https://b.com"}, found)
+
+	val, err := local.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.com", *val)
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MemoryURLCache is an in-process LRU cache bounded by the number of entries.
+type MemoryURLCache struct {
+	lru *LRU[memoryEntry]
+}
+
+type memoryEntry struct {
+	value    string
+	notFound bool
+}
+
+func NewMemoryURLCache(capacity int) *MemoryURLCache {
+	return &MemoryURLCache{
+		lru: NewLRU[memoryEntry](capacity),
+	}
+}
+
+func (c *MemoryURLCache) Get(_ context.Context, key string) (*string, error) {
+	entry, ok := c.lru.Get(key)
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if entry.notFound {
+		return nil, ErrCachedNotFound
+	}
+	return &entry.value, nil
+}
+
+func (c *MemoryURLCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	found := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, err := c.Get(ctx, key); err == nil {
+			found[key] = *value
+		}
+	}
+	return found, nil
+}
+
+func (c *MemoryURLCache) Set(_ context.Context, key string, value string, expiration time.Duration) error {
+	c.lru.Set(key, memoryEntry{value: value}, expiration)
+	return nil
+}
+
+func (c *MemoryURLCache) MSet(_ context.Context, entries map[string]string, expiration time.Duration) error {
+	for key, value := range entries {
+		c.lru.Set(key, memoryEntry{value: value}, expiration)
+	}
+	return nil
+}
+
+func (c *MemoryURLCache) Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (int, error) {
+	warmed := 0
+	for key, value := range entries {
+		if _, err := c.Get(ctx, key); !errors.Is(err, ErrCacheMiss) {
+			continue
+		}
+		c.lru.Set(key, memoryEntry{value: value}, expiration)
+		warmed++
+	}
+	return warmed, nil
+}
+
+func (c *MemoryURLCache) SetNotFound(_ context.Context, key string, expiration time.Duration) error {
+	c.lru.Set(key, memoryEntry{notFound: true}, expiration)
+	return nil
+}
+
+func (c *MemoryURLCache) Delete(_ context.Context, key string) error {
+	c.lru.Delete(key)
+	return nil
+}
+
+// Len returns the number of entries, including expired ones that have not
+// been evicted yet.
+func (c *MemoryURLCache) Len() int {
+	return c.lru.Len()
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryURLCache_GetSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryURLCache(10)
+
+	_, err := c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Minute))
+	val, err := c.Get(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", *val)
+
+	require.NoError(t, c.SetNotFound(ctx, "missing", time.Minute))
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCachedNotFound)
+
+	require.NoError(t, c.Delete(ctx, "exmpl"))
+	_, err = c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestMemoryURLCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryURLCache(2)
+
+	require.NoError(t, c.Set(ctx, "a", "https://a.com", time.Minute))
+	require.NoError(t, c.Set(ctx, "b", "https://b.com", time.Minute))
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "c", "https://c.com", time.Minute))
+
+	assert.Equal(t, 2, c.Len())
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	_, err = c.Get(ctx, "a")
+	assert.NoError(t, err)
+	_, err = c.Get(ctx, "c")
+	assert.NoError(t, err)
+}
+
+func TestMemoryURLCache_Expires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewMemoryURLCache(10)
+	c.lru.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Minute))
+	require.NoError(t, c.Set(ctx, "forever", "https://google.com", 0))
+
+	now = now.Add(time.Minute)
+	_, err := c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	_, err = c.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestMemoryURLCache_ZeroCapacity(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryURLCache(0)
+
+	require.NoError(t, c.Set(ctx, "exmpl", "https://google.com", time.Minute))
+	_, err := c.Get(ctx, "exmpl")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestMemoryURLCache_MGetMSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryURLCache(10)
+
+	require.NoError(t, c.MSet(ctx, map[string]string{"a": "https://a.com", "b": "https://b.com"}, time.Minute))
+	require.NoError(t, c.SetNotFound(ctx, "c", time.Minute))
+
+	found, err := c.MGet(ctx, []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "https://a.com", "b": "https://b.com"}, found)
+}
+
+func TestMemoryURLCache_WarmKeepsExistingEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryURLCache(10)
+
+	require.NoError(t, c.Set(ctx, "a", "https://current.com", time.Minute))
+	warmed, err := c.Warm(ctx, map[string]string{"a": "https://stale.com", "b": "https://b.com"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, warmed)
+
+	val, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "https://current.com", *val)
+	val, err = c.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.com", *val)
+}
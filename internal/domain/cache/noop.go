@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopURLCache caches nothing. It is used when no Redis is configured so that
+// every lookup goes straight to the repository.
+type NoopURLCache struct{}
+
+func NewNoopURLCache() URLCache {
+	return NoopURLCache{}
+}
+
+func (NoopURLCache) Get(context.Context, string) (*string, error) {
+	return nil, ErrCacheMiss
+}
+
+func (NoopURLCache) MGet(context.Context, []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (NoopURLCache) Set(context.Context, string, string, time.Duration) error {
+	return nil
+}
+
+func (NoopURLCache) MSet(context.Context, map[string]string, time.Duration) error {
+	return nil
+}
+
+func (NoopURLCache) SetNotFound(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (NoopURLCache) Warm(context.Context, map[string]string, time.Duration) (int, error) {
+	return 0, nil
+}
+
+func (NoopURLCache) Delete(context.Context, string) error {
+	return nil
+}
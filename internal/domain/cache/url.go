@@ -3,14 +3,42 @@ package cache
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/unwale/url-shortener/internal/telemetry"
 )
 
+var tracer = otel.Tracer("github.com/unwale/url-shortener/internal/domain/cache")
+
+// MaxExpirationJitter is the largest fraction added to an entry's expiration
+// so that entries written together do not all expire at the same moment.
+const MaxExpirationJitter = 0.1
+
+// notFoundValue marks a key that is known not to exist. Short links always
+// point at a non-empty URL, so the empty string can never be a real entry.
+const notFoundValue = ""
+
 type URLCache interface {
+	// Get returns ErrCacheMiss when nothing is cached for key and
+	// ErrCachedNotFound when key was cached as not existing.
 	Get(ctx context.Context, key string) (*string, error)
+	// MGet returns the cached values of keys. Keys that are not cached, or are
+	// cached as not found, are left out of the result.
+	MGet(ctx context.Context, keys []string) (map[string]string, error)
 	Set(ctx context.Context, key string, value string, expiration time.Duration) error
+	MSet(ctx context.Context, entries map[string]string, expiration time.Duration) error
+	SetNotFound(ctx context.Context, key string, expiration time.Duration) error
+	// Warm caches the entries whose keys are not cached yet and returns how
+	// many were added. Unlike MSet it never replaces an existing entry.
+	Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (int, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type RedisURLCache struct {
@@ -18,7 +46,11 @@ type RedisURLCache struct {
 }
 
 var (
-	ErrCacheMiss = errors.New("cache miss")
+	ErrCacheMiss      = errors.New("cache miss")
+	ErrCachedNotFound = errors.New("cached as not found")
+	// ErrCacheUnavailable is returned without contacting the cache while its
+	// circuit breaker is open.
+	ErrCacheUnavailable = errors.New("cache unavailable")
 )
 
 func NewRedisURLCache(client *redis.Client) URLCache {
@@ -28,19 +60,119 @@ func NewRedisURLCache(client *redis.Client) URLCache {
 }
 
 func (c *RedisURLCache) Get(ctx context.Context, key string) (*string, error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.Get", "GET")
+	defer span.End()
+
 	val, err := c.client.Get(ctx, key).Result()
 	if err == redis.Nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
 		return nil, ErrCacheMiss
 	} else if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, err
 	}
+	span.SetAttributes(attribute.Bool("cache.hit", true))
+	if val == notFoundValue {
+		return nil, ErrCachedNotFound
+	}
 	return &val, nil
 }
 
-func (c *RedisURLCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
-	err := c.client.Set(ctx, key, value, expiration).Err()
+func (c *RedisURLCache) MGet(ctx context.Context, keys []string) (_ map[string]string, err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.MGet", "MGET")
+	defer telemetry.End(span, &err)
+
+	if len(keys) == 0 {
+		return map[string]string{}, nil
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]string, len(keys))
+	for i, value := range values {
+		if value, ok := value.(string); ok && value != notFoundValue {
+			found[keys[i]] = value
+		}
+	}
+	span.SetAttributes(attribute.Int("cache.keys", len(keys)), attribute.Int("cache.hits", len(found)))
+	return found, nil
+}
+
+func (c *RedisURLCache) Set(ctx context.Context, key string, value string, expiration time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.Set", "SET")
+	defer telemetry.End(span, &err)
+
+	err = c.client.Set(ctx, key, value, jitter(expiration)).Err()
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (c *RedisURLCache) MSet(ctx context.Context, entries map[string]string, expiration time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.MSet", "SET")
+	defer telemetry.End(span, &err)
+
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range entries {
+			pipe.Set(ctx, key, value, jitter(expiration))
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *RedisURLCache) Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (_ int, err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.Warm", "SETNX")
+	defer telemetry.End(span, &err)
+
+	cmds := make([]*redis.BoolCmd, 0, len(entries))
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range entries {
+			cmds = append(cmds, pipe.SetNX(ctx, key, value, jitter(expiration)))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	warmed := 0
+	for _, cmd := range cmds {
+		if cmd.Val() {
+			warmed++
+		}
+	}
+	return warmed, nil
+}
+
+func (c *RedisURLCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.SetNotFound", "SET")
+	defer telemetry.End(span, &err)
+
+	return c.client.Set(ctx, key, notFoundValue, jitter(expiration)).Err()
+}
+
+func (c *RedisURLCache) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := startSpan(ctx, "RedisURLCache.Delete", "DEL")
+	defer telemetry.End(span, &err)
+
+	return c.client.Del(ctx, key).Err()
+}
+
+func startSpan(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(operation)),
+	)
+}
+
+func jitter(expiration time.Duration) time.Duration {
+	maxJitter := int64(float64(expiration) * MaxExpirationJitter)
+	if maxJitter <= 0 {
+		return expiration
+	}
+	return expiration + time.Duration(rand.Int64N(maxJitter+1))
+}
@@ -4,6 +4,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -78,3 +79,122 @@ func TestGet(t *testing.T) {
 		})
 	})
 }
+
+func TestDelete(t *testing.T) {
+	t.Run("delete existing url", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).Set(context.Background(), "exmpl", "https://google.com", 5*time.Second)
+			require.NoError(t, err)
+
+			err = (*repo).Delete(context.Background(), "exmpl")
+			require.NoError(t, err)
+
+			_, err = (*repo).Get(context.Background(), "exmpl")
+			require.ErrorIs(t, err, ErrCacheMiss)
+		})
+	})
+}
+
+func TestSetNotFound(t *testing.T) {
+	t.Run("get url cached as not found", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).SetNotFound(context.Background(), "missing", 5*time.Second)
+			require.NoError(t, err)
+
+			val, err := (*repo).Get(context.Background(), "missing")
+			require.ErrorIs(t, err, ErrCachedNotFound)
+			require.Nil(t, val)
+		})
+	})
+
+	t.Run("set replaces not found entry", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).SetNotFound(context.Background(), "exmpl", 5*time.Second)
+			require.NoError(t, err)
+
+			err = (*repo).Set(context.Background(), "exmpl", "https://google.com", 5*time.Second)
+			require.NoError(t, err)
+
+			val, err := (*repo).Get(context.Background(), "exmpl")
+			require.NoError(t, err)
+			require.Equal(t, "https://google.com", *val)
+		})
+	})
+
+	t.Run("expiration is jittered", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).Set(context.Background(), "exmpl", "https://google.com", 100*time.Second)
+			require.NoError(t, err)
+
+			ttl, err := testRedisClient.TTL(context.Background(), "exmpl").Result()
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, ttl, 99*time.Second)
+			require.LessOrEqual(t, ttl, 110*time.Second)
+		})
+	})
+}
+
+func TestLayeredInvalidation(t *testing.T) {
+	t.Run("delete evicts local copies on other instances", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			otherLocal := NewMemoryURLCache(10)
+			other := NewLayeredURLCache(otherLocal, *repo, testRedisClient, time.Minute)
+			listening := make(chan error, 1)
+			go func() { listening <- other.Listen(ctx) }()
+
+			this := NewLayeredURLCache(NewMemoryURLCache(10), *repo, testRedisClient, time.Minute)
+			require.NoError(t, this.Set(ctx, "exmpl", "https://google.com", time.Hour))
+			_, err := other.Get(ctx, "exmpl")
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				require.NoError(t, this.Delete(ctx, "exmpl"))
+				_, err := otherLocal.Get(ctx, "exmpl")
+				return errors.Is(err, ErrCacheMiss)
+			}, 2*time.Second, 50*time.Millisecond)
+
+			cancel()
+			require.NoError(t, <-listening)
+		})
+	})
+}
+
+func TestMGetMSet(t *testing.T) {
+	t.Run("get multiple urls", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).MSet(context.Background(), map[string]string{
+				"a": "https://a.com",
+				"b": "https://b.com",
+			}, 5*time.Second)
+			require.NoError(t, err)
+			require.NoError(t, (*repo).SetNotFound(context.Background(), "c", 5*time.Second))
+
+			found, err := (*repo).MGet(context.Background(), []string{"a", "b", "c", "d"})
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"a": "https://a.com", "b": "https://b.com"}, found)
+		})
+	})
+}
+
+func TestWarm(t *testing.T) {
+	t.Run("warm keeps existing entries", func(t *testing.T) {
+		runWithTestCache(t, func(repo *URLCache) {
+			err := (*repo).Set(context.Background(), "a", "https://current.com", 5*time.Second)
+			require.NoError(t, err)
+
+			warmed, err := (*repo).Warm(context.Background(), map[string]string{
+				"a": "https://stale.com",
+				"b": "https://b.com",
+			}, 5*time.Second)
+			require.NoError(t, err)
+			require.Equal(t, 1, warmed)
+
+			val, err := (*repo).Get(context.Background(), "a")
+			require.NoError(t, err)
+			require.Equal(t, "https://current.com", *val)
+		})
+	})
+}
@@ -0,0 +1,13 @@
+package model
+
+// OwnerDeletion reports the data of an owner that was deleted.
+type OwnerDeletion struct {
+	// ShortUrls are the codes of the deleted links. Their clicks, routing
+	// rules and variants are deleted with them.
+	ShortUrls []string
+	// Webhooks counts the deleted webhooks, whose delivery logs are deleted
+	// with them.
+	Webhooks int64
+	// Events counts the deleted outbox events about the owner's links.
+	Events int64
+}
@@ -0,0 +1,64 @@
+package model
+
+// Dimensions the clicks of a link are broken down by.
+const (
+	ClickDimensionClass    = "class"
+	ClickDimensionReferrer = "referrer"
+	ClickDimensionBrowser  = "browser"
+	ClickDimensionOS       = "os"
+	ClickDimensionDevice   = "device"
+)
+
+// Values of the class dimension.
+const (
+	ClickClassHuman = "human"
+	ClickClassBot   = "bot"
+)
+
+// Click is a resolve of a link, described by the dimensions it is counted in.
+// Clicks by bots are only counted by class.
+type Click struct {
+	ShortUrl string
+	Bot      bool
+	Referrer string
+	Browser  string
+	OS       string
+	Device   string
+}
+
+// ClickAnalytics breaks down the clicks of a link. Every breakdown holds the
+// most frequent values, most frequent first; Hourly and Daily count the clicks
+// of recent hours and days, oldest first.
+type ClickAnalytics struct {
+	BotClicks int64
+	Referrers []ValueCount
+	Browsers  []ValueCount
+	OSes      []ValueCount
+	Devices   []ValueCount
+	Hourly    []HourlyCount
+	Daily     []DailyCount
+}
+
+type ValueCount struct {
+	Value string
+	Count int64
+}
+
+// UniqueVisitors are estimates of how many different visitors resolved a
+// link. Daily holds one count per day, oldest first.
+type UniqueVisitors struct {
+	Total      int64
+	Last7Days  int64
+	Last30Days int64
+	Daily      []DailyCount
+}
+
+type HourlyCount struct {
+	Hour  string
+	Count int64
+}
+
+type DailyCount struct {
+	Date  string
+	Count int64
+}
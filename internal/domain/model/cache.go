@@ -0,0 +1,6 @@
+package model
+
+type CacheWarmReport struct {
+	Loaded int
+	Warmed int
+}
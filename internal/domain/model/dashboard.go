@@ -0,0 +1,21 @@
+package model
+
+// Dashboard summarizes the links of an owner. Click counts leave out bots.
+type Dashboard struct {
+	// Window is the period TopLinks are ranked by, such as "7d".
+	Window             string
+	TotalLinks         int64
+	TotalClicks        int64
+	ZeroClickLinkCount int64
+	// TopLinks are the links with the most clicks within Window, most clicked
+	// first.
+	TopLinks    []*TopLink
+	NewestLinks []*Url
+	// ZeroClickLinks are links that have never been clicked, oldest first.
+	ZeroClickLinks []*Url
+}
+
+type TopLink struct {
+	Url
+	WindowClicks int64
+}
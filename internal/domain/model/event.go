@@ -0,0 +1,24 @@
+package model
+
+import "encoding/json"
+
+// Types of the events recorded about a link's lifecycle.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkClicked = "link.clicked"
+)
+
+type Event struct {
+	ID       int64
+	Type     string
+	ShortUrl string
+	// Owner identifies the API key the link was created with, if any.
+	Owner      string
+	Data       json.RawMessage
+	OccurredAt string
+	// Attempts counts deliveries of the event so far, including the current
+	// one.
+	Attempts int
+}
@@ -0,0 +1,34 @@
+package model
+
+// BrokenAfterFailures is how many checks in a row must fail before a link is
+// considered broken, so that a destination that is briefly down is not.
+const BrokenAfterFailures = 3
+
+// LinkCheck is a link whose destination is due to be checked.
+type LinkCheck struct {
+	ShortUrl    string
+	OriginalUrl string
+}
+
+// LinkCheckResult is the outcome of checking the destination of a link.
+type LinkCheckResult struct {
+	ShortUrl string
+	// CheckedUrl is the destination that was checked.
+	CheckedUrl string
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	Error      string
+	Healthy    bool
+}
+
+// LinkHealth is the latest check of the current destination of a link.
+type LinkHealth struct {
+	ShortUrl    string
+	OriginalUrl string
+	StatusCode  int
+	Error       string
+	// Failures counts the checks that failed in a row.
+	Failures  int
+	Broken    bool
+	CheckedAt string
+}
@@ -0,0 +1,17 @@
+package model
+
+// LinkMetadata describes the page a link points to, as read from its HTML.
+type LinkMetadata struct {
+	ShortUrl string
+	// FetchedUrl is the destination the metadata was read from.
+	FetchedUrl  string
+	Title       string
+	Description string
+	FaviconUrl  string
+	// OpenGraph maps og:* properties, without the prefix, to their first
+	// value.
+	OpenGraph map[string]string
+	// Error is why the page could not be read, if it could not.
+	Error     string
+	FetchedAt string
+}
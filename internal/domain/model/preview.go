@@ -0,0 +1,12 @@
+package model
+
+// LinkPreview is the card shown for a link when it is shared on a chat app or
+// social network, in place of the one of its destination. Empty fields are
+// filled from the metadata of the destination.
+type LinkPreview struct {
+	ShortUrl    string
+	Title       string
+	Description string
+	ImageUrl    string
+	UpdatedAt   string
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// Device classes a routing rule can match.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Operating systems a routing rule can match.
+const (
+	OSiOS     = "ios"
+	OSAndroid = "android"
+	OSWindows = "windows"
+	OSMacOS   = "macos"
+	OSLinux   = "linux"
+)
+
+// Browsers recorded in click analytics.
+const (
+	BrowserChrome  = "chrome"
+	BrowserSafari  = "safari"
+	BrowserFirefox = "firefox"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+)
+
+// Visitor describes who follows a link, as far as routing rules care.
+// Fields that could not be determined are empty.
+type Visitor struct {
+	Device  string
+	OS      string
+	Browser string
+	// Language is the visitor's most preferred language tag, lowercased.
+	Language string
+	// Country is an ISO 3166-1 alpha-2 code.
+	Country string
+	// Referrer is the domain of the page the link was followed from.
+	Referrer string
+	// Bot is set for crawlers, link previews and prefetches, whose requests
+	// are not counted as clicks.
+	Bot bool
+	// Key identifies the visitor across requests, so that split links keep
+	// sending them to the same variant.
+	Key string
+	// OptedOut is set for visitors who ask not to be tracked. They are
+	// counted in aggregates but not as unique visitors, and get no cookie.
+	OptedOut bool
+	// Routed is set once the rules or variants of a link were applied to the
+	// visitor, meaning the destination depends on who follows the link.
+	Routed bool
+	// Fallback is set when the link is broken and the visitor was sent to
+	// the fallback URL instead.
+	Fallback bool
+}
+
+// RoutingRule sends visitors of a link that match all of its conditions to
+// Destination instead of the link's original URL. A condition matches when
+// it is empty or contains the visitor's value; languages also match regional
+// variants, so "de" matches "de-at".
+type RoutingRule struct {
+	ID          int64
+	ShortUrl    string
+	Priority    int
+	Devices     []string
+	OSes        []string
+	Languages   []string
+	Countries   []string
+	Destination string
+	CreatedAt   string
+}
+
+func (r *RoutingRule) Matches(v *Visitor) bool {
+	return matchesAny(r.Devices, v.Device) &&
+		matchesAny(r.OSes, v.OS) &&
+		matchesAny(r.Countries, v.Country) &&
+		(len(r.Languages) == 0 || slices.ContainsFunc(r.Languages, func(language string) bool {
+			return v.Language == language || strings.HasPrefix(v.Language, language+"-")
+		}))
+}
+
+func matchesAny(values []string, value string) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
+
+// Variant is one of the destinations a split link distributes its traffic
+// between, receiving Weight percent of the visitors.
+type Variant struct {
+	ID          int64
+	ShortUrl    string
+	Destination string
+	Weight      int
+	ClickCount  int64
+	CreatedAt   string
+}
+
+// Route is where a visitor of a link is sent.
+type Route struct {
+	// Destination is "" when the visitor goes to the link's original URL.
+	Destination string
+	// VariantID is the split variant the visitor was assigned to, if any.
+	VariantID int64
+	// Targeted is set when the link has routing rules or variants.
+	Targeted bool
+}
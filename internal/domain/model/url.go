@@ -6,6 +6,20 @@ type Url struct {
 	ClickCount  int64
 	CreatedAt   string
 	UpdatedAt   string
+	// Owner identifies the API key the link was created with, if any.
+	// Lookups of a single link report it; listings may leave it empty.
+	Owner string
+	// Variants split the link's traffic between several destinations.
+	Variants []*Variant
+	// Analytics breaks ClickCount down and counts clicks by bots, which
+	// ClickCount leaves out.
+	Analytics *ClickAnalytics
+	// UniqueVisitors counts the different visitors among ClickCount.
+	UniqueVisitors *UniqueVisitors
+	// Health is the latest check of OriginalUrl, if it was checked.
+	Health *LinkHealth
+	// Metadata describes the page OriginalUrl points to, if it was fetched.
+	Metadata *LinkMetadata
 }
 
 type Error struct {
@@ -15,3 +29,33 @@ type Error struct {
 func (e Error) Error() string {
 	return e.Message
 }
+
+type ImportReport struct {
+	DryRun      bool
+	Aborted     bool
+	Total       int
+	Created     int
+	Overwritten int
+	Skipped     int
+	Failed      int
+	Errors      []ImportError
+}
+
+type ImportError struct {
+	Record   int
+	ShortUrl string
+	Message  string
+}
+
+// AddError counts a failed record and keeps its details as long as fewer
+// than limit errors have been collected.
+func (r *ImportReport) AddError(record int, shortUrl, message string, limit int) {
+	r.Failed++
+	if len(r.Errors) < limit {
+		r.Errors = append(r.Errors, ImportError{
+			Record:   record,
+			ShortUrl: shortUrl,
+			Message:  message,
+		})
+	}
+}
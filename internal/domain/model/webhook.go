@@ -0,0 +1,38 @@
+package model
+
+// Statuses of a webhook delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type Webhook struct {
+	ID    int64
+	Owner string
+	URL   string
+	// Secret signs every delivery so that receivers can verify it.
+	Secret string
+	// EventTypes the webhook is subscribed to; empty means all of them.
+	EventTypes []string
+	CreatedAt  string
+}
+
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID int64
+	EventID   int64
+	EventType string
+	Payload   []byte
+	Status    string
+	// Attempts counts deliveries so far, including the current one.
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  string
+	CreatedAt      string
+	UpdatedAt      string
+	// URL and Secret of the webhook, set on claimed deliveries.
+	URL    string
+	Secret string
+}
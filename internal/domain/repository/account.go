@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// AccountRepository manages the data of API key owners as a whole.
+type AccountRepository interface {
+	// DeleteOwnerData deletes the links of owner with everything recorded
+	// about them, its webhooks and its undelivered events in one transaction.
+	DeleteOwnerData(ctx context.Context, owner string) (*model.OwnerDeletion, error)
+}
+
+type accountRepository struct {
+	querier db.Querier
+	tx      TxManager
+}
+
+func NewAccountRepository(conn *pgxpool.Pool) AccountRepository {
+	return &accountRepository{
+		querier: db.New(conn),
+		tx:      NewTxManager(conn),
+	}
+}
+
+func (r *accountRepository) DeleteOwnerData(ctx context.Context, owner string) (_ *model.OwnerDeletion, err error) {
+	ctx, span := startSpan(ctx, "accountRepository.DeleteOwnerData", "DeleteOwnerUrls")
+	defer telemetry.End(span, &err)
+
+	deletion := &model.OwnerDeletion{}
+	err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if deletion.Webhooks, err = r.q(ctx).DeleteOwnerWebhooks(ctx, owner); err != nil {
+			return err
+		}
+		if deletion.ShortUrls, err = r.q(ctx).DeleteOwnerUrls(ctx, owner); err != nil {
+			return err
+		}
+		deletion.Events, err = r.q(ctx).DeleteOwnerOutboxEvents(ctx, owner)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+func (r *accountRepository) q(ctx context.Context) db.Querier {
+	if q, ok := txQuerier(ctx); ok {
+		return q
+	}
+	return r.querier
+}
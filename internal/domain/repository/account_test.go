@@ -0,0 +1,72 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestAccountRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		t.Cleanup(func() {
+			_, err := testPool.Exec(ctx, "TRUNCATE TABLE webhooks, outbox CASCADE")
+			require.NoError(t, err)
+		})
+
+		for _, params := range []*db.CreateUrlParams{
+			{ShortUrl: "ab", OriginalUrl: "https://example.com/a", Owner: pgtype.Text{String: "owner-1", Valid: true}},
+			{ShortUrl: "cd", OriginalUrl: "https://example.com/c", Owner: pgtype.Text{String: "owner-1", Valid: true}},
+			{ShortUrl: "ef", OriginalUrl: "https://example.com/e", Owner: pgtype.Text{String: "owner-2", Valid: true}},
+		} {
+			_, err := (*urls).CreateURL(ctx, params)
+			require.NoError(t, err)
+		}
+		analytics := NewAnalyticsRepository(testPool)
+		require.NoError(t, analytics.RecordClick(ctx, &model.Click{ShortUrl: "ab"}))
+		require.NoError(t, analytics.RecordClick(ctx, &model.Click{ShortUrl: "ef"}))
+		outbox := NewOutboxRepository(testPool)
+		require.NoError(t, outbox.AddEvent(ctx, &model.Event{Type: model.EventLinkCreated, ShortUrl: "ab", Data: []byte("{}")}))
+		require.NoError(t, outbox.AddEvent(ctx, &model.Event{Type: model.EventLinkCreated, ShortUrl: "ef", Data: []byte("{}")}))
+		webhooks := NewWebhookRepository(testPool)
+		require.NoError(t, webhooks.CreateWebhook(ctx, &model.Webhook{Owner: "owner-1", URL: "https://example.com/hook", Secret: "whsec_1"}))
+		require.NoError(t, webhooks.CreateWebhook(ctx, &model.Webhook{Owner: "owner-2", URL: "https://example.com/hook", Secret: "whsec_2"}))
+
+		deletion, err := NewAccountRepository(testPool).DeleteOwnerData(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ab", "cd"}, deletion.ShortUrls)
+		assert.Equal(t, int64(1), deletion.Webhooks)
+		assert.Equal(t, int64(1), deletion.Events)
+
+		_, err = (*urls).GetURLByShortened(ctx, "ab")
+		assert.ErrorIs(t, err, ErrURLNotFound)
+		var events int
+		require.NoError(t, testPool.QueryRow(ctx, "SELECT count(*) FROM click_events WHERE short_url = 'ab'").Scan(&events))
+		assert.Zero(t, events, "clicks are deleted with their link")
+
+		t.Run("other owners are kept", func(t *testing.T) {
+			_, err := (*urls).GetURLByShortened(ctx, "ef")
+			require.NoError(t, err)
+			hooks, err := webhooks.ListWebhooks(ctx, "owner-2")
+			require.NoError(t, err)
+			assert.Len(t, hooks, 1)
+			require.NoError(t, testPool.QueryRow(ctx, "SELECT count(*) FROM outbox WHERE owner = 'owner-2'").Scan(&events))
+			assert.Equal(t, 1, events)
+		})
+
+		t.Run("nothing left", func(t *testing.T) {
+			deletion, err := NewAccountRepository(testPool).DeleteOwnerData(ctx, "owner-1")
+			require.NoError(t, err)
+			assert.Empty(t, deletion.ShortUrls)
+			assert.Zero(t, deletion.Webhooks)
+		})
+	})
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	// ClickHours and ClickDays are how many hours and days of clicks the
+	// analytics of a link include.
+	ClickHours = 24
+	ClickDays  = 30
+
+	clickDayFormat = "2006-01-02"
+)
+
+// AnalyticsRepository records every click as a raw event and counts them by
+// class, referrer, browser, operating system and device in hourly, daily and
+// all-time aggregates. Analytics are read from the aggregates only, so they
+// lag behind the events until these are rolled up. Events and counts are
+// removed together with their link.
+type AnalyticsRepository interface {
+	RecordClick(ctx context.Context, click *model.Click) error
+	// GetClickAnalytics returns up to limit values of every breakdown and the
+	// human clicks of the last ClickHours hours and ClickDays days.
+	GetClickAnalytics(ctx context.Context, shortURL string, limit int32) (*model.ClickAnalytics, error)
+	// RollUpClicks adds the events recorded since the last rollup and more
+	// than settle ago, but no more than maxSpan of them, to the aggregates.
+	// It returns whether it caught up. Concurrent rollups wait for each other.
+	RollUpClicks(ctx context.Context, settle, maxSpan time.Duration) (bool, error)
+	// DeleteClickEvents deletes up to limit events recorded before before that
+	// have been rolled up.
+	DeleteClickEvents(ctx context.Context, before time.Time, limit int32) (int64, error)
+	DeleteHourlyClickStats(ctx context.Context, before time.Time) (int64, error)
+}
+
+type analyticsRepository struct {
+	querier db.Querier
+	tx      TxManager
+}
+
+func NewAnalyticsRepository(conn *pgxpool.Pool) AnalyticsRepository {
+	return &analyticsRepository{
+		querier: db.New(conn),
+		tx:      NewTxManager(conn),
+	}
+}
+
+func (r *analyticsRepository) RecordClick(ctx context.Context, click *model.Click) (err error) {
+	ctx, span := startSpan(ctx, "analyticsRepository.RecordClick", "InsertClickEvent")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).InsertClickEvent(ctx, db.InsertClickEventParams{
+		ShortUrl: click.ShortUrl,
+		Bot:      click.Bot,
+		Referrer: click.Referrer,
+		Browser:  click.Browser,
+		Os:       click.OS,
+		Device:   click.Device,
+	})
+}
+
+func (r *analyticsRepository) GetClickAnalytics(ctx context.Context, shortURL string, limit int32) (_ *model.ClickAnalytics, err error) {
+	ctx, span := startSpan(ctx, "analyticsRepository.GetClickAnalytics", "ListClickStats")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.q(ctx).ListClickStats(ctx, db.ListClickStatsParams{
+		ShortUrl:  shortURL,
+		MaxValues: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &model.ClickAnalytics{
+		Referrers: []model.ValueCount{},
+		Browsers:  []model.ValueCount{},
+		OSes:      []model.ValueCount{},
+		Devices:   []model.ValueCount{},
+	}
+	for _, row := range rows {
+		count := model.ValueCount{Value: row.Value, Count: row.Count}
+		switch row.Dimension {
+		case model.ClickDimensionClass:
+			if row.Value == model.ClickClassBot {
+				analytics.BotClicks = row.Count
+			}
+		case model.ClickDimensionReferrer:
+			analytics.Referrers = append(analytics.Referrers, count)
+		case model.ClickDimensionBrowser:
+			analytics.Browsers = append(analytics.Browsers, count)
+		case model.ClickDimensionOS:
+			analytics.OSes = append(analytics.OSes, count)
+		case model.ClickDimensionDevice:
+			analytics.Devices = append(analytics.Devices, count)
+		}
+	}
+
+	now := time.Now().UTC()
+	if analytics.Hourly, err = r.hourlyClicks(ctx, shortURL, now); err != nil {
+		return nil, err
+	}
+	if analytics.Daily, err = r.dailyClicks(ctx, shortURL, now); err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// hourlyClicks returns the clicks of the last ClickHours hours up to now,
+// oldest first, including the hours without any.
+func (r *analyticsRepository) hourlyClicks(ctx context.Context, shortURL string, now time.Time) ([]model.HourlyCount, error) {
+	first := now.Truncate(time.Hour).Add(-(ClickHours - 1) * time.Hour)
+	rows, err := r.q(ctx).ListHourlyClicks(ctx, db.ListHourlyClicksParams{
+		ShortUrl: shortURL,
+		Since:    pgtype.Timestamp{Time: first, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Hour.Time.Format(time.RFC3339)] = row.Count
+	}
+	hours := make([]model.HourlyCount, 0, ClickHours)
+	for i := range ClickHours {
+		hour := first.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+		hours = append(hours, model.HourlyCount{Hour: hour, Count: counts[hour]})
+	}
+	return hours, nil
+}
+
+// dailyClicks returns the clicks of the last ClickDays days up to now, oldest
+// first, including the days without any.
+func (r *analyticsRepository) dailyClicks(ctx context.Context, shortURL string, now time.Time) ([]model.DailyCount, error) {
+	first := startOfDay(now).AddDate(0, 0, -(ClickDays - 1))
+	rows, err := r.q(ctx).ListDailyClicks(ctx, db.ListDailyClicksParams{
+		ShortUrl: shortURL,
+		Since:    pgtype.Date{Time: first, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day.Time.Format(clickDayFormat)] = row.Count
+	}
+	days := make([]model.DailyCount, 0, ClickDays)
+	for i := range ClickDays {
+		day := first.AddDate(0, 0, i).Format(clickDayFormat)
+		days = append(days, model.DailyCount{Date: day, Count: counts[day]})
+	}
+	return days, nil
+}
+
+func (r *analyticsRepository) RollUpClicks(ctx context.Context, settle, maxSpan time.Duration) (caughtUp bool, err error) {
+	ctx, span := startSpan(ctx, "analyticsRepository.RollUpClicks", "RollUpClickEvents")
+	defer telemetry.End(span, &err)
+
+	err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		state, err := r.q(ctx).LockClickRollup(ctx)
+		if err != nil {
+			return err
+		}
+
+		upTo := state.Now.Time.Add(-settle)
+		caughtUp = true
+		if limit := state.RolledUpTo.Time.Add(maxSpan); upTo.After(limit) {
+			upTo, caughtUp = limit, false
+		}
+		if !upTo.After(state.RolledUpTo.Time) {
+			return nil
+		}
+
+		if err := r.q(ctx).RollUpClickEvents(ctx, db.RollUpClickEventsParams{
+			RolledUpTo: state.RolledUpTo,
+			UpTo:       pgtype.Timestamp{Time: upTo, Valid: true},
+		}); err != nil {
+			return err
+		}
+		return r.q(ctx).UpdateClickRollup(ctx, pgtype.Timestamp{Time: upTo, Valid: true})
+	})
+	return caughtUp, err
+}
+
+func (r *analyticsRepository) DeleteClickEvents(ctx context.Context, before time.Time, limit int32) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "analyticsRepository.DeleteClickEvents", "DeleteClickEvents")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).DeleteClickEvents(ctx, db.DeleteClickEventsParams{
+		Before:    pgtype.Timestamp{Time: before.UTC(), Valid: true},
+		BatchSize: limit,
+	})
+}
+
+func (r *analyticsRepository) DeleteHourlyClickStats(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "analyticsRepository.DeleteHourlyClickStats", "DeleteHourlyClickStats")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).DeleteHourlyClickStats(ctx, pgtype.Timestamp{Time: before.UTC(), Valid: true})
+}
+
+func (r *analyticsRepository) q(ctx context.Context) db.Querier {
+	if q, ok := txQuerier(ctx); ok {
+		return q
+	}
+	return r.querier
+}
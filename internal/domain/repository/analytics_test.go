@@ -0,0 +1,99 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestAnalyticsRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		_, err := (*urls).CreateURL(ctx, &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com"})
+		require.NoError(t, err)
+		repo := NewAnalyticsRepository(testPool)
+
+		rollUp := func() {
+			for {
+				caughtUp, err := repo.RollUpClicks(ctx, 0, 1000*time.Hour)
+				require.NoError(t, err)
+				if caughtUp {
+					return
+				}
+			}
+		}
+		// Events recorded before this test must not show up in its counts.
+		rollUp()
+
+		clicks := []*model.Click{
+			{ShortUrl: "ab", Referrer: "direct", Browser: model.BrowserFirefox, OS: model.OSLinux, Device: model.DeviceDesktop},
+			{ShortUrl: "ab", Referrer: "news.example.org", Browser: model.BrowserFirefox, OS: model.OSLinux, Device: model.DeviceDesktop},
+			{ShortUrl: "ab", Referrer: "news.example.org", Browser: model.BrowserSafari, OS: model.OSiOS, Device: model.DeviceMobile},
+			{ShortUrl: "ab", Bot: true, Referrer: "direct", Browser: "other", OS: "other", Device: model.DeviceBot},
+		}
+		for _, click := range clicks {
+			require.NoError(t, repo.RecordClick(ctx, click))
+		}
+
+		t.Run("served from rollups only", func(t *testing.T) {
+			analytics, err := repo.GetClickAnalytics(ctx, "ab", 10)
+			require.NoError(t, err)
+			assert.Zero(t, analytics.BotClicks)
+			assert.Empty(t, analytics.Referrers)
+		})
+
+		rollUp()
+		// Rolling up again must not count events twice.
+		rollUp()
+
+		analytics, err := repo.GetClickAnalytics(ctx, "ab", 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), analytics.BotClicks)
+		assert.Equal(t, []model.ValueCount{{Value: "news.example.org", Count: 2}, {Value: "direct", Count: 1}}, analytics.Referrers)
+		assert.Equal(t, []model.ValueCount{{Value: model.BrowserFirefox, Count: 2}, {Value: model.BrowserSafari, Count: 1}}, analytics.Browsers)
+		assert.Equal(t, []model.ValueCount{{Value: model.DeviceDesktop, Count: 2}, {Value: model.DeviceMobile, Count: 1}}, analytics.Devices)
+		require.Len(t, analytics.Hourly, ClickHours)
+		assert.Equal(t, int64(3), analytics.Hourly[ClickHours-1].Count)
+		require.Len(t, analytics.Daily, ClickDays)
+		assert.Equal(t, int64(3), analytics.Daily[ClickDays-1].Count)
+		assert.Equal(t, time.Now().UTC().Format("2006-01-02"), analytics.Daily[ClickDays-1].Date)
+
+		t.Run("limit", func(t *testing.T) {
+			analytics, err := repo.GetClickAnalytics(ctx, "ab", 1)
+			require.NoError(t, err)
+			assert.Equal(t, []model.ValueCount{{Value: "news.example.org", Count: 2}}, analytics.Referrers)
+			assert.Equal(t, int64(1), analytics.BotClicks)
+		})
+
+		t.Run("unknown link", func(t *testing.T) {
+			analytics, err := repo.GetClickAnalytics(ctx, "missing", 10)
+			require.NoError(t, err)
+			assert.Zero(t, analytics.BotClicks)
+			assert.Empty(t, analytics.Referrers)
+		})
+
+		t.Run("prune", func(t *testing.T) {
+			require.NoError(t, repo.RecordClick(ctx, clicks[0]))
+
+			deleted, err := repo.DeleteClickEvents(ctx, time.Now().Add(time.Hour), 100)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(clicks)), deleted, "events that are not rolled up are kept")
+
+			deleted, err = repo.DeleteHourlyClickStats(ctx, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			assert.Positive(t, deleted)
+
+			analytics, err := repo.GetClickAnalytics(ctx, "ab", 10)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), analytics.Daily[ClickDays-1].Count, "daily counts are kept")
+		})
+	})
+}
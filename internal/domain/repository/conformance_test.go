@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// runConformanceSuite checks the behaviour every URLRepository implementation
+// must share. newRepo must return an empty repository.
+func runConformanceSuite(t *testing.T, newRepo func(t *testing.T) URLRepository) {
+	ctx := context.Background()
+
+	create := func(t *testing.T, repo URLRepository, codes ...string) {
+		t.Helper()
+		for _, code := range codes {
+			_, err := repo.CreateURL(ctx, &db.CreateUrlParams{
+				OriginalUrl: "https://google.com/" + code,
+				ShortUrl:    code,
+			})
+			require.NoError(t, err)
+		}
+	}
+	codes := func(urls []*model.Url) []string {
+		result := make([]string, 0, len(urls))
+		for _, url := range urls {
+			result = append(result, url.ShortUrl)
+		}
+		return result
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repo := newRepo(t)
+
+		created, err := repo.CreateURL(ctx, &db.CreateUrlParams{OriginalUrl: "https://google.com", ShortUrl: "exmpl"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", created.OriginalUrl)
+		assert.Equal(t, "exmpl", created.ShortUrl)
+		_, err = time.Parse(time.RFC3339, created.CreatedAt)
+		assert.NoError(t, err)
+
+		fetched, err := repo.GetURLByShortened(ctx, "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", fetched.OriginalUrl)
+		assert.Equal(t, int64(0), fetched.ClickCount)
+		assert.Equal(t, created.CreatedAt, fetched.CreatedAt)
+	})
+
+	t.Run("create duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "exmpl")
+
+		_, err := repo.CreateURL(ctx, &db.CreateUrlParams{OriginalUrl: "https://bing.com", ShortUrl: "exmpl"})
+		assert.ErrorIs(t, err, ErrURLAlreadyExists)
+
+		fetched, err := repo.GetURLByShortened(ctx, "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com/exmpl", fetched.OriginalUrl)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetURLByShortened(ctx, "missing")
+		assert.ErrorIs(t, err, ErrURLNotFound)
+	})
+
+	t.Run("increment click count", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "exmpl")
+
+		require.NoError(t, repo.IncrementClickCount(ctx, "exmpl"))
+		require.NoError(t, repo.IncrementClickCount(ctx, "exmpl"))
+
+		fetched, err := repo.GetURLByShortened(ctx, "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), fetched.ClickCount)
+
+		assert.ErrorIs(t, repo.IncrementClickCount(ctx, "missing"), ErrURLNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "exmpl", "other")
+
+		require.NoError(t, repo.DeleteURL(ctx, "exmpl"))
+		_, err := repo.GetURLByShortened(ctx, "exmpl")
+		assert.ErrorIs(t, err, ErrURLNotFound)
+		assert.ErrorIs(t, repo.DeleteURL(ctx, "exmpl"), ErrURLNotFound)
+
+		urls, err := repo.ListURLs(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, codes(urls))
+		urls, err = repo.ListTopURLs(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, codes(urls))
+	})
+
+	t.Run("list in creation order", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "ccc", "aaa", "bbb")
+
+		urls, err := repo.ListURLs(ctx, 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ccc", "aaa"}, codes(urls))
+
+		urls, err = repo.ListURLs(ctx, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bbb"}, codes(urls))
+
+		urls, err = repo.ListURLs(ctx, 2, 5)
+		require.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+
+	t.Run("list after cursor", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "ccc", "aaa", "bbb")
+
+		urls, err := repo.ListURLsAfter(ctx, "", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"aaa", "bbb"}, codes(urls))
+
+		urls, err = repo.ListURLsAfter(ctx, "bbb", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ccc"}, codes(urls))
+	})
+
+	t.Run("list most clicked", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "aaa", "bbb", "ccc", "ddd")
+		for code, clicks := range map[string]int{"aaa": 1, "bbb": 3, "ccc": 1} {
+			for range clicks {
+				require.NoError(t, repo.IncrementClickCount(ctx, code))
+			}
+		}
+
+		urls, err := repo.ListTopURLs(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bbb", "aaa", "ccc"}, codes(urls))
+		assert.Equal(t, int64(3), urls[0].ClickCount)
+	})
+
+	t.Run("import preserves fields", func(t *testing.T) {
+		repo := newRepo(t)
+		imported := &model.Url{
+			OriginalUrl: "https://google.com",
+			ShortUrl:    "exmpl",
+			ClickCount:  42,
+			CreatedAt:   "2024-01-02T03:04:05Z",
+			UpdatedAt:   "2024-02-03T04:05:06Z",
+		}
+
+		require.NoError(t, repo.ImportURL(ctx, imported, false))
+
+		fetched, err := repo.GetURLByShortened(ctx, "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, imported, fetched)
+
+		urls, err := repo.ListTopURLs(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"exmpl"}, codes(urls))
+	})
+
+	t.Run("import conflict", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "exmpl", "other")
+
+		err := repo.ImportURL(ctx, &model.Url{OriginalUrl: "https://bing.com", ShortUrl: "exmpl"}, false)
+		assert.ErrorIs(t, err, ErrURLAlreadyExists)
+
+		err = repo.ImportURL(ctx, &model.Url{OriginalUrl: "https://bing.com", ShortUrl: "exmpl", ClickCount: 5}, true)
+		require.NoError(t, err)
+
+		fetched, err := repo.GetURLByShortened(ctx, "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, "https://bing.com", fetched.OriginalUrl)
+		assert.Equal(t, int64(5), fetched.ClickCount)
+
+		urls, err := repo.ListURLs(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"exmpl", "other"}, codes(urls))
+	})
+
+	t.Run("import rejects invalid timestamps", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.ImportURL(ctx, &model.Url{OriginalUrl: "https://google.com", ShortUrl: "exmpl", CreatedAt: "yesterday"}, false)
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryURLRepository(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) URLRepository {
+		return NewMemoryURLRepository()
+	})
+}
+
+func TestSQLiteURLRepository(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) URLRepository {
+		conn, err := OpenSQLite(context.Background(), t.TempDir()+"/urls.db")
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+		return NewSQLiteURLRepository(conn)
+	})
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// DashboardRepository aggregates the links of an owner. Clicks within a window
+// are read from the click aggregates, so they lag behind like analytics do.
+type DashboardRepository interface {
+	// GetDashboard ranks links by their clicks in the last window, counted in
+	// whole hours up to ClickHours and in whole days beyond, and returns up to
+	// limit links in every list.
+	GetDashboard(ctx context.Context, owner string, window time.Duration, limit int32) (*model.Dashboard, error)
+}
+
+type dashboardRepository struct {
+	*replicaSet
+}
+
+// NewDashboardRepository reads from replicas like NewURLRepository does,
+// falling back to primary when none is healthy or a replica fails.
+func NewDashboardRepository(primary *pgxpool.Pool, replicas ...*Replica) DashboardRepository {
+	return &dashboardRepository{
+		replicaSet: newReplicaSet(db.New(primary), replicas),
+	}
+}
+
+func (r *dashboardRepository) GetDashboard(ctx context.Context, owner string, window time.Duration, limit int32) (_ *model.Dashboard, err error) {
+	ctx, span := startSpan(ctx, "dashboardRepository.GetDashboard", "GetOwnerLinkTotals")
+	defer telemetry.End(span, &err)
+
+	// The whole dashboard is read from one source, so that its numbers agree.
+	var dashboard *model.Dashboard
+	err = r.read(ctx, func(q db.Querier) error {
+		var err error
+		dashboard, err = getDashboard(ctx, q, owner, window, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+func getDashboard(ctx context.Context, q db.Querier, owner string, window time.Duration, limit int32) (*model.Dashboard, error) {
+	totals, err := q.GetOwnerLinkTotals(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := &model.Dashboard{
+		TotalLinks:         totals.Links,
+		TotalClicks:        totals.Clicks,
+		ZeroClickLinkCount: totals.UnclickedLinks,
+		NewestLinks:        []*model.Url{},
+		ZeroClickLinks:     []*model.Url{},
+	}
+
+	if dashboard.TopLinks, err = topLinks(ctx, q, owner, window, limit); err != nil {
+		return nil, err
+	}
+
+	newest, err := q.ListOwnerNewestLinks(ctx, db.ListOwnerNewestLinksParams{
+		Owner:      owner,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range newest {
+		dashboard.NewestLinks = append(dashboard.NewestLinks, toDashboardLink(row.ShortUrl, row.OriginalUrl, row.ClickCount, row.CreatedAt))
+	}
+
+	unclicked, err := q.ListOwnerUnclickedLinks(ctx, db.ListOwnerUnclickedLinksParams{
+		Owner:      owner,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range unclicked {
+		dashboard.ZeroClickLinks = append(dashboard.ZeroClickLinks, toDashboardLink(row.ShortUrl, row.OriginalUrl, row.ClickCount, row.CreatedAt))
+	}
+	return dashboard, nil
+}
+
+func topLinks(ctx context.Context, q db.Querier, owner string, window time.Duration, limit int32) ([]*model.TopLink, error) {
+	now := time.Now().UTC()
+	links := []*model.TopLink{}
+
+	if hours := int(window / time.Hour); hours <= ClickHours {
+		rows, err := q.ListOwnerTopLinksByHour(ctx, db.ListOwnerTopLinksByHourParams{
+			Owner:      owner,
+			Since:      pgtype.Timestamp{Time: now.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour), Valid: true},
+			MaxResults: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			links = append(links, &model.TopLink{
+				Url:          *toDashboardLink(row.ShortUrl, row.OriginalUrl, row.ClickCount, row.CreatedAt),
+				WindowClicks: row.WindowClicks,
+			})
+		}
+		return links, nil
+	}
+
+	days := int(window / (24 * time.Hour))
+	rows, err := q.ListOwnerTopLinksByDay(ctx, db.ListOwnerTopLinksByDayParams{
+		Owner:      owner,
+		Since:      pgtype.Date{Time: startOfDay(now).AddDate(0, 0, -(days - 1)), Valid: true},
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		links = append(links, &model.TopLink{
+			Url:          *toDashboardLink(row.ShortUrl, row.OriginalUrl, row.ClickCount, row.CreatedAt),
+			WindowClicks: row.WindowClicks,
+		})
+	}
+	return links, nil
+}
+
+func toDashboardLink(shortURL, originalURL string, clickCount int64, createdAt pgtype.Timestamp) *model.Url {
+	return &model.Url{
+		ShortUrl:    shortURL,
+		OriginalUrl: originalURL,
+		ClickCount:  clickCount,
+		CreatedAt:   createdAt.Time.Format(time.RFC3339),
+	}
+}
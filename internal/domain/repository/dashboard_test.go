@@ -0,0 +1,92 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestDashboardRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		owner := pgtype.Text{String: "owner-1", Valid: true}
+		for _, params := range []*db.CreateUrlParams{
+			{ShortUrl: "hot", OriginalUrl: "https://example.com/hot", Owner: owner},
+			{ShortUrl: "warm", OriginalUrl: "https://example.com/warm", Owner: owner},
+			{ShortUrl: "cold", OriginalUrl: "https://example.com/cold", Owner: owner},
+			{ShortUrl: "other", OriginalUrl: "https://example.com/other", Owner: pgtype.Text{String: "owner-2", Valid: true}},
+		} {
+			_, err := (*urls).CreateURL(ctx, params)
+			require.NoError(t, err)
+		}
+
+		analytics := NewAnalyticsRepository(testPool)
+		record := func(shortURL string, times int) {
+			for range times {
+				require.NoError(t, (*urls).IncrementClickCount(ctx, shortURL))
+				require.NoError(t, analytics.RecordClick(ctx, &model.Click{ShortUrl: shortURL}))
+			}
+		}
+		record("hot", 3)
+		record("warm", 1)
+		record("other", 5)
+		require.NoError(t, analytics.RecordClick(ctx, &model.Click{ShortUrl: "warm", Bot: true}))
+		for {
+			caughtUp, err := analytics.RollUpClicks(ctx, 0, 1000*time.Hour)
+			require.NoError(t, err)
+			if caughtUp {
+				break
+			}
+		}
+
+		repo := NewDashboardRepository(testPool)
+		for _, window := range []time.Duration{24 * time.Hour, 7 * 24 * time.Hour} {
+			dashboard, err := repo.GetDashboard(ctx, "owner-1", window, 10)
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(3), dashboard.TotalLinks)
+			assert.Equal(t, int64(4), dashboard.TotalClicks)
+			assert.Equal(t, int64(1), dashboard.ZeroClickLinkCount)
+			require.Len(t, dashboard.TopLinks, 2, "window %s", window)
+			assert.Equal(t, "hot", dashboard.TopLinks[0].ShortUrl)
+			assert.Equal(t, int64(3), dashboard.TopLinks[0].WindowClicks)
+			assert.Equal(t, "warm", dashboard.TopLinks[1].ShortUrl)
+			assert.Equal(t, int64(1), dashboard.TopLinks[1].WindowClicks, "bots are not counted")
+			assert.Equal(t, []string{"cold", "warm", "hot"}, shortURLs(dashboard.NewestLinks))
+			assert.Equal(t, []string{"cold"}, shortURLs(dashboard.ZeroClickLinks))
+		}
+
+		t.Run("limit", func(t *testing.T) {
+			dashboard, err := repo.GetDashboard(ctx, "owner-1", 24*time.Hour, 1)
+			require.NoError(t, err)
+			assert.Len(t, dashboard.TopLinks, 1)
+			assert.Len(t, dashboard.NewestLinks, 1)
+		})
+
+		t.Run("owner without links", func(t *testing.T) {
+			dashboard, err := repo.GetDashboard(ctx, "owner-3", 24*time.Hour, 10)
+			require.NoError(t, err)
+			assert.Zero(t, dashboard.TotalLinks)
+			assert.Zero(t, dashboard.TotalClicks)
+			assert.Empty(t, dashboard.TopLinks)
+			assert.Empty(t, dashboard.NewestLinks)
+		})
+	})
+}
+
+func shortURLs(links []*model.Url) []string {
+	codes := make([]string, 0, len(links))
+	for _, link := range links {
+		codes = append(codes, link.ShortUrl)
+	}
+	return codes
+}
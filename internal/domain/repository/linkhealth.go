@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// LinkHealthRepository stores the outcome of checking the destinations of
+// links. Results only apply to the destination that was checked, so a link
+// that is pointed elsewhere is healthy until it is checked again.
+type LinkHealthRepository interface {
+	// ClaimLinkChecks leases up to limit links that were never checked or
+	// whose next check is due, least recently checked first.
+	ClaimLinkChecks(ctx context.Context, limit int32, lease time.Duration) ([]*model.LinkCheck, error)
+	// RecordLinkCheck stores the outcome of a check and schedules the next
+	// one after recheckIn.
+	RecordLinkCheck(ctx context.Context, result *model.LinkCheckResult, recheckIn time.Duration) error
+	// GetLinkHealth returns nil if the destination of shortURL has not been
+	// checked yet.
+	GetLinkHealth(ctx context.Context, shortURL string) (*model.LinkHealth, error)
+	// ListBrokenLinks returns up to limit broken links of owner, those
+	// failing for longest first.
+	ListBrokenLinks(ctx context.Context, owner string, limit int32) ([]*model.LinkHealth, error)
+	// ListBrokenShortURLs returns the codes of all broken links.
+	ListBrokenShortURLs(ctx context.Context) ([]string, error)
+}
+
+type linkHealthRepository struct {
+	querier db.Querier
+}
+
+func NewLinkHealthRepository(conn *pgxpool.Pool) LinkHealthRepository {
+	return &linkHealthRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *linkHealthRepository) ClaimLinkChecks(ctx context.Context, limit int32, lease time.Duration) (_ []*model.LinkCheck, err error) {
+	ctx, span := startSpan(ctx, "linkHealthRepository.ClaimLinkChecks", "ClaimLinkChecks")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ClaimLinkChecks(ctx, db.ClaimLinkChecksParams{
+		BatchSize:    limit,
+		LeaseSeconds: lease.Seconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]*model.LinkCheck, 0, len(rows))
+	for _, row := range rows {
+		checks = append(checks, &model.LinkCheck{
+			ShortUrl:    row.ShortUrl,
+			OriginalUrl: row.OriginalUrl,
+		})
+	}
+	return checks, nil
+}
+
+func (r *linkHealthRepository) RecordLinkCheck(ctx context.Context, result *model.LinkCheckResult, recheckIn time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "linkHealthRepository.RecordLinkCheck", "RecordLinkCheck")
+	defer telemetry.End(span, &err)
+
+	return r.querier.RecordLinkCheck(ctx, db.RecordLinkCheckParams{
+		CheckedUrl:     result.CheckedUrl,
+		StatusCode:     int32(result.StatusCode),
+		Error:          result.Error,
+		Healthy:        result.Healthy,
+		RecheckSeconds: recheckIn.Seconds(),
+		ShortUrl:       result.ShortUrl,
+	})
+}
+
+func (r *linkHealthRepository) GetLinkHealth(ctx context.Context, shortURL string) (_ *model.LinkHealth, err error) {
+	ctx, span := startSpan(ctx, "linkHealthRepository.GetLinkHealth", "GetLinkCheck")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.GetLinkCheck(ctx, shortURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toLinkHealth(row.ShortUrl, row.OriginalUrl, row.StatusCode, row.Error, row.Failures, row.CheckedAt), nil
+}
+
+func (r *linkHealthRepository) ListBrokenLinks(ctx context.Context, owner string, limit int32) (_ []*model.LinkHealth, err error) {
+	ctx, span := startSpan(ctx, "linkHealthRepository.ListBrokenLinks", "ListBrokenLinks")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ListBrokenLinks(ctx, db.ListBrokenLinksParams{
+		Owner:       owner,
+		MinFailures: model.BrokenAfterFailures,
+		MaxResults:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]*model.LinkHealth, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, toLinkHealth(row.ShortUrl, row.OriginalUrl, row.StatusCode, row.Error, row.Failures, row.CheckedAt))
+	}
+	return links, nil
+}
+
+func (r *linkHealthRepository) ListBrokenShortURLs(ctx context.Context) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "linkHealthRepository.ListBrokenShortURLs", "ListBrokenShortUrls")
+	defer telemetry.End(span, &err)
+
+	return r.querier.ListBrokenShortUrls(ctx, model.BrokenAfterFailures)
+}
+
+func toLinkHealth(shortURL, originalURL string, statusCode int32, cause string, failures int32, checkedAt pgtype.Timestamp) *model.LinkHealth {
+	return &model.LinkHealth{
+		ShortUrl:    shortURL,
+		OriginalUrl: originalURL,
+		StatusCode:  int(statusCode),
+		Error:       cause,
+		Failures:    int(failures),
+		Broken:      failures >= model.BrokenAfterFailures,
+		CheckedAt:   checkedAt.Time.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,98 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestLinkHealthRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		for _, params := range []*db.CreateUrlParams{
+			{ShortUrl: "ok", OriginalUrl: "https://example.com/ok", Owner: pgtype.Text{String: "owner-1", Valid: true}},
+			{ShortUrl: "gone", OriginalUrl: "https://example.com/gone", Owner: pgtype.Text{String: "owner-1", Valid: true}},
+		} {
+			_, err := (*urls).CreateURL(ctx, params)
+			require.NoError(t, err)
+		}
+		repo := NewLinkHealthRepository(testPool)
+
+		checks, err := repo.ClaimLinkChecks(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*model.LinkCheck{
+			{ShortUrl: "ok", OriginalUrl: "https://example.com/ok"},
+			{ShortUrl: "gone", OriginalUrl: "https://example.com/gone"},
+		}, checks)
+		checks, err = repo.ClaimLinkChecks(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, checks, "claimed links are leased")
+
+		health, err := repo.GetLinkHealth(ctx, "ok")
+		require.NoError(t, err)
+		assert.Nil(t, health, "not checked yet")
+
+		require.NoError(t, repo.RecordLinkCheck(ctx, &model.LinkCheckResult{
+			ShortUrl: "ok", CheckedUrl: "https://example.com/ok", StatusCode: 200, Healthy: true,
+		}, time.Hour))
+		for range model.BrokenAfterFailures {
+			require.NoError(t, repo.RecordLinkCheck(ctx, &model.LinkCheckResult{
+				ShortUrl: "gone", CheckedUrl: "https://example.com/gone", StatusCode: 404, Error: "404 Not Found",
+			}, 0))
+		}
+
+		health, err = repo.GetLinkHealth(ctx, "ok")
+		require.NoError(t, err)
+		require.NotNil(t, health)
+		assert.Equal(t, 200, health.StatusCode)
+		assert.False(t, health.Broken)
+
+		broken, err := repo.ListBrokenLinks(ctx, "owner-2", 10)
+		require.NoError(t, err)
+		assert.Empty(t, broken, "links of other owners are not listed")
+
+		broken, err = repo.ListBrokenLinks(ctx, "owner-1", 10)
+		require.NoError(t, err)
+		require.Len(t, broken, 1)
+		assert.Equal(t, "gone", broken[0].ShortUrl)
+		assert.Equal(t, 404, broken[0].StatusCode)
+		assert.Equal(t, model.BrokenAfterFailures, broken[0].Failures)
+		assert.True(t, broken[0].Broken)
+
+		codes, err := repo.ListBrokenShortURLs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gone"}, codes)
+
+		checks, err = repo.ClaimLinkChecks(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.LinkCheck{{ShortUrl: "gone", OriginalUrl: "https://example.com/gone"}}, checks, "only due links are claimed")
+
+		t.Run("a new destination is not broken", func(t *testing.T) {
+			require.NoError(t, (*urls).ImportURL(ctx, &model.Url{ShortUrl: "gone", OriginalUrl: "https://example.com/moved"}, true))
+
+			codes, err := repo.ListBrokenShortURLs(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, codes)
+			health, err := repo.GetLinkHealth(ctx, "gone")
+			require.NoError(t, err)
+			assert.Nil(t, health)
+
+			require.NoError(t, repo.RecordLinkCheck(ctx, &model.LinkCheckResult{
+				ShortUrl: "gone", CheckedUrl: "https://example.com/moved", Error: "connection refused",
+			}, 0))
+			health, err = repo.GetLinkHealth(ctx, "gone")
+			require.NoError(t, err)
+			require.NotNil(t, health)
+			assert.Equal(t, 1, health.Failures, "failures of the old destination are not carried over")
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// memoryURLRepository keeps links in process memory. It is meant for tests and
+// demos; everything is lost when the process exits.
+type memoryURLRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	urls   map[string]*memoryURL
+}
+
+type memoryURL struct {
+	id          int64
+	originalURL string
+	shortURL    string
+	owner       string
+	clickCount  int64
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func NewMemoryURLRepository() URLRepository {
+	return &memoryURLRepository{
+		urls: make(map[string]*memoryURL),
+	}
+}
+
+func (r *memoryURLRepository) CreateURL(_ context.Context, url *db.CreateUrlParams) (*model.Url, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.urls[url.ShortUrl]; ok {
+		return nil, ErrURLAlreadyExists
+	}
+
+	now := time.Now().UTC()
+	r.nextID++
+	stored := &memoryURL{
+		id:          r.nextID,
+		originalURL: url.OriginalUrl,
+		shortURL:    url.ShortUrl,
+		owner:       url.Owner.String,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+	r.urls[url.ShortUrl] = stored
+	return stored.toModel(), nil
+}
+
+func (r *memoryURLRepository) GetURLByShortened(_ context.Context, shortened string) (*model.Url, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.urls[shortened]
+	if !ok {
+		return nil, ErrURLNotFound
+	}
+	return stored.toModel(), nil
+}
+
+func (r *memoryURLRepository) IncrementClickCount(_ context.Context, shortened string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.urls[shortened]
+	if !ok {
+		return ErrURLNotFound
+	}
+	stored.clickCount++
+	stored.updatedAt = time.Now().UTC()
+	return nil
+}
+
+func (r *memoryURLRepository) DeleteURL(_ context.Context, shortened string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.urls[shortened]; !ok {
+		return ErrURLNotFound
+	}
+	delete(r.urls, shortened)
+	return nil
+}
+
+func (r *memoryURLRepository) ListURLs(_ context.Context, limit, offset int32) ([]*model.Url, error) {
+	return r.list(func(a, b *memoryURL) int {
+		return cmp.Compare(a.id, b.id)
+	}, nil, int(offset), int(limit)), nil
+}
+
+func (r *memoryURLRepository) ListURLsAfter(_ context.Context, after string, limit int32) ([]*model.Url, error) {
+	return r.list(func(a, b *memoryURL) int {
+		return cmp.Compare(a.shortURL, b.shortURL)
+	}, func(u *memoryURL) bool {
+		return u.shortURL > after
+	}, 0, int(limit)), nil
+}
+
+func (r *memoryURLRepository) ListTopURLs(_ context.Context, limit int32) ([]*model.Url, error) {
+	return r.list(func(a, b *memoryURL) int {
+		return cmp.Or(cmp.Compare(b.clickCount, a.clickCount), cmp.Compare(a.shortURL, b.shortURL))
+	}, nil, 0, int(limit)), nil
+}
+
+func (r *memoryURLRepository) ImportURL(_ context.Context, url *model.Url, overwrite bool) error {
+	createdAt, err := parseTimestamp(url.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updatedAt, err := parseTimestamp(url.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.urls[url.ShortUrl]
+	if exists && !overwrite {
+		return ErrURLAlreadyExists
+	}
+	if !exists {
+		r.nextID++
+		stored = &memoryURL{id: r.nextID, shortURL: url.ShortUrl}
+		r.urls[url.ShortUrl] = stored
+	}
+	stored.originalURL = url.OriginalUrl
+	if url.Owner != "" {
+		stored.owner = url.Owner
+	}
+	stored.clickCount = url.ClickCount
+	stored.createdAt = createdAt.Time
+	stored.updatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *memoryURLRepository) list(compare func(a, b *memoryURL) int, keep func(*memoryURL) bool, offset, limit int) []*model.Url {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*memoryURL, 0, len(r.urls))
+	for _, stored := range r.urls {
+		if keep == nil || keep(stored) {
+			matched = append(matched, stored)
+		}
+	}
+	slices.SortFunc(matched, compare)
+
+	urls := make([]*model.Url, 0, limit)
+	for i := offset; i < len(matched) && len(urls) < limit; i++ {
+		urls = append(urls, matched[i].toModel())
+	}
+	return urls
+}
+
+func (u *memoryURL) toModel() *model.Url {
+	return &model.Url{
+		OriginalUrl: u.originalURL,
+		ShortUrl:    u.shortURL,
+		Owner:       u.owner,
+		ClickCount:  u.clickCount,
+		CreatedAt:   u.createdAt.Format(time.RFC3339),
+		UpdatedAt:   u.updatedAt.Format(time.RFC3339),
+	}
+}
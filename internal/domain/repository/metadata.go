@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// LinkMetadataRepository stores the metadata read from the destinations of
+// links. Metadata only applies to the destination it was read from.
+type LinkMetadataRepository interface {
+	// SaveLinkMetadata replaces the metadata of a link.
+	SaveLinkMetadata(ctx context.Context, metadata *model.LinkMetadata) error
+	// GetLinkMetadata returns nil if no metadata was read from the current
+	// destination of shortURL.
+	GetLinkMetadata(ctx context.Context, shortURL string) (*model.LinkMetadata, error)
+}
+
+type linkMetadataRepository struct {
+	querier db.Querier
+}
+
+func NewLinkMetadataRepository(conn *pgxpool.Pool) LinkMetadataRepository {
+	return &linkMetadataRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *linkMetadataRepository) SaveLinkMetadata(ctx context.Context, metadata *model.LinkMetadata) (err error) {
+	ctx, span := startSpan(ctx, "linkMetadataRepository.SaveLinkMetadata", "UpsertLinkMetadata")
+	defer telemetry.End(span, &err)
+
+	openGraph := metadata.OpenGraph
+	if openGraph == nil {
+		openGraph = map[string]string{}
+	}
+	properties, err := json.Marshal(openGraph)
+	if err != nil {
+		return err
+	}
+	return r.querier.UpsertLinkMetadata(ctx, db.UpsertLinkMetadataParams{
+		ShortUrl:    metadata.ShortUrl,
+		FetchedUrl:  metadata.FetchedUrl,
+		Title:       metadata.Title,
+		Description: metadata.Description,
+		FaviconUrl:  metadata.FaviconUrl,
+		OpenGraph:   properties,
+		Error:       metadata.Error,
+	})
+}
+
+func (r *linkMetadataRepository) GetLinkMetadata(ctx context.Context, shortURL string) (_ *model.LinkMetadata, err error) {
+	ctx, span := startSpan(ctx, "linkMetadataRepository.GetLinkMetadata", "GetLinkMetadata")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.GetLinkMetadata(ctx, shortURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &model.LinkMetadata{
+		ShortUrl:    row.ShortUrl,
+		FetchedUrl:  row.FetchedUrl,
+		Title:       row.Title,
+		Description: row.Description,
+		FaviconUrl:  row.FaviconUrl,
+		Error:       row.Error,
+		FetchedAt:   row.FetchedAt.Time.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal(row.OpenGraph, &metadata.OpenGraph); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
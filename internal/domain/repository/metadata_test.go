@@ -0,0 +1,62 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestLinkMetadataRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		_, err := (*urls).CreateURL(ctx, &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com/a"})
+		require.NoError(t, err)
+		repo := NewLinkMetadataRepository(testPool)
+
+		metadata, err := repo.GetLinkMetadata(ctx, "ab")
+		require.NoError(t, err)
+		assert.Nil(t, metadata, "not fetched yet")
+
+		require.NoError(t, repo.SaveLinkMetadata(ctx, &model.LinkMetadata{
+			ShortUrl:    "ab",
+			FetchedUrl:  "https://example.com/a",
+			Title:       "Example",
+			Description: "An example page",
+			FaviconUrl:  "https://example.com/favicon.ico",
+			OpenGraph:   map[string]string{"title": "Example", "image": "https://example.com/a.png"},
+		}))
+		metadata, err = repo.GetLinkMetadata(ctx, "ab")
+		require.NoError(t, err)
+		require.NotNil(t, metadata)
+		assert.Equal(t, "Example", metadata.Title)
+		assert.Equal(t, "An example page", metadata.Description)
+		assert.Equal(t, "https://example.com/favicon.ico", metadata.FaviconUrl)
+		assert.Equal(t, map[string]string{"title": "Example", "image": "https://example.com/a.png"}, metadata.OpenGraph)
+		assert.NotEmpty(t, metadata.FetchedAt)
+
+		require.NoError(t, repo.SaveLinkMetadata(ctx, &model.LinkMetadata{
+			ShortUrl: "ab", FetchedUrl: "https://example.com/a", Error: "page is not HTML",
+		}))
+		metadata, err = repo.GetLinkMetadata(ctx, "ab")
+		require.NoError(t, err)
+		require.NotNil(t, metadata)
+		assert.Empty(t, metadata.Title, "metadata is replaced")
+		assert.Empty(t, metadata.OpenGraph)
+		assert.Equal(t, "page is not HTML", metadata.Error)
+
+		t.Run("a new destination has no metadata", func(t *testing.T) {
+			require.NoError(t, (*urls).ImportURL(ctx, &model.Url{ShortUrl: "ab", OriginalUrl: "https://example.com/moved"}, true))
+
+			metadata, err := repo.GetLinkMetadata(ctx, "ab")
+			require.NoError(t, err)
+			assert.Nil(t, metadata)
+		})
+	})
+}
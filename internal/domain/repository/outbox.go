@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// OutboxRepository stores domain events until they are delivered. Events are
+// added in the transaction of the change they describe, so they are recorded
+// if and only if that change is committed.
+type OutboxRepository interface {
+	AddEvent(ctx context.Context, event *model.Event) error
+	// ClaimEvents leases up to limit pending events for lease. Events not
+	// marked delivered or failed before the lease ends are claimed again.
+	ClaimEvents(ctx context.Context, limit int32, lease time.Duration, maxAttempts int32) ([]*model.Event, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, cause string, retryIn time.Duration) error
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	querier db.Querier
+}
+
+func NewOutboxRepository(conn *pgxpool.Pool) OutboxRepository {
+	return &outboxRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *outboxRepository) AddEvent(ctx context.Context, event *model.Event) (err error) {
+	ctx, span := startSpan(ctx, "outboxRepository.AddEvent", "InsertOutboxEvent")
+	defer telemetry.End(span, &err)
+
+	row, err := r.q(ctx).InsertOutboxEvent(ctx, db.InsertOutboxEventParams{
+		EventType: event.Type,
+		ShortUrl:  event.ShortUrl,
+		Payload:   event.Data,
+	})
+	if err != nil {
+		return err
+	}
+	event.ID = row.ID
+	event.Owner = row.Owner.String
+	event.OccurredAt = row.OccurredAt.Time.Format(time.RFC3339)
+	return nil
+}
+
+func (r *outboxRepository) ClaimEvents(ctx context.Context, limit int32, lease time.Duration, maxAttempts int32) (_ []*model.Event, err error) {
+	ctx, span := startSpan(ctx, "outboxRepository.ClaimEvents", "ClaimOutboxEvents")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.q(ctx).ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+		LeaseSeconds: lease.Seconds(),
+		MaxAttempts:  maxAttempts,
+		BatchSize:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &model.Event{
+			ID:         row.ID,
+			Type:       row.EventType,
+			ShortUrl:   row.ShortUrl,
+			Owner:      row.Owner.String,
+			Data:       row.Payload,
+			OccurredAt: row.OccurredAt.Time.Format(time.RFC3339),
+			Attempts:   int(row.Attempts),
+		})
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "outboxRepository.MarkDelivered", "MarkOutboxEventDelivered")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).MarkOutboxEventDelivered(ctx, id)
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, cause string, retryIn time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "outboxRepository.MarkFailed", "MarkOutboxEventFailed")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		LastError:    pgtype.Text{String: cause, Valid: true},
+		RetrySeconds: retryIn.Seconds(),
+		ID:           id,
+	})
+}
+
+func (r *outboxRepository) DeleteDelivered(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "outboxRepository.DeleteDelivered", "DeleteDeliveredOutboxEvents")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).DeleteDeliveredOutboxEvents(ctx, pgtype.Timestamp{Time: before.UTC(), Valid: true})
+}
+
+func (r *outboxRepository) q(ctx context.Context) db.Querier {
+	if q, ok := txQuerier(ctx); ok {
+		return q
+	}
+	return r.querier
+}
@@ -0,0 +1,81 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	runWithTestDb(t, func(repo *URLRepository) {
+		ctx := context.Background()
+		t.Cleanup(func() {
+			_, err := testPool.Exec(ctx, "TRUNCATE TABLE outbox")
+			require.NoError(t, err)
+		})
+		outbox := NewOutboxRepository(testPool)
+		tx := NewTxManager(testPool)
+
+		t.Run("rolled back changes record no events", func(t *testing.T) {
+			rollback := errors.New("rollback")
+			err := tx.WithinTx(ctx, func(ctx context.Context) error {
+				_, err := (*repo).CreateURL(ctx, &db.CreateUrlParams{OriginalUrl: "https://google.com", ShortUrl: "gone"})
+				require.NoError(t, err)
+				require.NoError(t, outbox.AddEvent(ctx, &model.Event{
+					Type: model.EventLinkCreated, ShortUrl: "gone", Data: []byte("{}"),
+				}))
+				return rollback
+			})
+			assert.ErrorIs(t, err, rollback)
+
+			_, err = (*repo).GetURLByShortened(ctx, "gone")
+			assert.ErrorIs(t, err, ErrURLNotFound)
+			events, err := outbox.ClaimEvents(ctx, 10, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, events)
+		})
+
+		t.Run("claim and deliver", func(t *testing.T) {
+			err := tx.WithinTx(ctx, func(ctx context.Context) error {
+				if _, err := (*repo).CreateURL(ctx, &db.CreateUrlParams{OriginalUrl: "https://google.com", ShortUrl: "exmpl"}); err != nil {
+					return err
+				}
+				return outbox.AddEvent(ctx, &model.Event{
+					Type: model.EventLinkCreated, ShortUrl: "exmpl", Data: []byte(`{"original_url":"https://google.com"}`),
+				})
+			})
+			require.NoError(t, err)
+
+			events, err := outbox.ClaimEvents(ctx, 10, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.Equal(t, model.EventLinkCreated, events[0].Type)
+			assert.Equal(t, 1, events[0].Attempts)
+			assert.JSONEq(t, `{"original_url":"https://google.com"}`, string(events[0].Data))
+
+			leased, err := outbox.ClaimEvents(ctx, 10, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, leased, "claimed events are leased")
+
+			require.NoError(t, outbox.MarkFailed(ctx, events[0].ID, "connection refused", 0))
+			retried, err := outbox.ClaimEvents(ctx, 10, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, retried, 1)
+			assert.Equal(t, 2, retried[0].Attempts)
+
+			require.NoError(t, outbox.MarkDelivered(ctx, events[0].ID))
+			deleted, err := outbox.DeleteDelivered(ctx, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), deleted)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// LinkPreviewRepository stores the custom previews of links. Previews are
+// removed together with their link.
+type LinkPreviewRepository interface {
+	// SetLinkPreview creates or replaces the preview of a link.
+	SetLinkPreview(ctx context.Context, preview *model.LinkPreview) error
+	GetLinkPreview(ctx context.Context, shortURL string) (*model.LinkPreview, error)
+	DeleteLinkPreview(ctx context.Context, shortURL string) error
+}
+
+type linkPreviewRepository struct {
+	querier db.Querier
+}
+
+func NewLinkPreviewRepository(conn *pgxpool.Pool) LinkPreviewRepository {
+	return &linkPreviewRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *linkPreviewRepository) SetLinkPreview(ctx context.Context, preview *model.LinkPreview) (err error) {
+	ctx, span := startSpan(ctx, "linkPreviewRepository.SetLinkPreview", "UpsertLinkPreview")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.UpsertLinkPreview(ctx, db.UpsertLinkPreviewParams{
+		ShortUrl:    preview.ShortUrl,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageUrl:    preview.ImageUrl,
+	})
+	if err != nil {
+		return err
+	}
+	*preview = *toLinkPreview(row)
+	return nil
+}
+
+func (r *linkPreviewRepository) GetLinkPreview(ctx context.Context, shortURL string) (_ *model.LinkPreview, err error) {
+	ctx, span := startSpan(ctx, "linkPreviewRepository.GetLinkPreview", "GetLinkPreview")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.GetLinkPreview(ctx, shortURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLinkPreviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toLinkPreview(row), nil
+}
+
+func (r *linkPreviewRepository) DeleteLinkPreview(ctx context.Context, shortURL string) (err error) {
+	ctx, span := startSpan(ctx, "linkPreviewRepository.DeleteLinkPreview", "DeleteLinkPreview")
+	defer telemetry.End(span, &err)
+
+	deleted, err := r.querier.DeleteLinkPreview(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrLinkPreviewNotFound
+	}
+	return nil
+}
+
+func toLinkPreview(row db.LinkPreview) *model.LinkPreview {
+	return &model.LinkPreview{
+		ShortUrl:    row.ShortUrl,
+		Title:       row.Title,
+		Description: row.Description,
+		ImageUrl:    row.ImageUrl,
+		UpdatedAt:   row.UpdatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+var ErrLinkPreviewNotFound = model.Error{
+	Message: "Link preview not found",
+}
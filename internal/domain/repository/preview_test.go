@@ -0,0 +1,48 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestLinkPreviewRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		_, err := (*urls).CreateURL(ctx, &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com/a"})
+		require.NoError(t, err)
+		repo := NewLinkPreviewRepository(testPool)
+
+		_, err = repo.GetLinkPreview(ctx, "ab")
+		assert.ErrorIs(t, err, ErrLinkPreviewNotFound)
+
+		preview := &model.LinkPreview{ShortUrl: "ab", Title: "Launch", ImageUrl: "https://example.com/launch.png"}
+		require.NoError(t, repo.SetLinkPreview(ctx, preview))
+		assert.NotEmpty(t, preview.UpdatedAt)
+
+		require.NoError(t, repo.SetLinkPreview(ctx, &model.LinkPreview{ShortUrl: "ab", Description: "We launched"}))
+		preview, err = repo.GetLinkPreview(ctx, "ab")
+		require.NoError(t, err)
+		assert.Empty(t, preview.Title, "the preview is replaced")
+		assert.Equal(t, "We launched", preview.Description)
+		assert.Empty(t, preview.ImageUrl)
+
+		require.NoError(t, repo.DeleteLinkPreview(ctx, "ab"))
+		assert.ErrorIs(t, repo.DeleteLinkPreview(ctx, "ab"), ErrLinkPreviewNotFound)
+
+		t.Run("removed with the link", func(t *testing.T) {
+			require.NoError(t, repo.SetLinkPreview(ctx, &model.LinkPreview{ShortUrl: "ab", Title: "Launch"}))
+			require.NoError(t, (*urls).DeleteURL(ctx, "ab"))
+
+			_, err := repo.GetLinkPreview(ctx, "ab")
+			assert.ErrorIs(t, err, ErrLinkPreviewNotFound)
+		})
+	})
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// All keys share the {url-shortener} hash tag so that the scripts below touch
+// a single slot when running against Redis Cluster. The prefix is longer than
+// any alias, so link keys cannot collide with cache entries in the same
+// database.
+const (
+	redisKeyPrefix   = "{url-shortener}:"
+	redisLinkPrefix  = redisKeyPrefix + "link:"
+	redisSequenceKey = redisKeyPrefix + "links:seq"
+	redisByIDKey     = redisKeyPrefix + "links:by_id"
+	redisByCodeKey   = redisKeyPrefix + "links:by_code"
+	// redisByClicksKey scores links by negated click count so that ascending
+	// ranges return the most clicked links first, with ties in code order.
+	redisByClicksKey = redisKeyPrefix + "links:by_clicks"
+)
+
+// redisPutScript stores a link and its index entries. ARGV holds the code,
+// original URL, click count, created at, updated at and whether an existing
+// link may be replaced. It returns 0 when the link exists and may not be
+// replaced.
+var redisPutScript = redis.NewScript(`
+local exists = redis.call('EXISTS', KEYS[1]) == 1
+if exists and ARGV[6] == '0' then
+	return 0
+end
+local id
+if exists then
+	id = redis.call('HGET', KEYS[1], 'id')
+else
+	id = redis.call('INCR', KEYS[2])
+end
+redis.call('HSET', KEYS[1], 'id', id, 'original_url', ARGV[2], 'click_count', ARGV[3],
+	'created_at', ARGV[4], 'updated_at', ARGV[5])
+redis.call('ZADD', KEYS[3], id, ARGV[1])
+redis.call('ZADD', KEYS[4], 0, ARGV[1])
+redis.call('ZADD', KEYS[5], -tonumber(ARGV[3]), ARGV[1])
+return 1
+`)
+
+var redisIncrementScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local clicks = redis.call('HINCRBY', KEYS[1], 'click_count', 1)
+redis.call('HSET', KEYS[1], 'updated_at', ARGV[2])
+redis.call('ZADD', KEYS[2], -clicks, ARGV[1])
+return 1
+`)
+
+var redisDeleteScript = redis.NewScript(`
+if redis.call('DEL', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZREM', KEYS[4], ARGV[1])
+return 1
+`)
+
+type redisURLRepository struct {
+	client *redis.Client
+}
+
+func NewRedisURLRepository(client *redis.Client) URLRepository {
+	return &redisURLRepository{
+		client: client,
+	}
+}
+
+func (r *redisURLRepository) CreateURL(ctx context.Context, url *db.CreateUrlParams) (_ *model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.CreateURL", "EVALSHA")
+	defer telemetry.End(span, &err)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	created := &model.Url{
+		OriginalUrl: url.OriginalUrl,
+		ShortUrl:    url.ShortUrl,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := r.put(ctx, created, false); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *redisURLRepository) GetURLByShortened(ctx context.Context, shortened string) (_ *model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.GetURLByShortened", "HGETALL")
+	defer telemetry.End(span, &err)
+
+	fields, err := r.client.HGetAll(ctx, redisLinkPrefix+shortened).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrURLNotFound
+	}
+	return redisURL(shortened, fields), nil
+}
+
+func (r *redisURLRepository) IncrementClickCount(ctx context.Context, shortened string) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.IncrementClickCount", "EVALSHA")
+	defer telemetry.End(span, &err)
+
+	updated, err := redisIncrementScript.Run(ctx, r.client,
+		[]string{redisLinkPrefix + shortened, redisByClicksKey},
+		shortened, time.Now().UTC().Format(time.RFC3339),
+	).Int()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrURLNotFound
+	}
+	return nil
+}
+
+func (r *redisURLRepository) DeleteURL(ctx context.Context, shortened string) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.DeleteURL", "EVALSHA")
+	defer telemetry.End(span, &err)
+
+	deleted, err := redisDeleteScript.Run(ctx, r.client,
+		[]string{redisLinkPrefix + shortened, redisByIDKey, redisByCodeKey, redisByClicksKey},
+		shortened,
+	).Int()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrURLNotFound
+	}
+	return nil
+}
+
+func (r *redisURLRepository) ListURLs(ctx context.Context, limit, offset int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.ListURLs", "ZRANGE")
+	defer telemetry.End(span, &err)
+
+	if limit <= 0 {
+		return []*model.Url{}, nil
+	}
+	codes, err := r.client.ZRange(ctx, redisByIDKey, int64(offset), int64(offset)+int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.load(ctx, codes)
+}
+
+func (r *redisURLRepository) ListURLsAfter(ctx context.Context, after string, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.ListURLsAfter", "ZRANGEBYLEX")
+	defer telemetry.End(span, &err)
+
+	if limit <= 0 {
+		return []*model.Url{}, nil
+	}
+	from := "-"
+	if after != "" {
+		from = "(" + after
+	}
+	codes, err := r.client.ZRangeByLex(ctx, redisByCodeKey, &redis.ZRangeBy{
+		Min:   from,
+		Max:   "+",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.load(ctx, codes)
+}
+
+func (r *redisURLRepository) ListTopURLs(ctx context.Context, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.ListTopURLs", "ZRANGE")
+	defer telemetry.End(span, &err)
+
+	if limit <= 0 {
+		return []*model.Url{}, nil
+	}
+	codes, err := r.client.ZRange(ctx, redisByClicksKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.load(ctx, codes)
+}
+
+func (r *redisURLRepository) ImportURL(ctx context.Context, url *model.Url, overwrite bool) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisURLRepository.ImportURL", "EVALSHA")
+	defer telemetry.End(span, &err)
+
+	createdAt, err := parseTimestamp(url.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updatedAt, err := parseTimestamp(url.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return r.put(ctx, &model.Url{
+		OriginalUrl: url.OriginalUrl,
+		ShortUrl:    url.ShortUrl,
+		ClickCount:  url.ClickCount,
+		CreatedAt:   createdAt.Time.Format(time.RFC3339),
+		UpdatedAt:   updatedAt.Time.Format(time.RFC3339),
+	}, overwrite)
+}
+
+func (r *redisURLRepository) put(ctx context.Context, url *model.Url, overwrite bool) error {
+	replace := "0"
+	if overwrite {
+		replace = "1"
+	}
+	stored, err := redisPutScript.Run(ctx, r.client,
+		[]string{redisLinkPrefix + url.ShortUrl, redisSequenceKey, redisByIDKey, redisByCodeKey, redisByClicksKey},
+		url.ShortUrl, url.OriginalUrl, url.ClickCount, url.CreatedAt, url.UpdatedAt, replace,
+	).Int()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrURLAlreadyExists
+	}
+	return nil
+}
+
+// load fetches the links behind codes, preserving their order. Codes whose
+// link disappeared since the index was read are skipped.
+func (r *redisURLRepository) load(ctx context.Context, codes []string) ([]*model.Url, error) {
+	if len(codes) == 0 {
+		return []*model.Url{}, nil
+	}
+	cmds := make([]*redis.MapStringStringCmd, len(codes))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, code := range codes {
+			cmds[i] = pipe.HGetAll(ctx, redisLinkPrefix+code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]*model.Url, 0, len(codes))
+	for i, cmd := range cmds {
+		if fields := cmd.Val(); len(fields) > 0 {
+			urls = append(urls, redisURL(codes[i], fields))
+		}
+	}
+	return urls, nil
+}
+
+func redisURL(code string, fields map[string]string) *model.Url {
+	clicks, _ := strconv.ParseInt(fields["click_count"], 10, 64)
+	return &model.Url{
+		OriginalUrl: fields["original_url"],
+		ShortUrl:    code,
+		ClickCount:  clicks,
+		CreatedAt:   fields["created_at"],
+		UpdatedAt:   fields["updated_at"],
+	}
+}
@@ -0,0 +1,28 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/config"
+)
+
+func TestRedisConformance(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) URLRepository {
+		cfg, err := config.LoadConfig()
+		require.NoError(t, err)
+
+		client := redis.NewClient(&redis.Options{Addr: cfg.RedisURL})
+		require.NoError(t, client.FlushDB(context.Background()).Err())
+		t.Cleanup(func() {
+			require.NoError(t, client.FlushDB(context.Background()).Err())
+			client.Close() //nolint:errcheck
+		})
+		return NewRedisURLRepository(client)
+	})
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// replicaLagQuery reports how far a streaming replica is behind. A replica
+// that has replayed everything it received is not lagging, however long ago
+// the last transaction was, and a primary reports no lag at all.
+const replicaLagQuery = `SELECT CASE
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+// replicaSet routes the reads of a repository to healthy replicas in turn and
+// everything else to the primary.
+type replicaSet struct {
+	querier  db.Querier
+	replicas []*Replica
+	next     atomic.Uint64
+}
+
+func newReplicaSet(primary db.Querier, replicas []*Replica) *replicaSet {
+	return &replicaSet{
+		querier:  primary,
+		replicas: replicas,
+	}
+}
+
+// read runs query on the next healthy replica and retries it on the primary
+// if that fails.
+func (r *replicaSet) read(ctx context.Context, query func(q db.Querier) error) error {
+	if q, ok := txQuerier(ctx); ok {
+		return query(q)
+	}
+
+	replica := r.replica()
+	if replica == nil {
+		return query(r.querier)
+	}
+
+	err := query(replica.querier)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	metrics.ReplicaFallbacksTotal.WithLabelValues(replica.name).Inc()
+	return query(r.querier)
+}
+
+// q returns the querier of the transaction in ctx, or the primary's.
+func (r *replicaSet) q(ctx context.Context) db.Querier {
+	if q, ok := txQuerier(ctx); ok {
+		return q
+	}
+	return r.querier
+}
+
+func (r *replicaSet) replica() *Replica {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range uint64(len(r.replicas)) {
+		replica := r.replicas[(start+i)%uint64(len(r.replicas))]
+		if replica.Healthy() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// Replica is a read-only Postgres pool that lookups and listings are served
+// from. It is taken out of rotation while Monitor finds it lagging or
+// unreachable.
+type Replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	querier db.Querier
+	healthy atomic.Bool
+}
+
+func NewReplica(name string, pool *pgxpool.Pool) *Replica {
+	r := &Replica{
+		name:    name,
+		pool:    pool,
+		querier: db.New(pool),
+	}
+	r.healthy.Store(true)
+	return r
+}
+
+func (r *Replica) Name() string {
+	return r.name
+}
+
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// Lag returns how far the replica is behind its primary.
+func (r *Replica) Lag(ctx context.Context) (time.Duration, error) {
+	var seconds float64
+	if err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Monitor checks the replica's lag every interval until ctx is cancelled.
+// Reads skip the replica while the lag exceeds maxLag or cannot be measured.
+func (r *Replica) Monitor(ctx context.Context, interval, maxLag time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		lag, err := r.Lag(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil && lag <= maxLag
+		if err == nil {
+			metrics.ReplicaLagSeconds.WithLabelValues(r.name).Set(lag.Seconds())
+		}
+		if was := r.healthy.Swap(healthy); was != healthy {
+			if healthy {
+				logger.Info("Replica back in rotation", "replica", r.name, "lag", lag)
+			} else {
+				logger.Warn("Replica taken out of rotation", "replica", r.name, "lag", lag, "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+)
+
+// fakeQuerier answers GetUrlByShort from urls and counts the calls it gets.
+type fakeQuerier struct {
+	db.Querier
+	urls  map[string]string
+	err   error
+	calls int
+}
+
+func (q *fakeQuerier) GetUrlByShort(_ context.Context, shortUrl string) (db.GetUrlByShortRow, error) {
+	q.calls++
+	if q.err != nil {
+		return db.GetUrlByShortRow{}, q.err
+	}
+	original, ok := q.urls[shortUrl]
+	if !ok {
+		return db.GetUrlByShortRow{}, pgx.ErrNoRows
+	}
+	return db.GetUrlByShortRow{OriginalUrl: original, ShortUrl: shortUrl}, nil
+}
+
+func newFakeReplica(name string, q db.Querier) *Replica {
+	r := &Replica{name: name, querier: q}
+	r.healthy.Store(true)
+	return r
+}
+
+func TestURLRepository_ReadsFromReplicas(t *testing.T) {
+	primary := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+	first := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+	second := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+	repo := &urlRepository{
+		replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", first), newFakeReplica("replica-2", second)}),
+	}
+
+	for range 4 {
+		url, err := repo.GetURLByShortened(context.Background(), "exmpl")
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", url.OriginalUrl)
+	}
+
+	assert.Zero(t, primary.calls)
+	assert.Equal(t, 2, first.calls)
+	assert.Equal(t, 2, second.calls)
+}
+
+func TestURLRepository_SkipsUnhealthyReplicas(t *testing.T) {
+	primary := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+	lagging := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+	replica := newFakeReplica("replica-1", lagging)
+	replica.healthy.Store(false)
+	repo := &urlRepository{replicaSet: newReplicaSet(primary, []*Replica{replica})}
+
+	_, err := repo.GetURLByShortened(context.Background(), "exmpl")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+	assert.Zero(t, lagging.calls)
+}
+
+func TestURLRepository_FallsBackToPrimary(t *testing.T) {
+	t.Run("replica error", func(t *testing.T) {
+		primary := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+		failing := &fakeQuerier{err: errors.New("connection refused")}
+		repo := &urlRepository{replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", failing)})}
+
+		url, err := repo.GetURLByShortened(context.Background(), "exmpl")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", url.OriginalUrl)
+		assert.Equal(t, 1, primary.calls)
+	})
+
+	t.Run("link not replicated yet", func(t *testing.T) {
+		primary := &fakeQuerier{urls: map[string]string{"exmpl": "https://google.com"}}
+		behind := &fakeQuerier{urls: map[string]string{}}
+		repo := &urlRepository{replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", behind)})}
+
+		url, err := repo.GetURLByShortened(context.Background(), "exmpl")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", url.OriginalUrl)
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		primary := &fakeQuerier{urls: map[string]string{}}
+		replica := &fakeQuerier{urls: map[string]string{}}
+		repo := &urlRepository{replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", replica)})}
+
+		_, err := repo.GetURLByShortened(context.Background(), "unknown")
+
+		assert.ErrorIs(t, err, ErrURLNotFound)
+		assert.Equal(t, 1, primary.calls)
+	})
+}
+
+// fakeDashboardQuerier answers the dashboard queries with links total links,
+// or fails them all with err.
+type fakeDashboardQuerier struct {
+	db.Querier
+	links int64
+	err   error
+	calls int
+}
+
+func (q *fakeDashboardQuerier) GetOwnerLinkTotals(context.Context, string) (db.GetOwnerLinkTotalsRow, error) {
+	q.calls++
+	return db.GetOwnerLinkTotalsRow{Links: q.links}, q.err
+}
+
+func (q *fakeDashboardQuerier) ListOwnerTopLinksByHour(context.Context, db.ListOwnerTopLinksByHourParams) ([]db.ListOwnerTopLinksByHourRow, error) {
+	return nil, q.err
+}
+
+func (q *fakeDashboardQuerier) ListOwnerNewestLinks(context.Context, db.ListOwnerNewestLinksParams) ([]db.ListOwnerNewestLinksRow, error) {
+	return nil, q.err
+}
+
+func (q *fakeDashboardQuerier) ListOwnerUnclickedLinks(context.Context, db.ListOwnerUnclickedLinksParams) ([]db.ListOwnerUnclickedLinksRow, error) {
+	return nil, q.err
+}
+
+func TestDashboardRepository_ReadsFromReplicas(t *testing.T) {
+	primary := &fakeDashboardQuerier{links: 2}
+	replica := &fakeDashboardQuerier{links: 2}
+	repo := &dashboardRepository{replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", replica)})}
+
+	dashboard, err := repo.GetDashboard(context.Background(), "alice", time.Hour, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), dashboard.TotalLinks)
+	assert.Zero(t, primary.calls)
+	assert.Equal(t, 1, replica.calls)
+}
+
+func TestDashboardRepository_FallsBackToPrimary(t *testing.T) {
+	primary := &fakeDashboardQuerier{links: 2}
+	failing := &fakeDashboardQuerier{err: errors.New("connection refused")}
+	repo := &dashboardRepository{replicaSet: newReplicaSet(primary, []*Replica{newFakeReplica("replica-1", failing)})}
+
+	dashboard, err := repo.GetDashboard(context.Background(), "alice", time.Hour, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), dashboard.TotalLinks)
+	assert.Equal(t, 1, primary.calls)
+}
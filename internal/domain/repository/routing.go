@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// RoutingRuleRepository stores the routing rules of links. Rules are removed
+// together with their link.
+type RoutingRuleRepository interface {
+	CreateRule(ctx context.Context, rule *model.RoutingRule) error
+	// ListRules returns the rules of a link in the order they are evaluated.
+	ListRules(ctx context.Context, shortURL string) ([]*model.RoutingRule, error)
+	DeleteRule(ctx context.Context, shortURL string, id int64) error
+}
+
+type routingRuleRepository struct {
+	querier db.Querier
+}
+
+func NewRoutingRuleRepository(conn *pgxpool.Pool) RoutingRuleRepository {
+	return &routingRuleRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *routingRuleRepository) CreateRule(ctx context.Context, rule *model.RoutingRule) (err error) {
+	ctx, span := startSpan(ctx, "routingRuleRepository.CreateRule", "CreateRoutingRule")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.CreateRoutingRule(ctx, db.CreateRoutingRuleParams{
+		ShortUrl:         rule.ShortUrl,
+		Priority:         int32(rule.Priority),
+		Devices:          nonNil(rule.Devices),
+		OperatingSystems: nonNil(rule.OSes),
+		Languages:        nonNil(rule.Languages),
+		Countries:        nonNil(rule.Countries),
+		Destination:      rule.Destination,
+	})
+	if err != nil {
+		return err
+	}
+	*rule = *toRoutingRule(row)
+	return nil
+}
+
+func (r *routingRuleRepository) ListRules(ctx context.Context, shortURL string) (_ []*model.RoutingRule, err error) {
+	ctx, span := startSpan(ctx, "routingRuleRepository.ListRules", "ListRoutingRules")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ListRoutingRules(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*model.RoutingRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, toRoutingRule(row))
+	}
+	return rules, nil
+}
+
+func (r *routingRuleRepository) DeleteRule(ctx context.Context, shortURL string, id int64) (err error) {
+	ctx, span := startSpan(ctx, "routingRuleRepository.DeleteRule", "DeleteRoutingRule")
+	defer telemetry.End(span, &err)
+
+	deleted, err := r.querier.DeleteRoutingRule(ctx, db.DeleteRoutingRuleParams{ID: id, ShortUrl: shortURL})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRoutingRuleNotFound
+	}
+	return nil
+}
+
+func toRoutingRule(row db.RoutingRule) *model.RoutingRule {
+	return &model.RoutingRule{
+		ID:          row.ID,
+		ShortUrl:    row.ShortUrl,
+		Priority:    int(row.Priority),
+		Devices:     row.Devices,
+		OSes:        row.OperatingSystems,
+		Languages:   row.Languages,
+		Countries:   row.Countries,
+		Destination: row.Destination,
+		CreatedAt:   row.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+// nonNil keeps NOT NULL array columns from receiving NULL.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+var ErrRoutingRuleNotFound = model.Error{
+	Message: "Routing rule not found",
+}
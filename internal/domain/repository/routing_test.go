@@ -0,0 +1,48 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestRoutingRuleRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		_, err := (*urls).CreateURL(ctx, &db.CreateUrlParams{ShortUrl: "app", OriginalUrl: "https://example.com"})
+		require.NoError(t, err)
+		repo := NewRoutingRuleRepository(testPool)
+
+		fallback := &model.RoutingRule{ShortUrl: "app", Priority: 10, Countries: []string{"DE"},
+			Destination: "https://example.com/de"}
+		ios := &model.RoutingRule{ShortUrl: "app", OSes: []string{model.OSiOS}, Languages: []string{"de", "en"},
+			Destination: "https://apps.apple.com/app/id1"}
+		require.NoError(t, repo.CreateRule(ctx, fallback))
+		require.NoError(t, repo.CreateRule(ctx, ios))
+		require.NotZero(t, ios.ID)
+		assert.NotEmpty(t, ios.CreatedAt)
+
+		rules, err := repo.ListRules(ctx, "app")
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, ios.ID, rules[0].ID, "rules are ordered by priority")
+		assert.Equal(t, []string{"de", "en"}, rules[0].Languages)
+		assert.Empty(t, rules[0].Countries)
+
+		assert.ErrorIs(t, repo.DeleteRule(ctx, "other", ios.ID), ErrRoutingRuleNotFound)
+		require.NoError(t, repo.DeleteRule(ctx, "app", ios.ID))
+		assert.ErrorIs(t, repo.DeleteRule(ctx, "app", ios.ID), ErrRoutingRuleNotFound)
+
+		require.NoError(t, (*urls).DeleteURL(ctx, "app"))
+		rules, err = repo.ListRules(ctx, "app")
+		require.NoError(t, err)
+		assert.Empty(t, rules, "rules are deleted with their link")
+	})
+}
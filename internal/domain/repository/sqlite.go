@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// sqliteMigrations are applied in order; PRAGMA user_version records how many
+// of them a database has already seen.
+var sqliteMigrations = []string{
+	`CREATE TABLE urls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		original_url TEXT NOT NULL,
+		short_url TEXT NOT NULL UNIQUE,
+		click_count INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+	CREATE INDEX idx_urls_click_count ON urls (click_count DESC, short_url);`,
+}
+
+const sqliteColumns = "original_url, short_url, click_count, created_at, updated_at"
+
+type sqliteURLRepository struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed, and brings
+// its schema up to date.
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY errors
+	// and keeps ":memory:" databases from being opened more than once.
+	conn.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA synchronous = NORMAL",
+	} {
+		if _, err := conn.ExecContext(ctx, pragma); err != nil {
+			conn.Close() //nolint:errcheck
+			return nil, fmt.Errorf("failed to configure SQLite: %w", err)
+		}
+	}
+
+	if err := migrateSQLite(ctx, conn); err != nil {
+		conn.Close() //nolint:errcheck
+		return nil, err
+	}
+	return conn, nil
+}
+
+func migrateSQLite(ctx context.Context, conn *sql.DB) error {
+	var version int
+	if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read SQLite schema version: %w", err)
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback() //nolint:errcheck
+			return fmt.Errorf("failed to apply SQLite migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback() //nolint:errcheck
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewSQLiteURLRepository(conn *sql.DB) URLRepository {
+	return &sqliteURLRepository{
+		db: conn,
+	}
+}
+
+func (r *sqliteURLRepository) CreateURL(ctx context.Context, url *db.CreateUrlParams) (_ *model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.CreateURL", "INSERT")
+	defer telemetry.End(span, &err)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO urls (`+sqliteColumns+`) VALUES (?, ?, 0, ?, ?)
+		ON CONFLICT (short_url) DO NOTHING`,
+		url.OriginalUrl, url.ShortUrl, now, now)
+	if err != nil {
+		return nil, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if inserted == 0 {
+		return nil, ErrURLAlreadyExists
+	}
+
+	return &model.Url{
+		OriginalUrl: url.OriginalUrl,
+		ShortUrl:    url.ShortUrl,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (r *sqliteURLRepository) GetURLByShortened(ctx context.Context, shortened string) (_ *model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.GetURLByShortened", "SELECT")
+	defer telemetry.End(span, &err)
+
+	url := &model.Url{}
+	err = r.db.QueryRowContext(ctx,
+		`SELECT `+sqliteColumns+` FROM urls WHERE short_url = ?`, shortened).
+		Scan(&url.OriginalUrl, &url.ShortUrl, &url.ClickCount, &url.CreatedAt, &url.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrURLNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return url, nil
+}
+
+func (r *sqliteURLRepository) IncrementClickCount(ctx context.Context, shortened string) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.IncrementClickCount", "UPDATE")
+	defer telemetry.End(span, &err)
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE urls SET click_count = click_count + 1, updated_at = ? WHERE short_url = ?`,
+		time.Now().UTC().Format(time.RFC3339), shortened)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *sqliteURLRepository) DeleteURL(ctx context.Context, shortened string) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.DeleteURL", "DELETE")
+	defer telemetry.End(span, &err)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM urls WHERE short_url = ?`, shortened)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *sqliteURLRepository) ListURLs(ctx context.Context, limit, offset int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.ListURLs", "SELECT")
+	defer telemetry.End(span, &err)
+
+	return r.query(ctx, `SELECT `+sqliteColumns+` FROM urls ORDER BY id LIMIT ? OFFSET ?`, limit, offset)
+}
+
+func (r *sqliteURLRepository) ListURLsAfter(ctx context.Context, after string, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.ListURLsAfter", "SELECT")
+	defer telemetry.End(span, &err)
+
+	return r.query(ctx,
+		`SELECT `+sqliteColumns+` FROM urls WHERE short_url > ? ORDER BY short_url LIMIT ?`, after, limit)
+}
+
+func (r *sqliteURLRepository) ListTopURLs(ctx context.Context, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.ListTopURLs", "SELECT")
+	defer telemetry.End(span, &err)
+
+	return r.query(ctx,
+		`SELECT `+sqliteColumns+` FROM urls ORDER BY click_count DESC, short_url LIMIT ?`, limit)
+}
+
+func (r *sqliteURLRepository) ImportURL(ctx context.Context, url *model.Url, overwrite bool) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemSqlite, "sqliteURLRepository.ImportURL", "INSERT")
+	defer telemetry.End(span, &err)
+
+	createdAt, err := parseTimestamp(url.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updatedAt, err := parseTimestamp(url.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	conflict := `DO NOTHING`
+	if overwrite {
+		conflict = `DO UPDATE SET
+			original_url = excluded.original_url,
+			click_count = excluded.click_count,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at`
+	}
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO urls (`+sqliteColumns+`) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (short_url) `+conflict,
+		url.OriginalUrl, url.ShortUrl, url.ClickCount,
+		createdAt.Time.Format(time.RFC3339), updatedAt.Time.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if inserted == 0 {
+		return ErrURLAlreadyExists
+	}
+	return nil
+}
+
+func (r *sqliteURLRepository) query(ctx context.Context, query string, args ...any) ([]*model.Url, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	urls := []*model.Url{}
+	for rows.Next() {
+		url := &model.Url{}
+		if err := rows.Scan(&url.OriginalUrl, &url.ShortUrl, &url.ClickCount, &url.CreatedAt, &url.UpdatedAt); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrURLNotFound
+	}
+	return nil
+}
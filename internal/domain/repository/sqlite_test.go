@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+)
+
+func TestOpenSQLite_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir() + "/urls.db"
+
+	conn, err := OpenSQLite(ctx, path)
+	require.NoError(t, err)
+	_, err = NewSQLiteURLRepository(conn).CreateURL(ctx, &db.CreateUrlParams{
+		OriginalUrl: "https://google.com",
+		ShortUrl:    "exmpl",
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	conn, err = OpenSQLite(ctx, path)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck
+
+	var version int
+	require.NoError(t, conn.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, len(sqliteMigrations), version)
+
+	fetched, err := NewSQLiteURLRepository(conn).GetURLByShortened(ctx, "exmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", fetched.OriginalUrl)
+}
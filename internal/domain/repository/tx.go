@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+)
+
+// TxManager runs a unit of work in a single transaction. Repositories called
+// with the context handed to fn take part in that transaction.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type pgxTxManager struct {
+	pool *pgxpool.Pool
+}
+
+func NewTxManager(pool *pgxpool.Pool) TxManager {
+	return &pgxTxManager{
+		pool: pool,
+	}
+}
+
+// WithinTx commits when fn succeeds and rolls back otherwise. Nested calls
+// join the outer transaction.
+func (m *pgxTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				err = errors.Join(err, rbErr)
+			}
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type noopTxManager struct{}
+
+// NewNoopTxManager returns a TxManager that just calls fn, for backends
+// without transactions shared across repositories.
+func NewNoopTxManager() TxManager {
+	return noopTxManager{}
+}
+
+func (noopTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// txQuerier returns a querier bound to the transaction in ctx, if any.
+func txQuerier(ctx context.Context) (db.Querier, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	if !ok {
+		return nil, false
+	}
+	return db.New(tx), true
+}
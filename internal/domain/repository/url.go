@@ -2,39 +2,68 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 
 	db "github.com/unwale/url-shortener/db/sqlc"
 	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
 )
 
+// Storage backends that can hold short links.
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageRedis    = "redis"
+	StorageMemory   = "memory"
+)
+
+var tracer = otel.Tracer("github.com/unwale/url-shortener/internal/domain/repository")
+
 type URLRepository interface {
 	CreateURL(ctx context.Context, url *db.CreateUrlParams) (*model.Url, error)
 	GetURLByShortened(ctx context.Context, shortened string) (*model.Url, error)
 	IncrementClickCount(ctx context.Context, shortened string) error
+	DeleteURL(ctx context.Context, shortened string) error
+	ListURLs(ctx context.Context, limit, offset int32) ([]*model.Url, error)
+	ListURLsAfter(ctx context.Context, after string, limit int32) ([]*model.Url, error)
+	ListTopURLs(ctx context.Context, limit int32) ([]*model.Url, error)
+	ImportURL(ctx context.Context, url *model.Url, overwrite bool) error
 }
 
 type urlRepository struct {
-	querier db.Querier
+	*replicaSet
 }
 
-func NewURLRepository(conn *pgxpool.Pool) URLRepository {
+// NewURLRepository writes to primary and spreads lookups and listings over
+// replicas, falling back to primary when none is healthy or a replica fails.
+func NewURLRepository(primary *pgxpool.Pool, replicas ...*Replica) URLRepository {
 	return &urlRepository{
-		querier: db.New(conn),
+		replicaSet: newReplicaSet(db.New(primary), replicas),
 	}
 }
 
-func (r *urlRepository) CreateURL(ctx context.Context, url *db.CreateUrlParams) (*model.Url, error) {
-	_, err := r.querier.GetUrlByShort(ctx, url.ShortUrl)
+func (r *urlRepository) CreateURL(ctx context.Context, url *db.CreateUrlParams) (_ *model.Url, err error) {
+	ctx, span := startSpan(ctx, "urlRepository.CreateURL", "CreateUrl")
+	defer telemetry.End(span, &err)
+
+	_, err = r.q(ctx).GetUrlByShort(ctx, url.ShortUrl)
 	if err == nil {
 		return nil, ErrURLAlreadyExists
 	}
-	createdUrl, err := r.querier.CreateUrl(ctx,
+	createdUrl, err := r.q(ctx).CreateUrl(ctx,
 		db.CreateUrlParams{
 			OriginalUrl: url.OriginalUrl,
 			ShortUrl:    url.ShortUrl,
+			Owner:       url.Owner,
 		})
 
 	return &model.Url{
@@ -45,29 +74,208 @@ func (r *urlRepository) CreateURL(ctx context.Context, url *db.CreateUrlParams)
 	}, err
 }
 
-func (r *urlRepository) GetURLByShortened(ctx context.Context, shortened string) (*model.Url, error) {
-	url, err := r.querier.GetUrlByShort(ctx, shortened)
-	if err != nil {
+func (r *urlRepository) GetURLByShortened(ctx context.Context, shortened string) (_ *model.Url, err error) {
+	ctx, span := startSpan(ctx, "urlRepository.GetURLByShortened", "GetUrlByShort")
+	defer telemetry.End(span, &err)
+
+	// A replica that has not caught up with a fresh link reports no rows, which
+	// sends the lookup on to the primary as well.
+	var url db.GetUrlByShortRow
+	err = r.read(ctx, func(q db.Querier) (err error) {
+		url, err = q.GetUrlByShort(ctx, shortened)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrURLNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	return &model.Url{
 		OriginalUrl: url.OriginalUrl,
 		ShortUrl:    url.ShortUrl,
+		Owner:       url.Owner.String,
 		ClickCount:  url.ClickCount,
 		CreatedAt:   url.CreatedAt.Time.Format(time.RFC3339),
 		UpdatedAt:   url.UpdatedAt.Time.Format(time.RFC3339),
 	}, nil
 }
 
-func (r *urlRepository) IncrementClickCount(ctx context.Context, shortened string) error {
-	_, err := r.querier.IncrementClickCount(ctx, shortened)
+func (r *urlRepository) IncrementClickCount(ctx context.Context, shortened string) (err error) {
+	ctx, span := startSpan(ctx, "urlRepository.IncrementClickCount", "IncrementClickCount")
+	defer telemetry.End(span, &err)
+
+	_, err = r.q(ctx).IncrementClickCount(ctx, shortened)
+	if err != nil {
+		return ErrURLNotFound
+	}
+	return nil
+}
+
+func (r *urlRepository) DeleteURL(ctx context.Context, shortened string) (err error) {
+	ctx, span := startSpan(ctx, "urlRepository.DeleteURL", "DeleteUrl")
+	defer telemetry.End(span, &err)
+
+	deleted, err := r.q(ctx).DeleteUrl(ctx, shortened)
 	if err != nil {
+		return err
+	}
+	if deleted == 0 {
 		return ErrURLNotFound
 	}
 	return nil
 }
 
+func (r *urlRepository) ListURLs(ctx context.Context, limit, offset int32) (_ []*model.Url, err error) {
+	ctx, span := startSpan(ctx, "urlRepository.ListURLs", "ListUrls")
+	defer telemetry.End(span, &err)
+
+	var rows []db.ListUrlsRow
+	err = r.read(ctx, func(q db.Querier) (err error) {
+		rows, err = q.ListUrls(ctx, db.ListUrlsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]*model.Url, 0, len(rows))
+	for _, row := range rows {
+		urls = append(urls, &model.Url{
+			OriginalUrl: row.OriginalUrl,
+			ShortUrl:    row.ShortUrl,
+			ClickCount:  row.ClickCount,
+			CreatedAt:   row.CreatedAt.Time.Format(time.RFC3339),
+			UpdatedAt:   row.UpdatedAt.Time.Format(time.RFC3339),
+		})
+	}
+	return urls, nil
+}
+
+func (r *urlRepository) ListURLsAfter(ctx context.Context, after string, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startSpan(ctx, "urlRepository.ListURLsAfter", "ListUrlsAfter")
+	defer telemetry.End(span, &err)
+
+	var rows []db.ListUrlsAfterRow
+	err = r.read(ctx, func(q db.Querier) (err error) {
+		rows, err = q.ListUrlsAfter(ctx, db.ListUrlsAfterParams{
+			ShortUrl: after,
+			Limit:    limit,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]*model.Url, 0, len(rows))
+	for _, row := range rows {
+		urls = append(urls, &model.Url{
+			OriginalUrl: row.OriginalUrl,
+			ShortUrl:    row.ShortUrl,
+			Owner:       row.Owner.String,
+			ClickCount:  row.ClickCount,
+			CreatedAt:   row.CreatedAt.Time.Format(time.RFC3339),
+			UpdatedAt:   row.UpdatedAt.Time.Format(time.RFC3339),
+		})
+	}
+	return urls, nil
+}
+
+func (r *urlRepository) ListTopURLs(ctx context.Context, limit int32) (_ []*model.Url, err error) {
+	ctx, span := startSpan(ctx, "urlRepository.ListTopURLs", "ListTopUrls")
+	defer telemetry.End(span, &err)
+
+	var rows []db.ListTopUrlsRow
+	err = r.read(ctx, func(q db.Querier) (err error) {
+		rows, err = q.ListTopUrls(ctx, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]*model.Url, 0, len(rows))
+	for _, row := range rows {
+		urls = append(urls, &model.Url{
+			OriginalUrl: row.OriginalUrl,
+			ShortUrl:    row.ShortUrl,
+			ClickCount:  row.ClickCount,
+			CreatedAt:   row.CreatedAt.Time.Format(time.RFC3339),
+			UpdatedAt:   row.UpdatedAt.Time.Format(time.RFC3339),
+		})
+	}
+	return urls, nil
+}
+
+func (r *urlRepository) ImportURL(ctx context.Context, url *model.Url, overwrite bool) (err error) {
+	ctx, span := startSpan(ctx, "urlRepository.ImportURL", "ImportUrl")
+	defer telemetry.End(span, &err)
+
+	createdAt, err := parseTimestamp(url.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updatedAt, err := parseTimestamp(url.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	owner := pgtype.Text{String: url.Owner, Valid: url.Owner != ""}
+	if overwrite {
+		return r.q(ctx).UpsertUrl(ctx, db.UpsertUrlParams{
+			OriginalUrl: url.OriginalUrl,
+			ShortUrl:    url.ShortUrl,
+			ClickCount:  url.ClickCount,
+			CreatedAt:   createdAt,
+			UpdatedAt:   updatedAt,
+			Owner:       owner,
+		})
+	}
+
+	inserted, err := r.q(ctx).InsertUrl(ctx, db.InsertUrlParams{
+		OriginalUrl: url.OriginalUrl,
+		ShortUrl:    url.ShortUrl,
+		ClickCount:  url.ClickCount,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		Owner:       owner,
+	})
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return ErrURLAlreadyExists
+	}
+	return nil
+}
+
+func startSpan(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return startDBSpan(ctx, semconv.DBSystemPostgreSQL, name, operation)
+}
+
+func startDBSpan(ctx context.Context, system attribute.KeyValue, name, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(system, semconv.DBOperationName(operation)),
+	)
+}
+
+func parseTimestamp(value string) (pgtype.Timestamp, error) {
+	if value == "" {
+		return pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return pgtype.Timestamp{}, err
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}, nil
+}
+
 var (
 	ErrURLAlreadyExists = model.Error{
 		Message: "URL already exists",
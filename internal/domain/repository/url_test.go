@@ -9,9 +9,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	db "github.com/unwale/url-shortener/db/sqlc"
 	"github.com/unwale/url-shortener/internal/config"
+	"github.com/unwale/url-shortener/internal/domain/model"
 )
 
 var (
@@ -20,7 +22,7 @@ var (
 
 func runWithTestDb(t *testing.T, fn func(repo *URLRepository)) {
 	t.Cleanup(func() {
-		_, err := testPool.Exec(context.Background(), "TRUNCATE TABLE urls")
+		_, err := testPool.Exec(context.Background(), "TRUNCATE TABLE urls CASCADE")
 		require.NoError(t, err)
 	})
 
@@ -89,6 +91,21 @@ func TestGetURLByShortened(t *testing.T) {
 		})
 	})
 
+	t.Run("get owned url", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			_, err := (*repo).CreateURL(context.Background(), &db.CreateUrlParams{
+				OriginalUrl: "https://google.com",
+				ShortUrl:    "owned",
+				Owner:       pgtype.Text{String: "owner-1", Valid: true},
+			})
+			require.NoError(t, err)
+
+			fetchedURL, err := (*repo).GetURLByShortened(context.Background(), "owned")
+			require.NoError(t, err)
+			assert.Equal(t, "owner-1", fetchedURL.Owner)
+		})
+	})
+
 	t.Run("get non-existing url", func(t *testing.T) {
 		runWithTestDb(t, func(repo *URLRepository) {
 			fetchedURL, err := (*repo).GetURLByShortened(context.Background(), "nonexistent")
@@ -125,3 +142,177 @@ func TestIncrementClickCount(t *testing.T) {
 		})
 	})
 }
+
+func TestDeleteURL(t *testing.T) {
+	t.Run("delete existing url", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			url := &db.CreateUrlParams{
+				OriginalUrl: "https://google.com",
+				ShortUrl:    "exmpl",
+			}
+
+			createdURL, err := (*repo).CreateURL(context.Background(), url)
+			require.NoError(t, err)
+
+			err = (*repo).DeleteURL(context.Background(), createdURL.ShortUrl)
+			require.NoError(t, err)
+
+			_, err = (*repo).GetURLByShortened(context.Background(), createdURL.ShortUrl)
+			assert.ErrorIs(t, err, ErrURLNotFound)
+		})
+	})
+
+	t.Run("delete non-existing url", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			err := (*repo).DeleteURL(context.Background(), "nonexistent")
+			assert.ErrorIs(t, err, ErrURLNotFound)
+		})
+	})
+}
+
+func TestListURLs(t *testing.T) {
+	t.Run("list urls with pagination", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			for _, short := range []string{"first", "second", "third"} {
+				_, err := (*repo).CreateURL(context.Background(), &db.CreateUrlParams{
+					OriginalUrl: "https://google.com/" + short,
+					ShortUrl:    short,
+				})
+				require.NoError(t, err)
+			}
+
+			urls, err := (*repo).ListURLs(context.Background(), 2, 0)
+			require.NoError(t, err)
+			require.Len(t, urls, 2)
+			assert.Equal(t, "first", urls[0].ShortUrl)
+			assert.Equal(t, "second", urls[1].ShortUrl)
+
+			urls, err = (*repo).ListURLs(context.Background(), 2, 2)
+			require.NoError(t, err)
+			require.Len(t, urls, 1)
+			assert.Equal(t, "third", urls[0].ShortUrl)
+		})
+	})
+}
+
+func TestListURLsAfter(t *testing.T) {
+	t.Run("list urls after cursor", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			for _, short := range []string{"ccc", "aaa", "bbb"} {
+				_, err := (*repo).CreateURL(context.Background(), &db.CreateUrlParams{
+					OriginalUrl: "https://google.com/" + short,
+					ShortUrl:    short,
+				})
+				require.NoError(t, err)
+			}
+
+			urls, err := (*repo).ListURLsAfter(context.Background(), "", 2)
+			require.NoError(t, err)
+			require.Len(t, urls, 2)
+			assert.Equal(t, "aaa", urls[0].ShortUrl)
+			assert.Equal(t, "bbb", urls[1].ShortUrl)
+
+			urls, err = (*repo).ListURLsAfter(context.Background(), "bbb", 2)
+			require.NoError(t, err)
+			require.Len(t, urls, 1)
+			assert.Equal(t, "ccc", urls[0].ShortUrl)
+		})
+	})
+}
+
+func TestListTopURLs(t *testing.T) {
+	t.Run("list most clicked urls", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			clicks := map[string]int{"aaa": 1, "bbb": 3, "ccc": 2}
+			for short, count := range clicks {
+				_, err := (*repo).CreateURL(context.Background(), &db.CreateUrlParams{
+					OriginalUrl: "https://google.com/" + short,
+					ShortUrl:    short,
+				})
+				require.NoError(t, err)
+				for range count {
+					require.NoError(t, (*repo).IncrementClickCount(context.Background(), short))
+				}
+			}
+
+			urls, err := (*repo).ListTopURLs(context.Background(), 2)
+			require.NoError(t, err)
+			require.Len(t, urls, 2)
+			assert.Equal(t, "bbb", urls[0].ShortUrl)
+			assert.Equal(t, "ccc", urls[1].ShortUrl)
+		})
+	})
+}
+
+func TestImportURL(t *testing.T) {
+	imported := &model.Url{
+		OriginalUrl: "https://google.com",
+		ShortUrl:    "exmpl",
+		Owner:       "owner-1",
+		ClickCount:  42,
+		CreatedAt:   "2024-01-02T03:04:05Z",
+		UpdatedAt:   "2024-02-03T04:05:06Z",
+	}
+
+	t.Run("import preserves fields", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			err := (*repo).ImportURL(context.Background(), imported, false)
+			require.NoError(t, err)
+
+			fetchedURL, err := (*repo).GetURLByShortened(context.Background(), "exmpl")
+			require.NoError(t, err)
+			assert.Equal(t, imported, fetchedURL)
+		})
+	})
+
+	t.Run("import existing url without overwrite", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			err := (*repo).ImportURL(context.Background(), imported, false)
+			require.NoError(t, err)
+
+			err = (*repo).ImportURL(context.Background(), imported, false)
+			assert.ErrorIs(t, err, ErrURLAlreadyExists)
+		})
+	})
+
+	t.Run("import existing url with overwrite", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			_, err := (*repo).CreateURL(context.Background(), &db.CreateUrlParams{
+				OriginalUrl: "https://old.com",
+				ShortUrl:    "exmpl",
+			})
+			require.NoError(t, err)
+
+			err = (*repo).ImportURL(context.Background(), imported, true)
+			require.NoError(t, err)
+
+			fetchedURL, err := (*repo).GetURLByShortened(context.Background(), "exmpl")
+			require.NoError(t, err)
+			assert.Equal(t, imported, fetchedURL)
+		})
+	})
+
+	t.Run("overwrite without owner keeps the owner", func(t *testing.T) {
+		runWithTestDb(t, func(repo *URLRepository) {
+			require.NoError(t, (*repo).ImportURL(context.Background(), imported, false))
+
+			err := (*repo).ImportURL(context.Background(), &model.Url{OriginalUrl: "https://new.com", ShortUrl: "exmpl"}, true)
+			require.NoError(t, err)
+
+			fetchedURL, err := (*repo).GetURLByShortened(context.Background(), "exmpl")
+			require.NoError(t, err)
+			assert.Equal(t, "https://new.com", fetchedURL.OriginalUrl)
+			assert.Equal(t, "owner-1", fetchedURL.Owner)
+		})
+	})
+}
+
+func TestPostgresConformance(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) URLRepository {
+		var repo URLRepository
+		runWithTestDb(t, func(r *URLRepository) {
+			repo = *r
+		})
+		return repo
+	})
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// VariantRepository stores the weighted destinations of split links.
+// Variants are removed together with their link.
+type VariantRepository interface {
+	ListVariants(ctx context.Context, shortURL string) ([]*model.Variant, error)
+	// UpsertVariant creates the variant or updates the weight of the link's
+	// variant with the same destination, keeping its click count.
+	UpsertVariant(ctx context.Context, variant *model.Variant) error
+	// DeleteVariantsExcept removes the link's variants whose destination is
+	// not in destinations.
+	DeleteVariantsExcept(ctx context.Context, shortURL string, destinations []string) error
+	IncrementVariantClickCount(ctx context.Context, id int64) error
+}
+
+type variantRepository struct {
+	querier db.Querier
+}
+
+func NewVariantRepository(conn *pgxpool.Pool) VariantRepository {
+	return &variantRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *variantRepository) ListVariants(ctx context.Context, shortURL string) (_ []*model.Variant, err error) {
+	ctx, span := startSpan(ctx, "variantRepository.ListVariants", "ListLinkVariants")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.q(ctx).ListLinkVariants(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]*model.Variant, 0, len(rows))
+	for _, row := range rows {
+		variants = append(variants, toVariant(row))
+	}
+	return variants, nil
+}
+
+func (r *variantRepository) UpsertVariant(ctx context.Context, variant *model.Variant) (err error) {
+	ctx, span := startSpan(ctx, "variantRepository.UpsertVariant", "UpsertLinkVariant")
+	defer telemetry.End(span, &err)
+
+	row, err := r.q(ctx).UpsertLinkVariant(ctx, db.UpsertLinkVariantParams{
+		ShortUrl:    variant.ShortUrl,
+		Destination: variant.Destination,
+		Weight:      int32(variant.Weight),
+	})
+	if err != nil {
+		return err
+	}
+	*variant = *toVariant(row)
+	return nil
+}
+
+func (r *variantRepository) DeleteVariantsExcept(ctx context.Context, shortURL string, destinations []string) (err error) {
+	ctx, span := startSpan(ctx, "variantRepository.DeleteVariantsExcept", "DeleteLinkVariantsExcept")
+	defer telemetry.End(span, &err)
+
+	_, err = r.q(ctx).DeleteLinkVariantsExcept(ctx, db.DeleteLinkVariantsExceptParams{
+		ShortUrl:     shortURL,
+		Destinations: nonNil(destinations),
+	})
+	return err
+}
+
+func (r *variantRepository) IncrementVariantClickCount(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "variantRepository.IncrementVariantClickCount", "IncrementLinkVariantClickCount")
+	defer telemetry.End(span, &err)
+
+	return r.q(ctx).IncrementLinkVariantClickCount(ctx, id)
+}
+
+// q returns the querier of the transaction in ctx, or the pool's.
+func (r *variantRepository) q(ctx context.Context) db.Querier {
+	if q, ok := txQuerier(ctx); ok {
+		return q
+	}
+	return r.querier
+}
+
+func toVariant(row db.LinkVariant) *model.Variant {
+	return &model.Variant{
+		ID:          row.ID,
+		ShortUrl:    row.ShortUrl,
+		Destination: row.Destination,
+		Weight:      int(row.Weight),
+		ClickCount:  row.ClickCount,
+		CreatedAt:   row.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
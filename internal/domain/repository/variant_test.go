@@ -0,0 +1,53 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestVariantRepository(t *testing.T) {
+	runWithTestDb(t, func(urls *URLRepository) {
+		ctx := context.Background()
+		_, err := (*urls).CreateURL(ctx, &db.CreateUrlParams{ShortUrl: "ab", OriginalUrl: "https://example.com"})
+		require.NoError(t, err)
+		repo := NewVariantRepository(testPool)
+
+		a := &model.Variant{ShortUrl: "ab", Destination: "https://example.com/a", Weight: 50}
+		b := &model.Variant{ShortUrl: "ab", Destination: "https://example.com/b", Weight: 50}
+		require.NoError(t, repo.UpsertVariant(ctx, a))
+		require.NoError(t, repo.UpsertVariant(ctx, b))
+		require.NoError(t, repo.IncrementVariantClickCount(ctx, a.ID))
+
+		t.Run("upserting keeps click counts", func(t *testing.T) {
+			a.Weight = 80
+			require.NoError(t, repo.UpsertVariant(ctx, a))
+
+			variants, err := repo.ListVariants(ctx, "ab")
+			require.NoError(t, err)
+			require.Len(t, variants, 2)
+			assert.Equal(t, 80, variants[0].Weight)
+			assert.Equal(t, int64(1), variants[0].ClickCount)
+		})
+
+		t.Run("delete except", func(t *testing.T) {
+			require.NoError(t, repo.DeleteVariantsExcept(ctx, "ab", []string{a.Destination}))
+			variants, err := repo.ListVariants(ctx, "ab")
+			require.NoError(t, err)
+			require.Len(t, variants, 1)
+			assert.Equal(t, a.ID, variants[0].ID)
+
+			require.NoError(t, repo.DeleteVariantsExcept(ctx, "ab", nil))
+			variants, err = repo.ListVariants(ctx, "ab")
+			require.NoError(t, err)
+			assert.Empty(t, variants)
+		})
+	})
+}
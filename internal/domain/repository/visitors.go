@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	// VisitorDays is how many days of daily unique visitor counts are kept.
+	VisitorDays = 30
+
+	redisVisitorsPrefix    = redisKeyPrefix + "visitors:total:"
+	redisDayVisitorsPrefix = redisKeyPrefix + "visitors:day:"
+	redisVisitorSecretKey  = redisKeyPrefix + "visitor_secret"
+	redisIPSaltPrefix      = redisKeyPrefix + "ip_salt:"
+	visitorDayFormat       = "2006-01-02"
+)
+
+// VisitorRepository counts the unique visitors of links with HyperLogLogs,
+// one for all time and one per UTC day, so counts are estimates with a
+// standard error of 0.81%. Visitors are identified by opaque hashes.
+type VisitorRepository interface {
+	AddVisitor(ctx context.Context, shortURL, visitor string, at time.Time) error
+	// GetUniqueVisitors returns the unique visitors of a link in total, over
+	// the last 7 and VisitorDays days and on each of these days up to now.
+	GetUniqueVisitors(ctx context.Context, shortURL string, now time.Time) (*model.UniqueVisitors, error)
+	DeleteVisitors(ctx context.Context, shortURL string, now time.Time) error
+}
+
+type redisVisitorRepository struct {
+	client *redis.Client
+}
+
+func NewRedisVisitorRepository(client *redis.Client) VisitorRepository {
+	return &redisVisitorRepository{
+		client: client,
+	}
+}
+
+// LoadVisitorSecret returns the secret visitor hashes are keyed with, creating
+// it on first use so that every instance hashes a visitor the same way.
+func LoadVisitorSecret(ctx context.Context, client *redis.Client) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	if err := client.SetNX(ctx, redisVisitorSecretKey, hex.EncodeToString(buf), 0).Err(); err != nil {
+		return "", err
+	}
+	return client.Get(ctx, redisVisitorSecretKey).Result()
+}
+
+// LoadIPSalt returns the salt client addresses are hashed with during the
+// rotation period starting at period, creating it on first use so that every
+// instance hashes an address the same way. The salt expires with its period,
+// after which its hashes can no longer be linked to addresses.
+func LoadIPSalt(ctx context.Context, client *redis.Client, period time.Time, rotation time.Duration) ([]byte, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	key := redisIPSaltPrefix + strconv.FormatInt(period.Unix(), 10)
+	ttl := time.Until(period.Add(rotation))
+	if ttl <= 0 {
+		ttl = rotation
+	}
+	if err := client.SetNX(ctx, key, hex.EncodeToString(buf), ttl).Err(); err != nil {
+		return nil, err
+	}
+	return client.Get(ctx, key).Bytes()
+}
+
+func (r *redisVisitorRepository) AddVisitor(ctx context.Context, shortURL, visitor string, at time.Time) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisVisitorRepository.AddVisitor", "PFADD")
+	defer telemetry.End(span, &err)
+
+	day := visitorDayKey(shortURL, at)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFAdd(ctx, visitorTotalKey(shortURL), visitor)
+		pipe.PFAdd(ctx, day, visitor)
+		// Keep the day for as long as it is part of the 30 day window.
+		pipe.ExpireAt(ctx, day, startOfDay(at).AddDate(0, 0, VisitorDays+1))
+		return nil
+	})
+	return err
+}
+
+func (r *redisVisitorRepository) GetUniqueVisitors(ctx context.Context, shortURL string, now time.Time) (_ *model.UniqueVisitors, err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisVisitorRepository.GetUniqueVisitors", "PFCOUNT")
+	defer telemetry.End(span, &err)
+
+	window := visitorDayKeys(shortURL, now, VisitorDays)
+	var total, week, month *redis.IntCmd
+	daily := make([]*redis.IntCmd, VisitorDays)
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		total = pipe.PFCount(ctx, visitorTotalKey(shortURL))
+		week = pipe.PFCount(ctx, window[:7]...)
+		month = pipe.PFCount(ctx, window...)
+		for i := range daily {
+			daily[i] = pipe.PFCount(ctx, window[VisitorDays-1-i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	visitors := &model.UniqueVisitors{
+		Total:      total.Val(),
+		Last7Days:  week.Val(),
+		Last30Days: month.Val(),
+		Daily:      make([]model.DailyCount, 0, VisitorDays),
+	}
+	for i, cmd := range daily {
+		visitors.Daily = append(visitors.Daily, model.DailyCount{
+			Date:  now.UTC().AddDate(0, 0, i-VisitorDays+1).Format(visitorDayFormat),
+			Count: cmd.Val(),
+		})
+	}
+	return visitors, nil
+}
+
+func (r *redisVisitorRepository) DeleteVisitors(ctx context.Context, shortURL string, now time.Time) (err error) {
+	ctx, span := startDBSpan(ctx, semconv.DBSystemRedis, "redisVisitorRepository.DeleteVisitors", "DEL")
+	defer telemetry.End(span, &err)
+
+	keys := append(visitorDayKeys(shortURL, now, VisitorDays+1), visitorTotalKey(shortURL))
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func visitorTotalKey(shortURL string) string {
+	return redisVisitorsPrefix + shortURL
+}
+
+func visitorDayKey(shortURL string, at time.Time) string {
+	return redisDayVisitorsPrefix + at.UTC().Format(visitorDayFormat) + ":" + shortURL
+}
+
+// visitorDayKeys returns the keys of the last days days up to now, most
+// recent first.
+func visitorDayKeys(shortURL string, now time.Time, days int) []string {
+	keys := make([]string, 0, days)
+	for i := range days {
+		keys = append(keys, visitorDayKey(shortURL, now.AddDate(0, 0, -i)))
+	}
+	return keys
+}
+
+func startOfDay(at time.Time) time.Time {
+	year, month, day := at.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,93 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/config"
+)
+
+func TestRedisVisitorRepository(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: cfg.RedisURL})
+	ctx := context.Background()
+	require.NoError(t, client.FlushDB(ctx).Err())
+	t.Cleanup(func() {
+		require.NoError(t, client.FlushDB(context.Background()).Err())
+		client.Close() //nolint:errcheck
+	})
+	repo := NewRedisVisitorRepository(client)
+
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		require.NoError(t, repo.AddVisitor(ctx, "ab", fmt.Sprintf("visitor-%d", i), now))
+	}
+	require.NoError(t, repo.AddVisitor(ctx, "ab", "visitor-0", now.AddDate(0, 0, -1)))
+	require.NoError(t, repo.AddVisitor(ctx, "ab", "visitor-9", now.AddDate(0, 0, -10)))
+	require.NoError(t, repo.AddVisitor(ctx, "cd", "visitor-0", now))
+
+	visitors, err := repo.GetUniqueVisitors(ctx, "ab", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), visitors.Total)
+	assert.Equal(t, int64(3), visitors.Last7Days)
+	assert.Equal(t, int64(4), visitors.Last30Days)
+	require.Len(t, visitors.Daily, VisitorDays)
+	assert.Equal(t, "2026-10-19", visitors.Daily[VisitorDays-1].Date)
+	assert.Equal(t, int64(3), visitors.Daily[VisitorDays-1].Count)
+	assert.Equal(t, int64(1), visitors.Daily[VisitorDays-2].Count)
+	assert.Equal(t, int64(1), visitors.Daily[VisitorDays-11].Count)
+
+	ttl, err := client.TTL(ctx, visitorDayKey("ab", now)).Result()
+	require.NoError(t, err)
+	assert.Positive(t, ttl)
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.DeleteVisitors(ctx, "ab", now))
+		visitors, err := repo.GetUniqueVisitors(ctx, "ab", now)
+		require.NoError(t, err)
+		assert.Zero(t, visitors.Total)
+		assert.Zero(t, visitors.Last30Days)
+
+		other, err := repo.GetUniqueVisitors(ctx, "cd", now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), other.Total)
+	})
+
+	t.Run("secret is shared", func(t *testing.T) {
+		first, err := LoadVisitorSecret(ctx, client)
+		require.NoError(t, err)
+		second, err := LoadVisitorSecret(ctx, client)
+		require.NoError(t, err)
+		assert.Len(t, first, 64)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("ip salt is shared per period", func(t *testing.T) {
+		period := time.Now().UTC().Truncate(time.Hour)
+		first, err := LoadIPSalt(ctx, client, period, time.Hour)
+		require.NoError(t, err)
+		second, err := LoadIPSalt(ctx, client, period, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		next, err := LoadIPSalt(ctx, client, period.Add(time.Hour), time.Hour)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, next)
+
+		ttl, err := client.TTL(ctx, redisIPSaltPrefix+strconv.FormatInt(period.Unix(), 10)).Result()
+		require.NoError(t, err)
+		assert.Positive(t, ttl)
+		assert.LessOrEqual(t, ttl, time.Hour)
+	})
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+// WebhookRepository stores the webhooks registered by API key owners and the
+// log of deliveries made to them. Webhooks are only visible to their owner.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, hook *model.Webhook) error
+	ListWebhooks(ctx context.Context, owner string) ([]*model.Webhook, error)
+	GetWebhook(ctx context.Context, owner string, id int64) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, owner string, id int64) error
+	// ListSubscribedWebhooks returns the owner's webhooks that receive
+	// eventType.
+	ListSubscribedWebhooks(ctx context.Context, owner, eventType string) ([]*model.Webhook, error)
+	// EnqueueDelivery schedules payload for delivery to a webhook. Enqueueing
+	// the same event twice is a no-op.
+	EnqueueDelivery(ctx context.Context, webhookID int64, event *model.Event, payload []byte) error
+	// ClaimDeliveries leases up to limit due deliveries for lease.
+	ClaimDeliveries(ctx context.Context, limit int32, lease time.Duration) ([]*model.WebhookDelivery, error)
+	// RecordAttempt stores the outcome of an attempt. Pending deliveries are
+	// tried again after retryIn.
+	RecordAttempt(ctx context.Context, id int64, status string, responseStatus int, cause string, retryIn time.Duration) error
+	ListDeliveries(ctx context.Context, webhookID int64, status string, limit int32) ([]*model.WebhookDelivery, error)
+	// RetryDelivery schedules a dead delivery for another round of attempts.
+	RetryDelivery(ctx context.Context, webhookID, id int64) error
+}
+
+type webhookRepository struct {
+	querier db.Querier
+}
+
+func NewWebhookRepository(conn *pgxpool.Pool) WebhookRepository {
+	return &webhookRepository{
+		querier: db.New(conn),
+	}
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, hook *model.Webhook) (err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.CreateWebhook", "CreateWebhook")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.CreateWebhook(ctx, db.CreateWebhookParams{
+		Owner:      hook.Owner,
+		Url:        hook.URL,
+		Secret:     hook.Secret,
+		EventTypes: nonNil(hook.EventTypes),
+	})
+	if err != nil {
+		return err
+	}
+	*hook = *toWebhook(row)
+	return nil
+}
+
+func (r *webhookRepository) ListWebhooks(ctx context.Context, owner string) (_ []*model.Webhook, err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.ListWebhooks", "ListWebhooks")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ListWebhooks(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhooks(rows), nil
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, owner string, id int64) (_ *model.Webhook, err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.GetWebhook", "GetWebhook")
+	defer telemetry.End(span, &err)
+
+	row, err := r.querier.GetWebhook(ctx, db.GetWebhookParams{ID: id, Owner: owner})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toWebhook(row), nil
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, owner string, id int64) (err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.DeleteWebhook", "DeleteWebhook")
+	defer telemetry.End(span, &err)
+
+	deleted, err := r.querier.DeleteWebhook(ctx, db.DeleteWebhookParams{ID: id, Owner: owner})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) ListSubscribedWebhooks(ctx context.Context, owner, eventType string) (_ []*model.Webhook, err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.ListSubscribedWebhooks", "ListSubscribedWebhooks")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ListSubscribedWebhooks(ctx, db.ListSubscribedWebhooksParams{
+		Owner:     owner,
+		EventType: eventType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toWebhooks(rows), nil
+}
+
+func (r *webhookRepository) EnqueueDelivery(ctx context.Context, webhookID int64, event *model.Event, payload []byte) (err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.EnqueueDelivery", "EnqueueWebhookDelivery")
+	defer telemetry.End(span, &err)
+
+	_, err = r.querier.EnqueueWebhookDelivery(ctx, db.EnqueueWebhookDeliveryParams{
+		WebhookID: webhookID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+	})
+	return err
+}
+
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, limit int32, lease time.Duration) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.ClaimDeliveries", "ClaimWebhookDeliveries")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*model.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			ID:        row.ID,
+			WebhookID: row.WebhookID,
+			EventID:   row.EventID,
+			EventType: row.EventType,
+			Payload:   row.Payload,
+			Status:    model.DeliveryPending,
+			Attempts:  int(row.Attempts),
+			URL:       row.Url,
+			Secret:    row.Secret,
+		})
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, id int64, status string, responseStatus int, cause string, retryIn time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.RecordAttempt", "RecordWebhookAttempt")
+	defer telemetry.End(span, &err)
+
+	return r.querier.RecordWebhookAttempt(ctx, db.RecordWebhookAttemptParams{
+		Status:         status,
+		ResponseStatus: pgtype.Int4{Int32: int32(responseStatus), Valid: responseStatus != 0},
+		LastError:      pgtype.Text{String: cause, Valid: cause != ""},
+		RetrySeconds:   retryIn.Seconds(),
+		ID:             id,
+	})
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID int64, status string, limit int32) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.ListDeliveries", "ListWebhookDeliveries")
+	defer telemetry.End(span, &err)
+
+	rows, err := r.querier.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID:  webhookID,
+		Status:     status,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*model.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			ID:             row.ID,
+			WebhookID:      row.WebhookID,
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			Payload:        row.Payload,
+			Status:         row.Status,
+			Attempts:       int(row.Attempts),
+			ResponseStatus: int(row.ResponseStatus.Int32),
+			LastError:      row.LastError.String,
+			NextAttemptAt:  row.NextAttemptAt.Time.Format(time.RFC3339),
+			CreatedAt:      row.CreatedAt.Time.Format(time.RFC3339),
+			UpdatedAt:      row.UpdatedAt.Time.Format(time.RFC3339),
+		})
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RetryDelivery(ctx context.Context, webhookID, id int64) (err error) {
+	ctx, span := startSpan(ctx, "webhookRepository.RetryDelivery", "RetryWebhookDelivery")
+	defer telemetry.End(span, &err)
+
+	updated, err := r.querier.RetryWebhookDelivery(ctx, db.RetryWebhookDeliveryParams{
+		ID:        id,
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrDeliveryNotRetryable
+	}
+	return nil
+}
+
+func toWebhook(row db.Webhook) *model.Webhook {
+	return &model.Webhook{
+		ID:         row.ID,
+		Owner:      row.Owner,
+		URL:        row.Url,
+		Secret:     row.Secret,
+		EventTypes: row.EventTypes,
+		CreatedAt:  row.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+func toWebhooks(rows []db.Webhook) []*model.Webhook {
+	hooks := make([]*model.Webhook, 0, len(rows))
+	for _, row := range rows {
+		hooks = append(hooks, toWebhook(row))
+	}
+	return hooks
+}
+
+var (
+	ErrWebhookNotFound = model.Error{
+		Message: "Webhook not found",
+	}
+	ErrDeliveryNotRetryable = model.Error{
+		Message: "Delivery not found or not dead",
+	}
+)
@@ -0,0 +1,95 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	runWithTestDb(t, func(_ *URLRepository) {
+		ctx := context.Background()
+		t.Cleanup(func() {
+			_, err := testPool.Exec(ctx, "TRUNCATE TABLE webhooks CASCADE")
+			require.NoError(t, err)
+		})
+		repo := NewWebhookRepository(testPool)
+
+		hook := &model.Webhook{Owner: "owner-1", URL: "https://example.com/hook", Secret: "whsec_test",
+			EventTypes: []string{model.EventLinkCreated}}
+		require.NoError(t, repo.CreateWebhook(ctx, hook))
+		require.NotZero(t, hook.ID)
+
+		t.Run("owners only see their webhooks", func(t *testing.T) {
+			_, err := repo.GetWebhook(ctx, "owner-2", hook.ID)
+			assert.ErrorIs(t, err, ErrWebhookNotFound)
+			assert.ErrorIs(t, repo.DeleteWebhook(ctx, "owner-2", hook.ID), ErrWebhookNotFound)
+
+			hooks, err := repo.ListWebhooks(ctx, "owner-1")
+			require.NoError(t, err)
+			require.Len(t, hooks, 1)
+			assert.Equal(t, []string{model.EventLinkCreated}, hooks[0].EventTypes)
+		})
+
+		t.Run("subscriptions", func(t *testing.T) {
+			hooks, err := repo.ListSubscribedWebhooks(ctx, "owner-1", model.EventLinkCreated)
+			require.NoError(t, err)
+			assert.Len(t, hooks, 1)
+
+			hooks, err = repo.ListSubscribedWebhooks(ctx, "owner-1", model.EventLinkDeleted)
+			require.NoError(t, err)
+			assert.Empty(t, hooks)
+		})
+
+		t.Run("delivery lifecycle", func(t *testing.T) {
+			event := &model.Event{ID: 42, Type: model.EventLinkCreated}
+			require.NoError(t, repo.EnqueueDelivery(ctx, hook.ID, event, []byte(`{"id":42}`)))
+			require.NoError(t, repo.EnqueueDelivery(ctx, hook.ID, event, []byte(`{"id":42}`)))
+
+			claimed, err := repo.ClaimDeliveries(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, claimed, 1)
+			assert.Equal(t, 1, claimed[0].Attempts)
+			assert.Equal(t, hook.URL, claimed[0].URL)
+			assert.Equal(t, hook.Secret, claimed[0].Secret)
+
+			claimed, err = repo.ClaimDeliveries(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			assert.Empty(t, claimed, "leased deliveries are not claimed again")
+
+			deliveries, err := repo.ListDeliveries(ctx, hook.ID, "", 10)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 1)
+			id := deliveries[0].ID
+
+			assert.ErrorIs(t, repo.RetryDelivery(ctx, hook.ID, id), ErrDeliveryNotRetryable)
+			require.NoError(t, repo.RecordAttempt(ctx, id, model.DeliveryDead, 503, "status 503", 0))
+
+			deliveries, err = repo.ListDeliveries(ctx, hook.ID, model.DeliveryDead, 10)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 1)
+			assert.Equal(t, 503, deliveries[0].ResponseStatus)
+			assert.Equal(t, "status 503", deliveries[0].LastError)
+
+			require.NoError(t, repo.RetryDelivery(ctx, hook.ID, id))
+			claimed, err = repo.ClaimDeliveries(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, claimed, 1)
+			assert.Equal(t, 1, claimed[0].Attempts)
+		})
+
+		t.Run("deleting a webhook drops its deliveries", func(t *testing.T) {
+			require.NoError(t, repo.DeleteWebhook(ctx, "owner-1", hook.ID))
+			deliveries, err := repo.ListDeliveries(ctx, hook.ID, "", 10)
+			require.NoError(t, err)
+			assert.Empty(t, deliveries)
+		})
+	})
+}
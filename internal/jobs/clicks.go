@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+// PruneInterval is how often expired click data is deleted.
+const PruneInterval = time.Hour
+
+const (
+	// rollupSettle keeps the newest events out of a rollup, so that events
+	// whose insert is still in flight are not skipped by the watermark.
+	rollupSettle = 10 * time.Second
+	// rollupMaxSpan bounds the events rolled up in a single transaction when
+	// catching up on a backlog.
+	rollupMaxSpan = time.Hour
+	// pruneBatchSize bounds the events deleted by a single statement.
+	pruneBatchSize = 10000
+)
+
+type ClickOptions struct {
+	// EventRetention is how long raw click events are kept once rolled up.
+	EventRetention time.Duration
+	// HourlyRetention is how long hourly click counts are kept. Daily and
+	// all-time counts are kept as long as their link.
+	HourlyRetention time.Duration
+}
+
+// Clicks rolls raw click events up into the aggregates stats are served from
+// and deletes the events and hourly counts that are past their retention.
+type Clicks struct {
+	analytics repository.AnalyticsRepository
+	opts      ClickOptions
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+func NewClicks(analytics repository.AnalyticsRepository, opts ClickOptions, logger *slog.Logger) *Clicks {
+	return &Clicks{
+		analytics: analytics,
+		opts:      opts,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// RollUp adds the events recorded since the last rollup to the aggregates,
+// in as many transactions as it takes to catch up.
+func (c *Clicks) RollUp(ctx context.Context) error {
+	for {
+		caughtUp, err := c.analytics.RollUpClicks(ctx, rollupSettle, rollupMaxSpan)
+		if err != nil || caughtUp {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Prune deletes rolled up events older than EventRetention and hourly counts
+// older than HourlyRetention. A zero retention keeps them forever.
+func (c *Clicks) Prune(ctx context.Context) error {
+	now := c.now()
+	if c.opts.EventRetention > 0 {
+		var total int64
+		for {
+			deleted, err := c.analytics.DeleteClickEvents(ctx, now.Add(-c.opts.EventRetention), pruneBatchSize)
+			if err != nil {
+				return err
+			}
+			total += deleted
+			if deleted < pruneBatchSize {
+				break
+			}
+		}
+		if total > 0 {
+			c.logger.Debug("Pruned click events", "count", total)
+		}
+	}
+
+	if c.opts.HourlyRetention > 0 {
+		deleted, err := c.analytics.DeleteHourlyClickStats(ctx, now.Add(-c.opts.HourlyRetention))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			c.logger.Debug("Pruned hourly click counts", "count", deleted)
+		}
+	}
+	return nil
+}
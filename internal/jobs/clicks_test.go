@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// fakeAnalytics is an AnalyticsRepository with a backlog of rollup spans and
+// of click events to delete.
+type fakeAnalytics struct {
+	backlog       int
+	rollups       int
+	rollupErr     error
+	events        int64
+	eventsBefore  time.Time
+	hourlyBefore  time.Time
+	deleteBatches int
+}
+
+func (f *fakeAnalytics) RecordClick(context.Context, *model.Click) error {
+	return nil
+}
+
+func (f *fakeAnalytics) GetClickAnalytics(context.Context, string, int32) (*model.ClickAnalytics, error) {
+	return &model.ClickAnalytics{}, nil
+}
+
+func (f *fakeAnalytics) RollUpClicks(_ context.Context, settle, maxSpan time.Duration) (bool, error) {
+	if f.rollupErr != nil {
+		return false, f.rollupErr
+	}
+	f.rollups++
+	if f.backlog > 0 {
+		f.backlog--
+	}
+	return f.backlog == 0, nil
+}
+
+func (f *fakeAnalytics) DeleteClickEvents(_ context.Context, before time.Time, limit int32) (int64, error) {
+	f.eventsBefore = before
+	f.deleteBatches++
+	deleted := min(f.events, int64(limit))
+	f.events -= deleted
+	return deleted, nil
+}
+
+func (f *fakeAnalytics) DeleteHourlyClickStats(_ context.Context, before time.Time) (int64, error) {
+	f.hourlyBefore = before
+	return 0, nil
+}
+
+func newTestClicks(analytics *fakeAnalytics, opts ClickOptions) *Clicks {
+	clicks := NewClicks(analytics, opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	clicks.now = func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	}
+	return clicks
+}
+
+func TestClicksRollUp(t *testing.T) {
+	t.Run("catches up on a backlog", func(t *testing.T) {
+		analytics := &fakeAnalytics{backlog: 3}
+		require.NoError(t, newTestClicks(analytics, ClickOptions{}).RollUp(context.Background()))
+		assert.Equal(t, 3, analytics.rollups)
+	})
+
+	t.Run("nothing to roll up", func(t *testing.T) {
+		analytics := &fakeAnalytics{}
+		require.NoError(t, newTestClicks(analytics, ClickOptions{}).RollUp(context.Background()))
+		assert.Equal(t, 1, analytics.rollups)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		analytics := &fakeAnalytics{backlog: 3, rollupErr: errors.New("deadlock detected")}
+		assert.Error(t, newTestClicks(analytics, ClickOptions{}).RollUp(context.Background()))
+	})
+}
+
+func TestClicksPrune(t *testing.T) {
+	t.Run("deletes in batches", func(t *testing.T) {
+		analytics := &fakeAnalytics{events: 2*pruneBatchSize + 1}
+		clicks := newTestClicks(analytics, ClickOptions{EventRetention: 24 * time.Hour, HourlyRetention: 48 * time.Hour})
+
+		require.NoError(t, clicks.Prune(context.Background()))
+
+		assert.Zero(t, analytics.events)
+		assert.Equal(t, 3, analytics.deleteBatches)
+		assert.Equal(t, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), analytics.eventsBefore)
+		assert.Equal(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), analytics.hourlyBefore)
+	})
+
+	t.Run("zero retention keeps everything", func(t *testing.T) {
+		analytics := &fakeAnalytics{events: 5}
+		require.NoError(t, newTestClicks(analytics, ClickOptions{}).Prune(context.Background()))
+
+		assert.Equal(t, int64(5), analytics.events)
+		assert.Zero(t, analytics.deleteBatches)
+		assert.True(t, analytics.hourlyBefore.IsZero())
+	})
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// Job is background work that is repeated at a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs every job once on start and then at its interval. Runs of the
+// same job never overlap; a run that takes longer than the interval delays
+// the next one.
+type Runner struct {
+	jobs   []Job
+	logger *slog.Logger
+}
+
+func NewRunner(logger *slog.Logger, jobs ...Job) *Runner {
+	return &Runner{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+// Add adds jobs to a runner that has not been started yet.
+func (r *Runner) Add(jobs ...Job) {
+	r.jobs = append(r.jobs, jobs...)
+}
+
+// Run runs the jobs until ctx is cancelled and waits for the runs in
+// progress to return.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range r.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs job and records its outcome.
+func (r *Runner) RunOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	err := job.Run(ctx)
+	metrics.JobDuration.WithLabelValues(job.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.JobRunsTotal.WithLabelValues(job.Name, metrics.JobFailed).Inc()
+		if ctx.Err() == nil {
+			r.logger.Error("Background job failed", "job", job.Name, "error", err)
+		}
+		return
+	}
+	metrics.JobRunsTotal.WithLabelValues(job.Name, metrics.JobSucceeded).Inc()
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+func TestRunner(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("runs jobs on start and at their interval", func(t *testing.T) {
+		var fast, slow atomic.Int32
+		runner := NewRunner(logger,
+			Job{Name: "fast", Interval: 10 * time.Millisecond, Run: func(context.Context) error {
+				fast.Add(1)
+				return nil
+			}},
+			Job{Name: "slow", Interval: time.Hour, Run: func(context.Context) error {
+				slow.Add(1)
+				return nil
+			}},
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		runner.Run(ctx)
+
+		assert.GreaterOrEqual(t, fast.Load(), int32(3))
+		assert.Equal(t, int32(1), slow.Load())
+	})
+
+	t.Run("records failures", func(t *testing.T) {
+		runner := NewRunner(logger)
+		before := testutil.ToFloat64(metrics.JobRunsTotal.WithLabelValues("failing", metrics.JobFailed))
+
+		runner.RunOnce(context.Background(), Job{Name: "failing", Run: func(context.Context) error {
+			return errors.New("database is down")
+		}})
+
+		assert.Equal(t, before+1, testutil.ToFloat64(metrics.JobRunsTotal.WithLabelValues("failing", metrics.JobFailed)))
+	})
+}
@@ -0,0 +1,243 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+	"github.com/unwale/url-shortener/internal/safehttp"
+)
+
+const (
+	// UserAgent identifies the checker to the servers it checks.
+	UserAgent = "url-shortener-link-checker/1.0"
+	// DefaultLease is how long a checker reserves a claimed batch by default.
+	DefaultLease = 15 * time.Minute
+)
+
+const (
+	// failingRecheck bounds the wait before a failing link is checked again,
+	// so that broken links are confirmed within hours rather than days.
+	failingRecheck = time.Hour
+	// maxResponseBytes bounds how much of a response is read before the
+	// connection is reused.
+	maxResponseBytes = 64 << 10
+	// blockedDestination is recorded for destinations on internal addresses,
+	// so that checks do not reveal what is listening there.
+	blockedDestination = "blocked destination"
+)
+
+type Options struct {
+	// BatchSize is how many links are checked per run.
+	BatchSize int32
+	// Concurrency is how many requests are in flight at once.
+	Concurrency int
+	// HostInterval is the least time between two requests to the same host.
+	HostInterval time.Duration
+	// Lease is how long claimed links are reserved for this checker. It must
+	// comfortably exceed the time it takes to check a batch.
+	Lease time.Duration
+	// MaxAge is how long a healthy link goes unchecked.
+	MaxAge time.Duration
+}
+
+// Checker checks that the destinations of links still respond. A destination
+// is failing when it cannot be reached, is gone (404, 410) or has a server
+// error; other responses, such as 401 or 429, show that it is still there.
+// Links are broken once BrokenAfterFailures checks in a row failed.
+//
+// The client should be one from safehttp.NewClient, so that links cannot be
+// used to probe internal services.
+type Checker struct {
+	repo   repository.LinkHealthRepository
+	client *http.Client
+	hosts  *hostLimiter
+	opts   Options
+	logger *slog.Logger
+}
+
+func NewChecker(repo repository.LinkHealthRepository, client *http.Client, opts Options, logger *slog.Logger) *Checker {
+	return &Checker{
+		repo:   repo,
+		client: client,
+		hosts:  newHostLimiter(opts.HostInterval),
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Run claims one batch of links that are due and checks them. Several
+// checkers may run at once; each claims its own batches.
+func (c *Checker) Run(ctx context.Context) error {
+	checks, err := c.repo.ClaimLinkChecks(ctx, c.opts.BatchSize, c.opts.Lease)
+	if err != nil {
+		return err
+	}
+
+	queue := make(chan *model.LinkCheck)
+	var wg sync.WaitGroup
+	for range min(max(c.opts.Concurrency, 1), len(checks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range queue {
+				c.check(ctx, check)
+			}
+		}()
+	}
+
+feed:
+	for _, check := range checks {
+		select {
+		case queue <- check:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (c *Checker) check(ctx context.Context, check *model.LinkCheck) {
+	result := &model.LinkCheckResult{
+		ShortUrl:   check.ShortUrl,
+		CheckedUrl: check.OriginalUrl,
+	}
+	status, err := c.probe(ctx, check.OriginalUrl)
+	if ctx.Err() != nil {
+		// The lease runs out and the link is checked again.
+		return
+	}
+	result.StatusCode = status
+	switch {
+	case errors.Is(err, safehttp.ErrNonPublicAddress):
+		result.Error = blockedDestination
+	case err != nil:
+		result.Error = err.Error()
+	case failing(status):
+		result.Error = http.StatusText(status)
+	default:
+		result.Healthy = true
+	}
+
+	recheckIn := c.opts.MaxAge
+	if result.Healthy {
+		metrics.LinkChecksTotal.WithLabelValues(metrics.LinkHealthy).Inc()
+	} else {
+		metrics.LinkChecksTotal.WithLabelValues(metrics.LinkFailed).Inc()
+		c.logger.Debug("Link check failed", "shortURL", check.ShortUrl, "status", status, "error", result.Error)
+		recheckIn = min(recheckIn, failingRecheck)
+	}
+	if err := c.repo.RecordLinkCheck(ctx, result, recheckIn); err != nil && ctx.Err() == nil {
+		c.logger.Error("Failed to record link check", "shortURL", check.ShortUrl, "error", err)
+	}
+}
+
+// probe requests target with HEAD, falling back to GET on an error status,
+// since some servers do not implement HEAD properly.
+func (c *Checker) probe(ctx context.Context, target string) (int, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return 0, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return 0, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	status, err := c.request(ctx, http.MethodHead, u)
+	if err != nil || status < http.StatusBadRequest {
+		return status, err
+	}
+	return c.request(ctx, http.MethodGet, u)
+}
+
+func (c *Checker) request(ctx context.Context, method string, u *url.URL) (int, error) {
+	if err := c.hosts.wait(ctx, u.Host); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()                                                 //nolint:errcheck
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes)) // allow the connection to be reused
+	return resp.StatusCode, nil
+}
+
+func failing(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone || status >= http.StatusInternalServerError
+}
+
+// hostLimiter spaces out requests to the same host by interval, across runs.
+// Workers waiting for a busy host hold their slot, so a batch dominated by one
+// host is checked at that host's pace.
+type hostLimiter struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	next  map[string]time.Time
+	swept time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// wait reserves the next request to host and waits for its turn.
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.sweep(now)
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sweep forgets hosts that may be requested again right away, so that the
+// limiter does not grow with every host ever checked.
+func (l *hostLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	for host, at := range l.next {
+		if at.Before(now) {
+			delete(l.next, host)
+		}
+	}
+	l.swept = now
+}
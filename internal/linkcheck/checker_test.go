@@ -0,0 +1,235 @@
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/safehttp"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// memoryLinks is a LinkHealthRepository that hands out its links once and
+// keeps the results recorded for them.
+type memoryLinks struct {
+	repository.LinkHealthRepository
+
+	mu      sync.Mutex
+	checks  []*model.LinkCheck
+	results map[string]*model.LinkCheckResult
+	recheck map[string]time.Duration
+}
+
+func newMemoryLinks(checks ...*model.LinkCheck) *memoryLinks {
+	return &memoryLinks{
+		checks:  checks,
+		results: make(map[string]*model.LinkCheckResult),
+		recheck: make(map[string]time.Duration),
+	}
+}
+
+func (m *memoryLinks) ClaimLinkChecks(_ context.Context, limit int32, _ time.Duration) ([]*model.LinkCheck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := min(int(limit), len(m.checks))
+	claimed := m.checks[:n]
+	m.checks = m.checks[n:]
+	return claimed, nil
+}
+
+func (m *memoryLinks) RecordLinkCheck(_ context.Context, result *model.LinkCheckResult, recheckIn time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[result.ShortUrl] = result
+	m.recheck[result.ShortUrl] = recheckIn
+	return nil
+}
+
+func testOptions() Options {
+	return Options{
+		BatchSize:   100,
+		Concurrency: 4,
+		Lease:       time.Minute,
+		MaxAge:      24 * time.Hour,
+	}
+}
+
+func TestChecker_Run(t *testing.T) {
+	var headRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, UserAgent, r.UserAgent())
+		if r.Method == http.MethodHead {
+			headRequests.Add(1)
+		}
+		switch r.URL.Path {
+		case "/ok":
+		case "/private":
+			w.WriteHeader(http.StatusForbidden)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		case "/error":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	links := newMemoryLinks(
+		&model.LinkCheck{ShortUrl: "ok", OriginalUrl: server.URL + "/ok"},
+		&model.LinkCheck{ShortUrl: "private", OriginalUrl: server.URL + "/private"},
+		&model.LinkCheck{ShortUrl: "nohead", OriginalUrl: server.URL + "/no-head"},
+		&model.LinkCheck{ShortUrl: "moved", OriginalUrl: server.URL + "/moved"},
+		&model.LinkCheck{ShortUrl: "gone", OriginalUrl: server.URL + "/gone"},
+		&model.LinkCheck{ShortUrl: "error", OriginalUrl: server.URL + "/error"},
+		&model.LinkCheck{ShortUrl: "down", OriginalUrl: closed.URL},
+		&model.LinkCheck{ShortUrl: "ftp", OriginalUrl: "ftp://example.com/file"},
+	)
+	checker := NewChecker(links, server.Client(), testOptions(), discard)
+	require.NoError(t, checker.Run(context.Background()))
+
+	for _, shortURL := range []string{"ok", "private", "nohead", "moved"} {
+		result := links.results[shortURL]
+		require.NotNil(t, result, shortURL)
+		assert.True(t, result.Healthy, shortURL)
+		assert.Empty(t, result.Error, shortURL)
+		assert.Equal(t, 24*time.Hour, links.recheck[shortURL], shortURL)
+	}
+	assert.Equal(t, http.StatusOK, links.results["nohead"].StatusCode, "falls back to GET")
+	assert.Equal(t, http.StatusOK, links.results["moved"].StatusCode, "follows redirects")
+
+	for shortURL, status := range map[string]int{"gone": 404, "error": 502, "down": 0, "ftp": 0} {
+		result := links.results[shortURL]
+		require.NotNil(t, result, shortURL)
+		assert.False(t, result.Healthy, shortURL)
+		assert.Equal(t, status, result.StatusCode, shortURL)
+		assert.NotEmpty(t, result.Error, shortURL)
+		assert.Equal(t, failingRecheck, links.recheck[shortURL], shortURL)
+	}
+	assert.Equal(t, server.URL+"/gone", links.results["gone"].CheckedUrl)
+	assert.Equal(t, int32(7), headRequests.Load(), "every link is checked with HEAD first")
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	links := newMemoryLinks(&model.LinkCheck{ShortUrl: "slow", OriginalUrl: server.URL})
+	client := server.Client()
+	client.Timeout = 50 * time.Millisecond
+	require.NoError(t, NewChecker(links, client, testOptions(), discard).Run(context.Background()))
+
+	result := links.results["slow"]
+	require.NotNil(t, result)
+	assert.False(t, result.Healthy)
+	assert.Contains(t, result.Error, "Timeout")
+}
+
+func TestChecker_Limits(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	requests := make(map[string][]time.Time)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		requests[r.Host] = append(requests[r.Host], time.Now())
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	var checks []*model.LinkCheck
+	for range 3 {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		for range 3 {
+			checks = append(checks, &model.LinkCheck{ShortUrl: server.URL, OriginalUrl: server.URL})
+		}
+	}
+	opts := testOptions()
+	opts.Concurrency = 2
+	opts.HostInterval = 50 * time.Millisecond
+	require.NoError(t, NewChecker(newMemoryLinks(checks...), http.DefaultClient, opts, discard).Run(context.Background()))
+
+	assert.LessOrEqual(t, maxInFlight, 2)
+	require.Len(t, requests, 3)
+	for host, times := range requests {
+		require.Len(t, times, 3, host)
+		for i := 1; i < len(times); i++ {
+			assert.GreaterOrEqual(t, times[i].Sub(times[i-1]), 45*time.Millisecond, "requests to %s are spaced out", host)
+		}
+	}
+}
+
+func TestChecker_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	links := newMemoryLinks(&model.LinkCheck{ShortUrl: "ab", OriginalUrl: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, NewChecker(links, server.Client(), testOptions(), discard).Run(ctx), context.Canceled)
+	assert.Empty(t, links.results, "unfinished checks are not recorded")
+}
+
+func TestChecker_BlockedDestination(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	links := newMemoryLinks(&model.LinkCheck{ShortUrl: "ab", OriginalUrl: server.URL})
+	require.NoError(t, NewChecker(links, safehttp.NewClient(time.Second), testOptions(), discard).Run(context.Background()))
+
+	result := links.results["ab"]
+	require.NotNil(t, result)
+	assert.False(t, result.Healthy)
+	assert.Zero(t, result.StatusCode)
+	assert.Equal(t, blockedDestination, result.Error, "the test server listens on a loopback address")
+}
+
+func TestChecker_HostIntervalAcrossRuns(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	links := newMemoryLinks()
+	opts := testOptions()
+	opts.HostInterval = 50 * time.Millisecond
+	checker := NewChecker(links, server.Client(), opts, discard)
+	for _, shortURL := range []string{"ab", "cd"} {
+		links.checks = []*model.LinkCheck{{ShortUrl: shortURL, OriginalUrl: server.URL}}
+		require.NoError(t, checker.Run(context.Background()))
+	}
+
+	require.Len(t, times, 2)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), 45*time.Millisecond, "the next run waits for the host")
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+const (
+	// UserAgent identifies the fetcher to the pages it reads.
+	UserAgent = "url-shortener-metadata-fetcher/1.0"
+	// DefaultQueueSize is how many links wait to be read before new ones are
+	// dropped.
+	DefaultQueueSize = 1000
+)
+
+type Options struct {
+	// Workers is how many pages are read at once.
+	Workers int
+	// QueueSize bounds the links waiting to be read. Links enqueued while it
+	// is full are dropped.
+	QueueSize int
+	// MaxBytes bounds how much of a page is read.
+	MaxBytes int64
+}
+
+type request struct {
+	shortURL    string
+	destination string
+}
+
+// Fetcher reads the title, description, favicon and OpenGraph properties of
+// the pages links point to in the background and stores them, or the reason
+// they could not be read, on the link. Links waiting in the queue are lost
+// when the process stops.
+type Fetcher struct {
+	repo   repository.LinkMetadataRepository
+	client *http.Client
+	opts   Options
+	logger *slog.Logger
+	queue  chan request
+}
+
+func NewFetcher(repo repository.LinkMetadataRepository, client *http.Client, opts Options, logger *slog.Logger) *Fetcher {
+	return &Fetcher{
+		repo:   repo,
+		client: client,
+		opts:   opts,
+		logger: logger,
+		queue:  make(chan request, opts.QueueSize),
+	}
+}
+
+// Enqueue schedules reading the page destination points to. It never blocks.
+func (f *Fetcher) Enqueue(shortURL, destination string) {
+	select {
+	case f.queue <- request{shortURL: shortURL, destination: destination}:
+	default:
+		metrics.MetadataFetchesTotal.WithLabelValues(metrics.MetadataDropped).Inc()
+		f.logger.Warn("Metadata queue is full, not fetching link metadata", "shortURL", shortURL)
+	}
+}
+
+// Run reads enqueued pages until ctx is cancelled.
+func (f *Fetcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(f.opts.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case req := <-f.queue:
+					f.process(ctx, req)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (f *Fetcher) process(ctx context.Context, req request) {
+	metadata := f.Fetch(ctx, req.shortURL, req.destination)
+	if ctx.Err() != nil {
+		return
+	}
+	if metadata.Error != "" {
+		metrics.MetadataFetchesTotal.WithLabelValues(metrics.MetadataFailed).Inc()
+		f.logger.Debug("Failed to fetch link metadata", "shortURL", req.shortURL, "error", metadata.Error)
+	} else {
+		metrics.MetadataFetchesTotal.WithLabelValues(metrics.MetadataFetched).Inc()
+	}
+	if err := f.repo.SaveLinkMetadata(ctx, metadata); err != nil && ctx.Err() == nil {
+		f.logger.Error("Failed to save link metadata", "shortURL", req.shortURL, "error", err)
+	}
+}
+
+// Fetch reads the metadata of the page destination points to. Pages that
+// cannot be read are reported in the Error of the result.
+func (f *Fetcher) Fetch(ctx context.Context, shortURL, destination string) *model.LinkMetadata {
+	metadata := &model.LinkMetadata{
+		ShortUrl:   shortURL,
+		FetchedUrl: destination,
+	}
+	page, err := f.read(ctx, destination)
+	if err != nil {
+		metadata.Error = err.Error()
+		return metadata
+	}
+	metadata.Title = page.Title
+	metadata.Description = page.Description
+	metadata.FaviconUrl = page.Favicon
+	metadata.OpenGraph = page.OpenGraph
+	return metadata
+}
+
+func (f *Fetcher) read(ctx context.Context, destination string) (*Page, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("destination responded with status %d", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("destination is not an HTML page but %q", mediaType)
+	}
+
+	// Metadata is in the head, so a page cut off at MaxBytes is parsed as is.
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.opts.MaxBytes), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(body, resp.Request.URL), nil
+}
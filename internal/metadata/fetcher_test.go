@@ -0,0 +1,135 @@
+package metadata
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/safehttp"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// memoryMetadata is a LinkMetadataRepository that keeps what is saved.
+type memoryMetadata struct {
+	repository.LinkMetadataRepository
+
+	mu    sync.Mutex
+	saved map[string]*model.LinkMetadata
+}
+
+func (m *memoryMetadata) SaveLinkMetadata(_ context.Context, metadata *model.LinkMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved[metadata.ShortUrl] = metadata
+	return nil
+}
+
+func (m *memoryMetadata) get(shortURL string) *model.LinkMetadata {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saved[shortURL]
+}
+
+func testOptions() Options {
+	return Options{Workers: 2, QueueSize: 10, MaxBytes: 64 << 10}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, UserAgent, r.UserAgent())
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, `<html><head><title>Page</title><meta property="og:image" content="/cover.png"></head></html>`) //nolint:errcheck
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			w.Write([]byte("<title>Caf\xe9</title>")) //nolint:errcheck
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/huge":
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, "<title>Huge</title><!--"+strings.Repeat("x", 1<<20)) //nolint:errcheck
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(nil, server.Client(), testOptions(), discard)
+	ctx := context.Background()
+
+	metadata := fetcher.Fetch(ctx, "ab", server.URL+"/moved")
+	assert.Empty(t, metadata.Error)
+	assert.Equal(t, "ab", metadata.ShortUrl)
+	assert.Equal(t, server.URL+"/moved", metadata.FetchedUrl)
+	assert.Equal(t, "Page", metadata.Title)
+	assert.Equal(t, server.URL+"/favicon.ico", metadata.FaviconUrl)
+	assert.Equal(t, map[string]string{"image": "/cover.png"}, metadata.OpenGraph)
+
+	assert.Equal(t, "Café", fetcher.Fetch(ctx, "ab", server.URL+"/latin1").Title, "pages are decoded")
+	assert.Equal(t, "Huge", fetcher.Fetch(ctx, "ab", server.URL+"/huge").Title, "pages are cut off")
+
+	for path, message := range map[string]string{
+		"/missing": "status 404",
+		"/image":   "not an HTML page",
+	} {
+		metadata := fetcher.Fetch(ctx, "ab", server.URL+path)
+		assert.Contains(t, metadata.Error, message, path)
+		assert.Empty(t, metadata.Title, path)
+	}
+	assert.Contains(t, fetcher.Fetch(ctx, "ab", "ftp://example.com").Error, "unsupported scheme")
+}
+
+func TestFetcher_Run(t *testing.T) {
+	server := newTestServer(t)
+	repo := &memoryMetadata{saved: make(map[string]*model.LinkMetadata)}
+	opts := testOptions()
+	opts.QueueSize = 2
+	fetcher := NewFetcher(repo, server.Client(), opts, discard)
+
+	fetcher.Enqueue("ab", server.URL+"/page")
+	fetcher.Enqueue("cd", server.URL+"/missing")
+	fetcher.Enqueue("ef", server.URL+"/page")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		fetcher.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return repo.get("ab") != nil && repo.get("cd") != nil
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, "Page", repo.get("ab").Title)
+	assert.Contains(t, repo.get("cd").Error, "404")
+	assert.Nil(t, repo.get("ef"), "links enqueued while the queue is full are dropped")
+}
+
+func TestFetcher_NonPublicAddress(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(nil, safehttp.NewClient(time.Second), testOptions(), discard)
+
+	metadata := fetcher.Fetch(context.Background(), "ab", server.URL+"/page")
+	assert.Contains(t, metadata.Error, safehttp.ErrNonPublicAddress.Error(), "the test server listens on a loopback address")
+}
@@ -0,0 +1,141 @@
+package metadata
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength = 300
+	maxValueLength = 1000
+	maxProperties  = 32
+)
+
+// Page is the metadata found in the head of an HTML page.
+type Page struct {
+	Title       string
+	Description string
+	Favicon     string
+	// OpenGraph maps og:* properties, without the prefix, to their first
+	// value.
+	OpenGraph map[string]string
+}
+
+// Parse reads the metadata of the HTML page in r, stopping at its body.
+// Without a <title> or description, the OpenGraph ones are used, and without
+// an icon link, the favicon is assumed at /favicon.ico. Relative URLs are
+// resolved against base.
+func Parse(r io.Reader, base *url.URL) *Page {
+	page := &Page{OpenGraph: make(map[string]string)}
+	var title strings.Builder
+	inTitle, titled := false, false
+
+	z := html.NewTokenizer(r)
+parse:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break parse
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				break parse
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				break parse
+			case atom.Title:
+				inTitle, titled = !titled, true
+			case atom.Meta:
+				page.addMeta(attributes(z))
+			case atom.Link:
+				page.addLink(attributes(z), base)
+			}
+		}
+	}
+
+	page.Title = clean(title.String(), maxTitleLength)
+	if page.Title == "" {
+		page.Title = clean(page.OpenGraph["title"], maxTitleLength)
+	}
+	if page.Description == "" {
+		page.Description = page.OpenGraph["description"]
+	}
+	if page.Favicon == "" && base != nil {
+		page.Favicon = (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/favicon.ico"}).String()
+	}
+	return page
+}
+
+func (p *Page) addMeta(attrs map[string]string) {
+	content := clean(attrs["content"], maxValueLength)
+	if content == "" {
+		return
+	}
+	// og:* tags are meant to use property, but name is common too.
+	key := strings.ToLower(attrs["property"])
+	if key == "" {
+		key = strings.ToLower(attrs["name"])
+	}
+	if property, ok := strings.CutPrefix(key, "og:"); ok {
+		if _, seen := p.OpenGraph[property]; !seen && property != "" && len(p.OpenGraph) < maxProperties {
+			p.OpenGraph[property] = content
+		}
+		return
+	}
+	if key == "description" && p.Description == "" {
+		p.Description = content
+	}
+}
+
+func (p *Page) addLink(attrs map[string]string, base *url.URL) {
+	if p.Favicon != "" || base == nil {
+		return
+	}
+	for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+		if rel != "icon" {
+			continue
+		}
+		icon, err := base.Parse(strings.TrimSpace(attrs["href"]))
+		if err == nil && (icon.Scheme == "http" || icon.Scheme == "https") {
+			p.Favicon = icon.String()
+		}
+		return
+	}
+}
+
+func attributes(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, value, more := z.TagAttr()
+		if _, seen := attrs[string(key)]; !seen && len(key) > 0 {
+			attrs[string(key)] = string(value)
+		}
+		if !more {
+			return attrs
+		}
+	}
+}
+
+// clean collapses whitespace and cuts s to at most limit characters.
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(strings.ToValidUTF8(s, "")), " ")
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
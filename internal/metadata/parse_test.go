@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	t.Run("head", func(t *testing.T) {
+		page := Parse(strings.NewReader(`<!DOCTYPE html>
+<html><head>
+  <meta charset="utf-8">
+  <title>
+    Tips &amp; Tricks
+  </title>
+  <meta name="description" content="Ten tips">
+  <meta property="og:title" content="Tips and Tricks">
+  <meta property="OG:Image" content="https://cdn.example.com/cover.png">
+  <meta name="og:site_name" content="Example Blog">
+  <meta property="og:title" content="A second title">
+  <link rel="shortcut icon" href="/static/icon.png">
+  <link rel="icon" href="/other.png">
+</head>
+<body><meta property="og:description" content="not in the head"></body>
+</html>`), base)
+
+		assert.Equal(t, "Tips & Tricks", page.Title)
+		assert.Equal(t, "Ten tips", page.Description)
+		assert.Equal(t, "https://example.com/static/icon.png", page.Favicon)
+		assert.Equal(t, map[string]string{
+			"title":     "Tips and Tricks",
+			"image":     "https://cdn.example.com/cover.png",
+			"site_name": "Example Blog",
+		}, page.OpenGraph)
+	})
+
+	t.Run("falls back to OpenGraph and /favicon.ico", func(t *testing.T) {
+		page := Parse(strings.NewReader(`<meta property="og:title" content="Launch">
+<meta property="og:description" content="We launched">`), base)
+
+		assert.Equal(t, "Launch", page.Title)
+		assert.Equal(t, "We launched", page.Description)
+		assert.Equal(t, "https://example.com/favicon.ico", page.Favicon)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		var html strings.Builder
+		html.WriteString("<title>" + strings.Repeat("a", 2*maxTitleLength) + "</title>")
+		for i := range 2 * maxProperties {
+			html.WriteString(`<meta property="og:p` + string(rune('a'+i%26)) + string(rune('a'+i/26)) + `" content="x">`)
+		}
+		html.WriteString(`<link rel="icon" href="javascript:alert(1)">`)
+		page := Parse(strings.NewReader(html.String()), base)
+
+		assert.Len(t, page.Title, maxTitleLength)
+		assert.Len(t, page.OpenGraph, maxProperties)
+		assert.Equal(t, "https://example.com/favicon.ico", page.Favicon, "only http(s) icons are kept")
+	})
+
+	t.Run("not HTML", func(t *testing.T) {
+		page := Parse(strings.NewReader("\x00\x01binary"), base)
+		assert.Empty(t, page.Title)
+		assert.Empty(t, page.OpenGraph)
+	})
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "url_shortener"
+
+const (
+	CacheHit         = "hit"
+	CacheNegativeHit = "negative_hit"
+	CacheMiss        = "miss"
+	CacheBypass      = "bypass"
+	CacheError       = "error"
+
+	OutboxDelivered = "delivered"
+	OutboxFailed    = "failed"
+
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+	WebhookDead      = "dead"
+
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+
+	LinkHealthy = "healthy"
+	LinkFailed  = "failed"
+
+	MetadataFetched = "fetched"
+	MetadataFailed  = "failed"
+	MetadataDropped = "dropped"
+)
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route, method and status code.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"route", "method", "status"})
+
+	RedirectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Number of short links resolved to a redirect.",
+	})
+
+	CacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups while resolving short links by result (hit, negative_hit, miss, bypass, error).",
+	}, []string{"result"})
+
+	LocalCacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "local_cache_requests_total",
+		Help:      "Number of lookups in the in-process cache tier by result (hit, miss).",
+	}, []string{"result"})
+
+	LocalCacheInvalidationsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "local_cache_invalidations_total",
+		Help:      "Number of invalidation messages applied to the in-process cache tier.",
+	})
+
+	CacheCircuitState = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_circuit_state",
+		Help:      "State of the circuit breaker around the shared cache (0 closed, 1 half-open, 2 open).",
+	})
+
+	CacheCircuitRejectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_circuit_rejections_total",
+		Help:      "Number of cache calls skipped because the circuit breaker was open.",
+	})
+
+	ReplicaLagSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_replica_lag_seconds",
+		Help:      "Replication lag of each Postgres read replica.",
+	}, []string{"replica"})
+
+	ReplicaFallbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_replica_fallbacks_total",
+		Help:      "Number of reads retried on the primary after a replica failed.",
+	}, []string{"replica"})
+
+	OutboxDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_deliveries_total",
+		Help:      "Number of outbox events sent to each sink by result (delivered, failed).",
+	}, []string{"sink", "result"})
+
+	WebhookAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_attempts_total",
+		Help:      "Number of webhook delivery attempts by result (delivered, failed, dead).",
+	}, []string{"result"})
+
+	TargetedRedirectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "targeted_redirects_total",
+		Help:      "Number of redirects sent to the destination of a routing rule.",
+	})
+
+	SplitRedirectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "split_redirects_total",
+		Help:      "Number of redirects sent to a variant of a split link.",
+	})
+
+	BotClicksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_clicks_total",
+		Help:      "Number of resolves by crawlers, link previews and prefetches, which are not counted as clicks.",
+	})
+
+	ClickIncrementFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_increment_failures_total",
+		Help:      "Number of click count updates that failed.",
+	})
+
+	JobRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Number of background job runs by job and result (succeeded, failed).",
+	}, []string{"job", "result"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of background job runs by job.",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+
+	LinkChecksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "link_checks_total",
+		Help:      "Number of link destination checks by result (healthy, failed).",
+	}, []string{"result"})
+
+	FallbackRedirectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fallback_redirects_total",
+		Help:      "Number of redirects of broken links sent to the fallback URL.",
+	})
+
+	PreviewCardsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "preview_cards_total",
+		Help:      "Number of custom preview cards served to link unfurlers instead of a redirect.",
+	})
+
+	MetadataFetchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "metadata_fetches_total",
+		Help:      "Number of destination pages read for link metadata by result (fetched, failed, dropped).",
+	}, []string{"result"})
+
+	PanicsRecoveredTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_recovered_total",
+		Help:      "Number of handler panics turned into 500 responses.",
+	})
+)
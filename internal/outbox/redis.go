@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// DefaultStreamMaxLen caps the Redis stream at roughly this many entries.
+const DefaultStreamMaxLen = 100000
+
+// RedisStreamSink appends events to a Redis stream, one field per message
+// attribute.
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink(client *redis.Client, stream string) *RedisStreamSink {
+	return &RedisStreamSink{
+		client: client,
+		stream: stream,
+		maxLen: DefaultStreamMaxLen,
+	}
+}
+
+func (s *RedisStreamSink) Name() string {
+	return "redis"
+}
+
+func (s *RedisStreamSink) Send(ctx context.Context, event *model.Event) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":          strconv.FormatInt(event.ID, 10),
+			"type":        event.Type,
+			"short_url":   event.ShortUrl,
+			"occurred_at": event.OccurredAt,
+			"data":        string(event.Data),
+		},
+	}).Err()
+}
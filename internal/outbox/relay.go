@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+)
+
+// DefaultLease is how long a relay reserves a claimed batch by default.
+const DefaultLease = time.Minute
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 10 * time.Minute
+	// pruneInterval spaces out deletes of delivered events, which scan the
+	// whole outbox.
+	pruneInterval = time.Minute
+)
+
+type RelayOptions struct {
+	BatchSize    int32
+	PollInterval time.Duration
+	// Lease is how long a claimed batch is reserved for this relay. It must
+	// comfortably exceed the time it takes to deliver a batch.
+	Lease time.Duration
+	// MaxAttempts is how often an event is tried before it is left in the
+	// outbox for manual inspection.
+	MaxAttempts int32
+	// Retention is how long delivered events are kept.
+	Retention time.Duration
+}
+
+// Relay moves events from the outbox to every sink. An event is marked
+// delivered only after all sinks accepted it, so a failing sink makes the
+// others see the event again on the next attempt.
+type Relay struct {
+	outbox repository.OutboxRepository
+	sinks  []Sink
+	opts   RelayOptions
+	logger *slog.Logger
+}
+
+func NewRelay(outbox repository.OutboxRepository, sinks []Sink, opts RelayOptions, logger *slog.Logger) *Relay {
+	return &Relay{
+		outbox: outbox,
+		sinks:  sinks,
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Run relays events until ctx is cancelled. Several relays may run against
+// the same outbox; each claims its own batches.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		relayed, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to relay outbox events", "error", err)
+		}
+		if relayed == 0 && err == nil && time.Since(pruned) >= pruneInterval {
+			r.prune(ctx)
+			pruned = time.Now()
+		}
+
+		// Keep draining while there is a backlog.
+		if relayed < int(r.opts.BatchSize) || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// RelayBatch claims one batch of pending events and delivers it, returning
+// how many events were claimed.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	events, err := r.outbox.ClaimEvents(ctx, r.opts.BatchSize, r.opts.Lease, r.opts.MaxAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, event := range events {
+		if err := r.deliver(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(events), errors.Join(errs...)
+}
+
+func (r *Relay) deliver(ctx context.Context, event *model.Event) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			metrics.OutboxDeliveriesTotal.WithLabelValues(sink.Name(), metrics.OutboxFailed).Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		metrics.OutboxDeliveriesTotal.WithLabelValues(sink.Name(), metrics.OutboxDelivered).Inc()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		if event.Attempts >= int(r.opts.MaxAttempts) {
+			r.logger.Error("Giving up on outbox event", "id", event.ID, "type", event.Type,
+				"attempts", event.Attempts, "error", err)
+		} else {
+			r.logger.Warn("Failed to deliver outbox event", "id", event.ID, "type", event.Type,
+				"attempts", event.Attempts, "error", err)
+		}
+		return r.outbox.MarkFailed(ctx, event.ID, err.Error(), retryDelay(event.Attempts))
+	}
+	return r.outbox.MarkDelivered(ctx, event.ID)
+}
+
+func (r *Relay) prune(ctx context.Context) {
+	if r.opts.Retention <= 0 {
+		return
+	}
+	deleted, err := r.outbox.DeleteDelivered(ctx, time.Now().Add(-r.opts.Retention))
+	if err != nil && ctx.Err() == nil {
+		r.logger.Error("Failed to prune delivered outbox events", "error", err)
+	} else if deleted > 0 {
+		r.logger.Debug("Pruned delivered outbox events", "count", deleted)
+	}
+}
+
+// retryDelay doubles with every attempt, from minRetryDelay up to
+// maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
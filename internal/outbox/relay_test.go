@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// memoryOutbox is an OutboxRepository that keeps events in a slice and
+// ignores leases.
+type memoryOutbox struct {
+	mu        sync.Mutex
+	events    []*model.Event
+	delivered map[int64]bool
+	failed    map[int64]time.Duration
+}
+
+func newMemoryOutbox(events ...*model.Event) *memoryOutbox {
+	return &memoryOutbox{
+		events:    events,
+		delivered: make(map[int64]bool),
+		failed:    make(map[int64]time.Duration),
+	}
+}
+
+func (o *memoryOutbox) AddEvent(_ context.Context, event *model.Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	return nil
+}
+
+func (o *memoryOutbox) ClaimEvents(_ context.Context, limit int32, _ time.Duration, maxAttempts int32) ([]*model.Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var claimed []*model.Event
+	for _, event := range o.events {
+		if len(claimed) == int(limit) {
+			break
+		}
+		if o.delivered[event.ID] || event.Attempts >= int(maxAttempts) {
+			continue
+		}
+		event.Attempts++
+		claimed = append(claimed, event)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) MarkDelivered(_ context.Context, id int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.delivered[id] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(_ context.Context, id int64, _ string, retryIn time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failed[id] = retryIn
+	return nil
+}
+
+func (o *memoryOutbox) DeleteDelivered(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type recordingSink struct {
+	name string
+	err  error
+	sent []int64
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Send(_ context.Context, event *model.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, event.ID)
+	return nil
+}
+
+func newTestRelay(o *memoryOutbox, sinks ...Sink) *Relay {
+	return NewRelay(o, sinks, RelayOptions{
+		BatchSize:    10,
+		PollInterval: time.Millisecond,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRelay_DeliversToEverySink(t *testing.T) {
+	o := newMemoryOutbox(
+		&model.Event{ID: 1, Type: model.EventLinkCreated, ShortUrl: "exmpl"},
+		&model.Event{ID: 2, Type: model.EventLinkDeleted, ShortUrl: "exmpl"},
+	)
+	first := &recordingSink{name: "first"}
+	second := &recordingSink{name: "second"}
+
+	relayed, err := newTestRelay(o, first, second).RelayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, relayed)
+	assert.Equal(t, []int64{1, 2}, first.sent)
+	assert.Equal(t, []int64{1, 2}, second.sent)
+	assert.True(t, o.delivered[1])
+	assert.True(t, o.delivered[2])
+}
+
+func TestRelay_RetriesWhenASinkFails(t *testing.T) {
+	o := newMemoryOutbox(&model.Event{ID: 1, Type: model.EventLinkCreated, ShortUrl: "exmpl"})
+	healthy := &recordingSink{name: "healthy"}
+	failing := &recordingSink{name: "failing", err: errors.New("connection refused")}
+	relay := newTestRelay(o, healthy, failing)
+
+	_, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err, "failed deliveries are scheduled for a retry")
+	assert.False(t, o.delivered[1])
+	assert.Equal(t, time.Second, o.failed[1])
+
+	failing.err = nil
+	_, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, o.delivered[1])
+	assert.Equal(t, []int64{1, 1}, healthy.sent, "healthy sinks see the event again")
+}
+
+func TestRelay_StopsAfterMaxAttempts(t *testing.T) {
+	o := newMemoryOutbox(&model.Event{ID: 1, Type: model.EventLinkCreated, ShortUrl: "exmpl"})
+	relay := newTestRelay(o, &recordingSink{name: "failing", err: errors.New("connection refused")})
+
+	for range 5 {
+		_, _ = relay.RelayBatch(context.Background())
+	}
+
+	relayed, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, relayed)
+	assert.Equal(t, 3, o.events[0].Attempts)
+}
+
+func TestRelay_RunStopsWithContext(t *testing.T) {
+	o := newMemoryOutbox(&model.Event{ID: 1, Type: model.EventLinkCreated, ShortUrl: "exmpl"})
+	sink := &recordingSink{name: "sink"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		newTestRelay(o, sink).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return o.delivered[1]
+	}, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, retryDelay(1))
+	assert.Equal(t, 2*time.Second, retryDelay(2))
+	assert.Equal(t, 8*time.Second, retryDelay(4))
+	assert.Equal(t, maxRetryDelay, retryDelay(50))
+}
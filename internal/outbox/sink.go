@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// Sink delivers events to a downstream system. Events are delivered at least
+// once, so consumers should deduplicate them by ID.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *model.Event) error
+}
+
+// Message is the wire format of an event.
+type Message struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	ShortURL   string          `json:"short_url"`
+	OccurredAt string          `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewMessage(event *model.Event) Message {
+	return Message{
+		ID:         event.ID,
+		Type:       event.Type,
+		ShortURL:   event.ShortUrl,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Data,
+	}
+}
+
+// WriterSink writes events as JSON lines, e.g. to stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		w: w,
+	}
+}
+
+func (s *WriterSink) Name() string {
+	return "stdout"
+}
+
+func (s *WriterSink) Send(_ context.Context, event *model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(NewMessage(event))
+}
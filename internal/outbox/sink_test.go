@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+var testEvent = &model.Event{
+	ID:         42,
+	Type:       model.EventLinkCreated,
+	ShortUrl:   "exmpl",
+	Data:       json.RawMessage(`{"original_url":"https://google.com"}`),
+	OccurredAt: "2025-01-02T03:04:05Z",
+}
+
+const testMessage = `{"id":42,"type":"link.created","short_url":"exmpl","occurred_at":"2025-01-02T03:04:05Z","data":{"original_url":"https://google.com"}}`
+
+func TestWebhookSink(t *testing.T) {
+	t.Run("delivers event", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		err := NewWebhookSink(server.URL, server.Client()).Send(context.Background(), testEvent)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "42", received.Header.Get("X-Event-ID"))
+		assert.Equal(t, "link.created", received.Header.Get("X-Event-Type"))
+		assert.JSONEq(t, testMessage, string(body))
+	})
+
+	t.Run("rejected event", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := NewWebhookSink(server.URL, server.Client()).Send(context.Background(), testEvent)
+
+		assert.ErrorContains(t, err, "status 500")
+	})
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, NewWriterSink(&buf).Send(context.Background(), testEvent))
+
+	assert.JSONEq(t, testMessage, buf.String())
+	assert.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+}
@@ -0,0 +1,251 @@
+package privacy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Modes of anonymizing client addresses.
+const (
+	IPModeNone     = "none"
+	IPModeTruncate = "truncate"
+	IPModeHash     = "hash"
+)
+
+const saltSize = 32
+
+const (
+	// saltLoadTimeout bounds loading a salt. Loads are detached from the
+	// request that needs the salt, so that one cancelled request does not
+	// decide the salt of everyone else.
+	saltLoadTimeout = 2 * time.Second
+	// saltRetryInterval is how long a local salt stands in for a shared one
+	// that could not be loaded before loading it is tried again.
+	saltRetryInterval = time.Minute
+	// saltPrefetchLead is how long before the end of a period the salt of
+	// the next one is loaded, so that requests do not wait for it.
+	saltPrefetchLead = time.Minute
+)
+
+// SaltSource returns the salt of the rotation period starting at period. It
+// must return the same salt every time it is asked for the same period.
+type SaltSource func(ctx context.Context, period time.Time) ([]byte, error)
+
+// NewLocalSalts returns a SaltSource that generates a random salt for every
+// period, so addresses are hashed differently by every instance. The salts of
+// the two latest periods asked for are kept, which covers the current period
+// and the prefetched next one.
+func NewLocalSalts() SaltSource {
+	var (
+		mu     sync.Mutex
+		recent []periodSalt
+	)
+	return func(_ context.Context, period time.Time) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, s := range recent {
+			if s.of(period) {
+				return s.salt, nil
+			}
+		}
+		salt := newSalt()
+		recent = append(recent, periodSalt{period: period, salt: salt})
+		slices.SortFunc(recent, func(a, b periodSalt) int {
+			return a.period.Compare(b.period)
+		})
+		if len(recent) > 2 {
+			recent = recent[len(recent)-2:]
+		}
+		return salt, nil
+	}
+}
+
+// IPAnonymizer hides client addresses before they are logged or used to tell
+// visitors apart. Truncation keeps the network of an address, which is still
+// enough to look up its country; hashing replaces it with a keyed hash whose
+// salt rotates, so hashes of different periods cannot be linked. A nil
+// IPAnonymizer leaves addresses unchanged.
+type IPAnonymizer struct {
+	mode     string
+	rotation time.Duration
+	salts    SaltSource
+	logger   *slog.Logger
+	now      func() time.Time
+	loads    singleflight.Group
+
+	mu      sync.Mutex
+	current periodSalt
+	next    periodSalt
+	// retryAt is when loading the salt of the current period is tried again,
+	// or zero if the current salt is the shared one.
+	retryAt time.Time
+	// prefetched is the last period whose salt was prefetched.
+	prefetched time.Time
+}
+
+type periodSalt struct {
+	period time.Time
+	salt   []byte
+}
+
+func (s periodSalt) of(period time.Time) bool {
+	return s.salt != nil && s.period.Equal(period)
+}
+
+// NewIPAnonymizer anonymizes addresses as mode says, rotating hash salts
+// every rotation. It returns nil for IPModeNone.
+func NewIPAnonymizer(mode string, rotation time.Duration, salts SaltSource, logger *slog.Logger) (*IPAnonymizer, error) {
+	switch mode {
+	case "", IPModeNone:
+		return nil, nil
+	case IPModeTruncate:
+	case IPModeHash:
+		if rotation <= 0 {
+			return nil, fmt.Errorf("IP hash salt rotation must be positive, got %s", rotation)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported IP anonymization %q, expected none, truncate or hash", mode)
+	}
+	return &IPAnonymizer{
+		mode:     mode,
+		rotation: rotation,
+		salts:    salts,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+// Anonymize returns the anonymized form of ip, or "" if it is not an address.
+func (a *IPAnonymizer) Anonymize(ctx context.Context, ip string) string {
+	if a == nil {
+		return ip
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	if a.mode == IPModeTruncate {
+		return Truncate(addr).String()
+	}
+
+	mac := hmac.New(sha256.New, a.currentSalt(ctx))
+	mac.Write(addr.Unmap().AsSlice())
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Truncate zeroes the host part of addr: the last octet of IPv4 addresses
+// and all but the first 48 bits of IPv6 addresses.
+func Truncate(addr netip.Addr) netip.Addr {
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return prefix.Addr()
+}
+
+// currentSalt returns the salt of the current period. While the shared salt
+// cannot be loaded, a local one stands in for it and loading is retried every
+// saltRetryInterval, so addresses are never hashed with a stale salt.
+func (a *IPAnonymizer) currentSalt(ctx context.Context) []byte {
+	now := a.now().UTC()
+	period := now.Truncate(a.rotation)
+	salt := a.periodSalt(ctx, now, period)
+
+	next := period.Add(a.rotation)
+	if next.Sub(now) <= min(saltPrefetchLead, a.rotation/2) {
+		a.mu.Lock()
+		prefetch := !a.prefetched.Equal(next)
+		a.prefetched = next
+		a.mu.Unlock()
+		if prefetch {
+			go a.prefetch(ctx, next)
+		}
+	}
+	return salt
+}
+
+func (a *IPAnonymizer) periodSalt(ctx context.Context, now, period time.Time) []byte {
+	a.mu.Lock()
+	if a.next.of(period) {
+		a.current, a.next, a.retryAt = a.next, periodSalt{}, time.Time{}
+	}
+	if a.current.of(period) {
+		if a.retryAt.IsZero() || now.Before(a.retryAt) {
+			defer a.mu.Unlock()
+			return a.current.salt
+		}
+		// Only this request retries; the others keep the local salt meanwhile.
+		a.retryAt = now.Add(saltRetryInterval)
+	}
+	a.mu.Unlock()
+
+	salt, err := a.load(ctx, period)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current.of(period) && a.retryAt.IsZero() {
+		return a.current.salt
+	}
+	if err != nil {
+		a.logger.Warn("Failed to load IP hash salt, using a local one", "error", err)
+		if !a.current.of(period) {
+			a.current = periodSalt{period: period, salt: newSalt()}
+		}
+		a.retryAt = now.Add(saltRetryInterval)
+		return a.current.salt
+	}
+	a.current, a.retryAt = periodSalt{period: period, salt: salt}, time.Time{}
+	return salt
+}
+
+// prefetch loads the salt of the period starting at period ahead of time.
+func (a *IPAnonymizer) prefetch(ctx context.Context, period time.Time) {
+	salt, err := a.load(ctx, period)
+	if err != nil {
+		a.logger.Warn("Failed to prefetch IP hash salt", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.next = periodSalt{period: period, salt: salt}
+}
+
+// load loads the salt of period once for all requests that need it at the
+// same time, outside of the lock and regardless of whether ctx is cancelled.
+func (a *IPAnonymizer) load(ctx context.Context, period time.Time) ([]byte, error) {
+	salt, err, _ := a.loads.Do(strconv.FormatInt(period.Unix(), 10), func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saltLoadTimeout)
+		defer cancel()
+		return a.salts(ctx, period)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return salt.([]byte), nil
+}
+
+func newSalt() []byte {
+	salt := make([]byte, saltSize)
+	// Read never returns an error; it crashes the program instead.
+	rand.Read(salt)
+	return salt
+}
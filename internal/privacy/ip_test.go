@@ -0,0 +1,196 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{"203.0.113.42", "203.0.113.0"},
+		{"::ffff:203.0.113.42", "203.0.113.0"},
+		{"2001:db8:1234:5678::1", "2001:db8:1234::"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Truncate(netip.MustParseAddr(tt.addr)).String())
+		})
+	}
+}
+
+func TestNewIPAnonymizer(t *testing.T) {
+	anonymizer, err := NewIPAnonymizer(IPModeNone, 0, NewLocalSalts(), discard)
+	require.NoError(t, err)
+	assert.Nil(t, anonymizer)
+	assert.Equal(t, "203.0.113.42", anonymizer.Anonymize(context.Background(), "203.0.113.42"))
+
+	_, err = NewIPAnonymizer("mask", time.Hour, NewLocalSalts(), discard)
+	assert.Error(t, err)
+	_, err = NewIPAnonymizer(IPModeHash, 0, NewLocalSalts(), discard)
+	assert.Error(t, err)
+}
+
+func TestLocalSalts(t *testing.T) {
+	ctx := context.Background()
+	salts := NewLocalSalts()
+	first := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	salt, err := salts(ctx, first)
+	require.NoError(t, err)
+	again, err := salts(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, salt, again, "a period keeps its salt")
+
+	next, err := salts(ctx, second)
+	require.NoError(t, err)
+	assert.NotEqual(t, salt, next)
+	again, err = salts(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, salt, again, "the current period keeps its salt after the next one is prefetched")
+}
+
+func TestIPAnonymizer_Truncate(t *testing.T) {
+	anonymizer, err := NewIPAnonymizer(IPModeTruncate, 0, NewLocalSalts(), discard)
+	require.NoError(t, err)
+
+	assert.Equal(t, "203.0.113.0", anonymizer.Anonymize(context.Background(), "203.0.113.42"))
+	assert.Equal(t, "", anonymizer.Anonymize(context.Background(), "not an address"))
+}
+
+func TestIPAnonymizer_Hash(t *testing.T) {
+	ctx := context.Background()
+	var loads []time.Time
+	salts := func(_ context.Context, period time.Time) ([]byte, error) {
+		loads = append(loads, period)
+		return []byte(period.String()), nil
+	}
+	anonymizer, err := NewIPAnonymizer(IPModeHash, 24*time.Hour, salts, discard)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	anonymizer.now = func() time.Time { return now }
+
+	first := anonymizer.Anonymize(ctx, "203.0.113.42")
+	assert.Len(t, first, 16)
+	assert.NotContains(t, first, "203")
+	assert.Equal(t, first, anonymizer.Anonymize(ctx, "::ffff:203.0.113.42"), "same address, same period")
+	assert.NotEqual(t, first, anonymizer.Anonymize(ctx, "203.0.113.43"))
+
+	now = now.Add(24 * time.Hour)
+	assert.NotEqual(t, first, anonymizer.Anonymize(ctx, "203.0.113.42"), "the salt rotates")
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+	}, loads)
+}
+
+func TestIPAnonymizer_HashWithoutSalt(t *testing.T) {
+	calls := 0
+	salts := func(context.Context, time.Time) ([]byte, error) {
+		calls++
+		return nil, errors.New("redis: connection refused")
+	}
+	anonymizer, err := NewIPAnonymizer(IPModeHash, time.Hour, salts, discard)
+	require.NoError(t, err)
+	anonymizer.now = func() time.Time { return time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC) }
+
+	hash := anonymizer.Anonymize(context.Background(), "203.0.113.42")
+	assert.Len(t, hash, 16)
+	assert.Equal(t, hash, anonymizer.Anonymize(context.Background(), "203.0.113.42"))
+	assert.Equal(t, 1, calls, "the local salt is kept for the period")
+}
+
+func TestIPAnonymizer_HashRetriesSharedSalt(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	salts := func(_ context.Context, period time.Time) ([]byte, error) {
+		if fail.Load() {
+			return nil, errors.New("redis: connection refused")
+		}
+		return []byte(period.String()), nil
+	}
+	anonymizer, err := NewIPAnonymizer(IPModeHash, 24*time.Hour, salts, discard)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	anonymizer.now = func() time.Time { return now }
+	shared, err := NewIPAnonymizer(IPModeHash, 24*time.Hour, salts, discard)
+	require.NoError(t, err)
+	shared.now = anonymizer.now
+
+	local := anonymizer.Anonymize(context.Background(), "203.0.113.42")
+	fail.Store(false)
+	assert.Equal(t, local, anonymizer.Anonymize(context.Background(), "203.0.113.42"), "the local salt is kept until the retry")
+
+	now = now.Add(saltRetryInterval)
+	hash := anonymizer.Anonymize(context.Background(), "203.0.113.42")
+	assert.NotEqual(t, local, hash)
+	assert.Equal(t, shared.Anonymize(context.Background(), "203.0.113.42"), hash, "instances agree once the salt is loaded")
+}
+
+func TestIPAnonymizer_HashDetachedFromRequest(t *testing.T) {
+	salts := func(ctx context.Context, period time.Time) ([]byte, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return []byte(period.String()), nil
+	}
+	anonymizer, err := NewIPAnonymizer(IPModeHash, 24*time.Hour, salts, discard)
+	require.NoError(t, err)
+	shared, err := NewIPAnonymizer(IPModeHash, 24*time.Hour, salts, discard)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, shared.Anonymize(context.Background(), "203.0.113.42"), anonymizer.Anonymize(ctx, "203.0.113.42"),
+		"a cancelled request still gets the shared salt")
+}
+
+func TestIPAnonymizer_HashPrefetchesNextSalt(t *testing.T) {
+	var mu sync.Mutex
+	var loads []time.Time
+	salts := func(_ context.Context, period time.Time) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads = append(loads, period)
+		return []byte(period.String()), nil
+	}
+	loaded := func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(loads)
+	}
+	anonymizer, err := NewIPAnonymizer(IPModeHash, time.Hour, salts, discard)
+	require.NoError(t, err)
+	var now atomic.Pointer[time.Time]
+	now.Store(ptr(time.Date(2026, 10, 19, 15, 59, 30, 0, time.UTC)))
+	anonymizer.now = func() time.Time { return *now.Load() }
+
+	anonymizer.Anonymize(context.Background(), "203.0.113.42")
+	next := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	assert.Eventually(t, func() bool { return len(loaded()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, next, loaded()[1])
+
+	now.Store(ptr(next.Add(time.Second)))
+	anonymizer.Anonymize(context.Background(), "203.0.113.42")
+	assert.Len(t, loaded(), 2, "the prefetched salt is used")
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,52 @@
+package safehttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublic(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.215.14":        true,
+		"2606:2800:21f:cb07::": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"255.255.255.255":      false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"64:ff9b::a00:1":       false,
+	} {
+		assert.Equal(t, public, Public(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNonPublicAddress, "the test server listens on a loopback address")
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "ftp://example.com/file", nil)
+	assert.Error(t, checkRedirect(req, nil))
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	assert.NoError(t, checkRedirect(req, nil))
+	assert.Error(t, checkRedirect(req, make([]*http.Request, maxRedirects)))
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+type AccountService interface {
+	// DeleteOwnerData erases everything stored about the links and webhooks
+	// of owner, such as for a GDPR erasure request.
+	DeleteOwnerData(ctx context.Context, owner string) (*model.OwnerDeletion, error)
+}
+
+type accountService struct {
+	repository repository.AccountRepository
+	cache      cache.URLCache
+	visitors   repository.VisitorRepository
+	logger     *slog.Logger
+}
+
+// NewAccountService returns an AccountService. visitors is nil when unique
+// visitors are not counted.
+func NewAccountService(repo repository.AccountRepository, cache cache.URLCache, visitors repository.VisitorRepository, logger *slog.Logger) AccountService {
+	return &accountService{
+		repository: repo,
+		cache:      cache,
+		visitors:   visitors,
+		logger:     logger,
+	}
+}
+
+// DeleteOwnerData deletes the owner's data from the database first, so that
+// a failure there leaves everything in place. The links are then evicted from
+// the cache and their unique visitor counts deleted. Failures of these are
+// only logged: what is left behind expires within CacheExpiration and
+// VisitorDays days.
+func (s *accountService) DeleteOwnerData(ctx context.Context, owner string) (_ *model.OwnerDeletion, err error) {
+	ctx, span := tracer.Start(ctx, "accountService.DeleteOwnerData")
+	defer telemetry.End(span, &err)
+
+	deletion, err := s.repository.DeleteOwnerData(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, shortURL := range deletion.ShortUrls {
+		if err := s.cache.Delete(ctx, shortURL); cacheFailed(err) {
+			s.logger.Error("Failed to evict URL from cache", "shortURL", shortURL, "error", err)
+		}
+		if s.visitors != nil {
+			if err := s.visitors.DeleteVisitors(ctx, shortURL, now); err != nil {
+				s.logger.Error("Failed to delete unique visitor counts", "shortURL", shortURL, "error", err)
+			}
+		}
+	}
+	s.logger.Info("Deleted owner data", "links", len(deletion.ShortUrls), "webhooks", deletion.Webhooks, "events", deletion.Events)
+	return deletion, nil
+}
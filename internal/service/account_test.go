@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+type mockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *mockAccountRepository) DeleteOwnerData(ctx context.Context, owner string) (*model.OwnerDeletion, error) {
+	args := m.Called(ctx, owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OwnerDeletion), args.Error(1)
+}
+
+func TestDeleteOwnerData(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockAccountRepository)
+		mockCache := new(mockCache)
+		visitors := new(mockVisitorRepository)
+		deletion := &model.OwnerDeletion{ShortUrls: []string{"ab", "cd"}, Webhooks: 1}
+		repo.On("DeleteOwnerData", mock.Anything, "owner-1").Return(deletion, nil)
+		mockCache.On("Delete", mock.Anything, "ab").Return(nil)
+		mockCache.On("Delete", mock.Anything, "cd").Return(cache.ErrCacheUnavailable)
+		visitors.On("DeleteVisitors", mock.Anything, "ab", mock.Anything).Return(errors.New("redis: connection refused"))
+		visitors.On("DeleteVisitors", mock.Anything, "cd", mock.Anything).Return(nil)
+
+		result, err := NewAccountService(repo, mockCache, visitors, logger).DeleteOwnerData(context.Background(), "owner-1")
+
+		require.NoError(t, err, "cleaning up after the database is best effort")
+		assert.Equal(t, deletion, result)
+		mockCache.AssertExpectations(t)
+		visitors.AssertExpectations(t)
+	})
+
+	t.Run("without unique visitors", func(t *testing.T) {
+		repo := new(mockAccountRepository)
+		mockCache := new(mockCache)
+		repo.On("DeleteOwnerData", mock.Anything, "owner-1").Return(&model.OwnerDeletion{ShortUrls: []string{"ab"}}, nil)
+		mockCache.On("Delete", mock.Anything, "ab").Return(nil)
+
+		_, err := NewAccountService(repo, mockCache, nil, logger).DeleteOwnerData(context.Background(), "owner-1")
+
+		require.NoError(t, err)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		repo := new(mockAccountRepository)
+		mockCache := new(mockCache)
+		repo.On("DeleteOwnerData", mock.Anything, "owner-1").Return(nil, errors.New("connection refused"))
+
+		_, err := NewAccountService(repo, mockCache, nil, logger).DeleteOwnerData(context.Background(), "owner-1")
+
+		assert.Error(t, err)
+		mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+type mockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *mockAnalyticsRepository) RecordClick(ctx context.Context, click *model.Click) error {
+	args := m.Called(ctx, click)
+	return args.Error(0)
+}
+
+func (m *mockAnalyticsRepository) GetClickAnalytics(ctx context.Context, shortURL string, limit int32) (*model.ClickAnalytics, error) {
+	args := m.Called(ctx, shortURL, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ClickAnalytics), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) RollUpClicks(ctx context.Context, settle, maxSpan time.Duration) (bool, error) {
+	args := m.Called(ctx, settle, maxSpan)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) DeleteClickEvents(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) DeleteHourlyClickStats(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockVisitorRepository struct {
+	mock.Mock
+}
+
+func (m *mockVisitorRepository) AddVisitor(ctx context.Context, shortURL, visitor string, at time.Time) error {
+	args := m.Called(ctx, shortURL, visitor, at)
+	return args.Error(0)
+}
+
+func (m *mockVisitorRepository) GetUniqueVisitors(ctx context.Context, shortURL string, now time.Time) (*model.UniqueVisitors, error) {
+	args := m.Called(ctx, shortURL, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UniqueVisitors), args.Error(1)
+}
+
+func (m *mockVisitorRepository) DeleteVisitors(ctx context.Context, shortURL string, now time.Time) error {
+	args := m.Called(ctx, shortURL, now)
+	return args.Error(0)
+}
+
+func TestResolveShortURL_RecordsClickAnalytics(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	analytics := new(mockAnalyticsRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	mockCache.On("Get", mock.Anything, "exmpl").Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, "exmpl").Return(nil)
+	analytics.On("RecordClick", mock.Anything, &model.Click{
+		ShortUrl: "exmpl",
+		Referrer: "direct",
+		Browser:  model.BrowserFirefox,
+		OS:       model.OSLinux,
+		Device:   model.DeviceDesktop,
+	}).Return(nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithAnalytics(analytics))
+
+	visitor := &model.Visitor{Device: model.DeviceDesktop, OS: model.OSLinux, Browser: model.BrowserFirefox}
+	_, err := service.ResolveShortURL(WithVisitor(context.Background(), visitor), "exmpl")
+
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	mockRepo.AssertExpectations(t)
+	analytics.AssertExpectations(t)
+}
+
+func TestResolveShortURL_BotsAreNotCountedAsClicks(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	analytics := new(mockAnalyticsRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	mockCache.On("Get", mock.Anything, "exmpl").Return(&originalURL, nil)
+	analytics.On("RecordClick", mock.Anything, mock.MatchedBy(func(click *model.Click) bool {
+		return click.Bot
+	})).Return(nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithAnalytics(analytics))
+
+	resolved, err := service.ResolveShortURL(WithVisitor(context.Background(), &model.Visitor{Device: model.DeviceBot, Bot: true}), "exmpl")
+
+	require.NoError(t, err)
+	assert.Equal(t, originalURL, resolved, "bots are still redirected")
+	time.Sleep(10 * time.Millisecond)
+	mockRepo.AssertNotCalled(t, "IncrementClickCount", mock.Anything, mock.Anything)
+	analytics.AssertExpectations(t)
+}
+
+func TestGetShortURLStats_IncludesAnalytics(t *testing.T) {
+	mockRepo := new(mockRepository)
+	analytics := new(mockAnalyticsRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	breakdown := &model.ClickAnalytics{
+		BotClicks: 4,
+		Referrers: []model.ValueCount{{Value: "news.ycombinator.com", Count: 7}},
+	}
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "exmpl").Return(&model.Url{ShortUrl: "exmpl", ClickCount: 7}, nil)
+	analytics.On("GetClickAnalytics", mock.Anything, "exmpl", int32(TopClickValues)).Return(breakdown, nil)
+	service := NewURLService(mockRepo, new(mockCache), logger, WithAnalytics(analytics))
+
+	stats, err := service.GetShortURLStats(context.Background(), "exmpl")
+
+	require.NoError(t, err)
+	assert.Equal(t, breakdown, stats.Analytics)
+}
+
+func TestResolveShortURL_CountsUniqueVisitors(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	visitors := new(mockVisitorRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	hashes := make(chan string, 4)
+	mockCache.On("Get", mock.Anything, mock.Anything).Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, mock.Anything).Return(nil)
+	visitors.On("AddVisitor", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hashes <- args.String(2)
+	}).Return(nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithUniqueVisitors(visitors, "secret"))
+
+	resolve := func(shortURL string, visitor *model.Visitor) {
+		_, err := service.ResolveShortURL(WithVisitor(context.Background(), visitor), shortURL)
+		require.NoError(t, err)
+	}
+	key := "0123456789abcdef0123456789abcdef"
+	resolve("exmpl", &model.Visitor{Key: key})
+	first := <-hashes
+	resolve("exmpl", &model.Visitor{Key: key})
+	assert.Equal(t, first, <-hashes)
+	resolve("other", &model.Visitor{Key: key})
+	assert.NotEqual(t, first, <-hashes, "hashes differ between links")
+	assert.NotContains(t, first, key[:16])
+
+	resolve("exmpl", &model.Visitor{Key: key, Bot: true})
+	resolve("exmpl", &model.Visitor{Key: key, OptedOut: true})
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, hashes, "bots and visitors who opt out are not counted")
+}
+
+func TestGetShortURLStats_IncludesUniqueVisitors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	counts := &model.UniqueVisitors{Total: 12, Last7Days: 5, Last30Days: 9}
+
+	t.Run("counted", func(t *testing.T) {
+		mockRepo := new(mockRepository)
+		visitors := new(mockVisitorRepository)
+		mockRepo.On("GetURLByShortened", mock.Anything, "exmpl").Return(&model.Url{ShortUrl: "exmpl"}, nil)
+		visitors.On("GetUniqueVisitors", mock.Anything, "exmpl", mock.Anything).Return(counts, nil)
+		service := NewURLService(mockRepo, new(mockCache), logger, WithUniqueVisitors(visitors, "secret"))
+
+		stats, err := service.GetShortURLStats(context.Background(), "exmpl")
+
+		require.NoError(t, err)
+		assert.Equal(t, counts, stats.UniqueVisitors)
+	})
+
+	t.Run("redis unavailable", func(t *testing.T) {
+		mockRepo := new(mockRepository)
+		visitors := new(mockVisitorRepository)
+		mockRepo.On("GetURLByShortened", mock.Anything, "exmpl").Return(&model.Url{ShortUrl: "exmpl", ClickCount: 3}, nil)
+		visitors.On("GetUniqueVisitors", mock.Anything, "exmpl", mock.Anything).Return(nil, errors.New("connection refused"))
+		service := NewURLService(mockRepo, new(mockCache), logger, WithUniqueVisitors(visitors, "secret"))
+
+		stats, err := service.GetShortURLStats(context.Background(), "exmpl")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), stats.ClickCount)
+		assert.Nil(t, stats.UniqueVisitors)
+	})
+}
+
+func TestDeleteShortURL_DeletesUniqueVisitors(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	visitors := new(mockVisitorRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mockRepo.On("DeleteURL", mock.Anything, "exmpl").Return(nil)
+	mockCache.On("Delete", mock.Anything, "exmpl").Return(nil)
+	visitors.On("DeleteVisitors", mock.Anything, "exmpl", mock.Anything).Return(nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithUniqueVisitors(visitors, "secret"))
+
+	require.NoError(t, service.DeleteShortURL(context.Background(), "exmpl"))
+	visitors.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const MaxWarmCount = 10000
+
+type CacheService interface {
+	PurgeShortURL(ctx context.Context, shortURL string) error
+	WarmTopURLs(ctx context.Context, count int) (*model.CacheWarmReport, error)
+}
+
+type cacheService struct {
+	repository repository.URLRepository
+	cache      cache.URLCache
+	logger     *slog.Logger
+}
+
+func NewCacheService(repo repository.URLRepository, cache cache.URLCache, logger *slog.Logger) CacheService {
+	return &cacheService{
+		repository: repo,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+func (s *cacheService) PurgeShortURL(ctx context.Context, shortURL string) (err error) {
+	ctx, span := tracer.Start(ctx, "cacheService.PurgeShortURL")
+	defer telemetry.End(span, &err)
+
+	if shortURL == "" {
+		return ErrInvalidShortCode
+	}
+	return s.cache.Delete(ctx, shortURL)
+}
+
+// WarmTopURLs loads the count most clicked links into the cache. Links that
+// are already cached are left untouched.
+func (s *cacheService) WarmTopURLs(ctx context.Context, count int) (_ *model.CacheWarmReport, err error) {
+	ctx, span := tracer.Start(ctx, "cacheService.WarmTopURLs")
+	defer telemetry.End(span, &err)
+
+	if count <= 0 || count > MaxWarmCount {
+		return nil, ErrInvalidWarmCount
+	}
+
+	urls, err := s.repository.ListTopURLs(ctx, int32(count))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]string, len(urls))
+	for _, url := range urls {
+		entries[url.ShortUrl] = url.OriginalUrl
+	}
+	warmed, err := s.cache.Warm(ctx, entries, CacheExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Warmed URL cache", "loaded", len(urls), "warmed", warmed)
+	return &model.CacheWarmReport{Loaded: len(urls), Warmed: warmed}, nil
+}
+
+var (
+	ErrInvalidWarmCount = model.Error{
+		Message: "Count must be between 1 and 10000",
+	}
+)
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func newTestCacheService() (CacheService, *mockRepository, *mockCache) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewCacheService(mockRepo, mockCache, logger), mockRepo, mockCache
+}
+
+func TestPurgeShortURL(t *testing.T) {
+	service, _, mockCache := newTestCacheService()
+
+	mockCache.On("Delete", mock.Anything, "ggl").Return(nil)
+
+	err := service.PurgeShortURL(context.Background(), "ggl")
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestWarmTopURLs_Success(t *testing.T) {
+	service, mockRepo, mockCache := newTestCacheService()
+
+	mockRepo.On("ListTopURLs", mock.Anything, int32(2)).Return([]*model.Url{
+		{ShortUrl: "ggl", OriginalUrl: "https://google.com"},
+		{ShortUrl: "ddg", OriginalUrl: "https://duckduckgo.com"},
+	}, nil)
+	mockCache.On("Warm", mock.Anything, map[string]string{
+		"ggl": "https://google.com",
+		"ddg": "https://duckduckgo.com",
+	}, CacheExpiration).Return(1, nil)
+
+	report, err := service.WarmTopURLs(context.Background(), 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.CacheWarmReport{Loaded: 2, Warmed: 1}, report)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestWarmTopURLs_Failure_InvalidCount(t *testing.T) {
+	service, mockRepo, _ := newTestCacheService()
+
+	for _, count := range []int{0, -1, MaxWarmCount + 1} {
+		_, err := service.WarmTopURLs(context.Background(), count)
+		assert.ErrorIs(t, err, ErrInvalidWarmCount)
+	}
+	mockRepo.AssertNotCalled(t, "ListTopURLs", mock.Anything, mock.Anything)
+}
+
+func TestWarmTopURLs_Failure_Repository(t *testing.T) {
+	service, mockRepo, mockCache := newTestCacheService()
+
+	dbErr := errors.New("connection refused")
+	mockRepo.On("ListTopURLs", mock.Anything, int32(10)).Return(nil, dbErr)
+
+	_, err := service.WarmTopURLs(context.Background(), 10)
+
+	assert.ErrorIs(t, err, dbErr)
+	mockCache.AssertNotCalled(t, "Warm", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	DefaultDashboardWindow = "7d"
+	DefaultDashboardLimit  = 10
+	MaxDashboardLimit      = 100
+)
+
+// DashboardWindows are the periods the top links of a dashboard can be ranked
+// by.
+var DashboardWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+type DashboardService interface {
+	// GetDashboard summarizes the links of owner, ranking the top links by
+	// their clicks within window and listing up to limit links of every kind.
+	GetDashboard(ctx context.Context, owner, window string, limit int) (*model.Dashboard, error)
+}
+
+type dashboardService struct {
+	repository repository.DashboardRepository
+}
+
+func NewDashboardService(repo repository.DashboardRepository) DashboardService {
+	return &dashboardService{
+		repository: repo,
+	}
+}
+
+func (s *dashboardService) GetDashboard(ctx context.Context, owner, window string, limit int) (_ *model.Dashboard, err error) {
+	ctx, span := tracer.Start(ctx, "dashboardService.GetDashboard")
+	defer telemetry.End(span, &err)
+
+	if window == "" {
+		window = DefaultDashboardWindow
+	}
+	duration, ok := DashboardWindows[window]
+	if !ok {
+		return nil, ErrInvalidDashboardWindow
+	}
+	if limit == 0 {
+		limit = DefaultDashboardLimit
+	}
+	if limit < 0 || limit > MaxDashboardLimit {
+		return nil, ErrInvalidDashboardLimit
+	}
+
+	dashboard, err := s.repository.GetDashboard(ctx, owner, duration, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Window = window
+	return dashboard, nil
+}
+
+var (
+	ErrInvalidDashboardWindow = model.Error{
+		Message: "Window must be one of 24h, 7d or 30d",
+	}
+	ErrInvalidDashboardLimit = model.Error{
+		Message: "Limit must be between 1 and 100",
+	}
+)
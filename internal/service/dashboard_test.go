@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+type mockDashboardRepository struct {
+	mock.Mock
+}
+
+func (m *mockDashboardRepository) GetDashboard(ctx context.Context, owner string, window time.Duration, limit int32) (*model.Dashboard, error) {
+	args := m.Called(ctx, owner, window, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Dashboard), args.Error(1)
+}
+
+func TestGetDashboard(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		repo := new(mockDashboardRepository)
+		repo.On("GetDashboard", mock.Anything, "owner-1", 7*24*time.Hour, int32(DefaultDashboardLimit)).
+			Return(&model.Dashboard{TotalLinks: 3}, nil)
+
+		dashboard, err := NewDashboardService(repo).GetDashboard(context.Background(), "owner-1", "", 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), dashboard.TotalLinks)
+		assert.Equal(t, DefaultDashboardWindow, dashboard.Window)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("window and limit", func(t *testing.T) {
+		repo := new(mockDashboardRepository)
+		repo.On("GetDashboard", mock.Anything, "owner-1", 24*time.Hour, int32(5)).Return(&model.Dashboard{}, nil)
+
+		dashboard, err := NewDashboardService(repo).GetDashboard(context.Background(), "owner-1", "24h", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, "24h", dashboard.Window)
+		repo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name     string
+		window   string
+		limit    int
+		expected error
+	}{
+		{"unknown window", "1y", 0, ErrInvalidDashboardWindow},
+		{"negative limit", "7d", -1, ErrInvalidDashboardLimit},
+		{"limit too large", "7d", MaxDashboardLimit + 1, ErrInvalidDashboardLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockDashboardRepository)
+
+			_, err := NewDashboardService(repo).GetDashboard(context.Background(), "owner-1", tt.window, tt.limit)
+
+			assert.ErrorIs(t, err, tt.expected)
+			repo.AssertNotCalled(t, "GetDashboard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
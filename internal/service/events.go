@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+// EventPublisher records domain events about links. Publish takes part in the
+// transaction carried by ctx, so an event is only delivered once the change
+// it describes is committed.
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType, shortURL string, data any) error
+}
+
+// linkEventData is the payload of link.created and link.updated events.
+type linkEventData struct {
+	OriginalURL string `json:"original_url"`
+}
+
+type outboxPublisher struct {
+	outbox repository.OutboxRepository
+}
+
+func NewOutboxPublisher(outbox repository.OutboxRepository) EventPublisher {
+	return &outboxPublisher{
+		outbox: outbox,
+	}
+}
+
+func (p *outboxPublisher) Publish(ctx context.Context, eventType, shortURL string, data any) error {
+	payload := []byte("{}")
+	if data != nil {
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			return err
+		}
+	}
+	return p.outbox.AddEvent(ctx, &model.Event{
+		Type:     eventType,
+		ShortUrl: shortURL,
+		Data:     payload,
+	})
+}
+
+type noopPublisher struct{}
+
+func NewNoopEventPublisher() EventPublisher {
+	return noopPublisher{}
+}
+
+func (noopPublisher) Publish(context.Context, string, string, any) error {
+	return nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/unwale/url-shortener/db/sqlc"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, eventType, shortURL string, data any) error {
+	args := m.Called(ctx, eventType, shortURL, data)
+	return args.Error(0)
+}
+
+type mockOutbox struct {
+	mock.Mock
+}
+
+func (m *mockOutbox) AddEvent(ctx context.Context, event *model.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *mockOutbox) ClaimEvents(ctx context.Context, limit int32, lease time.Duration, maxAttempts int32) ([]*model.Event, error) {
+	args := m.Called(ctx, limit, lease, maxAttempts)
+	return args.Get(0).([]*model.Event), args.Error(1)
+}
+
+func (m *mockOutbox) MarkDelivered(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockOutbox) MarkFailed(ctx context.Context, id int64, cause string, retryIn time.Duration) error {
+	args := m.Called(ctx, id, cause, retryIn)
+	return args.Error(0)
+}
+
+func (m *mockOutbox) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestCreateShortURL_PublishesEvent(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	mockEvents := new(mockPublisher)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger, WithURLEvents(repository.NewNoopTxManager(), mockEvents))
+
+	mockRepo.On("CreateURL", mock.Anything, mock.Anything).
+		Return(&model.Url{OriginalUrl: "https://www.google.com", ShortUrl: "my-google"}, nil)
+	mockEvents.On("Publish", mock.Anything, model.EventLinkCreated, "my-google",
+		linkEventData{OriginalURL: "https://www.google.com"}).Return(nil)
+	mockCache.On("Delete", mock.Anything, "my-google").Return(nil)
+
+	_, err := service.CreateShortURL(context.Background(), "https://www.google.com", "my-google")
+
+	assert.NoError(t, err)
+	mockEvents.AssertExpectations(t)
+}
+
+func TestCreateShortURL_FailsWhenEventIsNotRecorded(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	mockEvents := new(mockPublisher)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger, WithURLEvents(repository.NewNoopTxManager(), mockEvents))
+
+	outboxErr := errors.New("outbox unavailable")
+	mockRepo.On("CreateURL", mock.Anything, &db.CreateUrlParams{
+		OriginalUrl: "https://www.google.com",
+		ShortUrl:    "my-google",
+	}).Return(&model.Url{OriginalUrl: "https://www.google.com", ShortUrl: "my-google"}, nil)
+	mockEvents.On("Publish", mock.Anything, model.EventLinkCreated, "my-google", mock.Anything).Return(outboxErr)
+
+	_, err := service.CreateShortURL(context.Background(), "https://www.google.com", "my-google")
+
+	assert.ErrorIs(t, err, outboxErr)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestDeleteShortURL_PublishesEvent(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	mockEvents := new(mockPublisher)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger, WithURLEvents(repository.NewNoopTxManager(), mockEvents))
+
+	mockRepo.On("DeleteURL", mock.Anything, "ac6bb669").Return(nil)
+	mockEvents.On("Publish", mock.Anything, model.EventLinkDeleted, "ac6bb669", nil).Return(nil)
+	mockCache.On("Delete", mock.Anything, "ac6bb669").Return(nil)
+
+	assert.NoError(t, service.DeleteShortURL(context.Background(), "ac6bb669"))
+	mockEvents.AssertExpectations(t)
+}
+
+func TestOutboxPublisher(t *testing.T) {
+	mockOutbox := new(mockOutbox)
+	publisher := NewOutboxPublisher(mockOutbox)
+
+	var added *model.Event
+	mockOutbox.On("AddEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		added = args.Get(1).(*model.Event)
+	}).Return(nil)
+
+	require.NoError(t, publisher.Publish(context.Background(), model.EventLinkCreated, "exmpl",
+		linkEventData{OriginalURL: "https://google.com"}))
+	require.NoError(t, publisher.Publish(context.Background(), model.EventLinkClicked, "exmpl", nil))
+
+	assert.Equal(t, model.EventLinkClicked, added.Type)
+	assert.Equal(t, "exmpl", added.ShortUrl)
+	assert.Equal(t, json.RawMessage("{}"), added.Data)
+	first := mockOutbox.Calls[0].Arguments.Get(1).(*model.Event)
+	assert.JSONEq(t, `{"original_url":"https://google.com"}`, string(first.Data))
+}
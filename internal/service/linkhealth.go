@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	DefaultBrokenLinksLimit = 100
+	MaxBrokenLinksLimit     = 1000
+	// BrokenLinksReloadInterval is how often the broken links sent to the
+	// fallback URL are reloaded.
+	BrokenLinksReloadInterval = time.Minute
+)
+
+// LinkHealthService reports on the destinations of links as checked by the
+// link checker.
+type LinkHealthService interface {
+	// GetLinkHealth returns nil if the link has not been checked yet.
+	GetLinkHealth(ctx context.Context, shortURL string) (*model.LinkHealth, error)
+	ListBrokenLinks(ctx context.Context, owner string, limit int) ([]*model.LinkHealth, error)
+	// IsBroken reports whether shortURL was broken when the broken links were
+	// last loaded, so that redirects do not wait on the database.
+	IsBroken(shortURL string) bool
+	// LoadBrokenLinks reloads the broken links IsBroken answers from.
+	LoadBrokenLinks(ctx context.Context) error
+}
+
+type linkHealthService struct {
+	repository repository.LinkHealthRepository
+	broken     atomic.Pointer[map[string]struct{}]
+}
+
+func NewLinkHealthService(repo repository.LinkHealthRepository) LinkHealthService {
+	return &linkHealthService{
+		repository: repo,
+	}
+}
+
+func (s *linkHealthService) GetLinkHealth(ctx context.Context, shortURL string) (_ *model.LinkHealth, err error) {
+	ctx, span := tracer.Start(ctx, "linkHealthService.GetLinkHealth")
+	defer telemetry.End(span, &err)
+
+	return s.repository.GetLinkHealth(ctx, shortURL)
+}
+
+func (s *linkHealthService) ListBrokenLinks(ctx context.Context, owner string, limit int) (_ []*model.LinkHealth, err error) {
+	ctx, span := tracer.Start(ctx, "linkHealthService.ListBrokenLinks")
+	defer telemetry.End(span, &err)
+
+	if limit == 0 {
+		limit = DefaultBrokenLinksLimit
+	}
+	if limit < 0 || limit > MaxBrokenLinksLimit {
+		return nil, ErrInvalidBrokenLinksLimit
+	}
+	return s.repository.ListBrokenLinks(ctx, owner, int32(limit))
+}
+
+func (s *linkHealthService) IsBroken(shortURL string) bool {
+	broken := s.broken.Load()
+	if broken == nil {
+		return false
+	}
+	_, ok := (*broken)[shortURL]
+	return ok
+}
+
+func (s *linkHealthService) LoadBrokenLinks(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "linkHealthService.LoadBrokenLinks")
+	defer telemetry.End(span, &err)
+
+	codes, err := s.repository.ListBrokenShortURLs(ctx)
+	if err != nil {
+		return err
+	}
+	broken := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		broken[code] = struct{}{}
+	}
+	s.broken.Store(&broken)
+	return nil
+}
+
+var ErrInvalidBrokenLinksLimit = model.Error{
+	Message: "Limit must be between 1 and 1000",
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+type mockLinkHealthRepository struct {
+	mock.Mock
+}
+
+func (m *mockLinkHealthRepository) ClaimLinkChecks(ctx context.Context, limit int32, lease time.Duration) ([]*model.LinkCheck, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*model.LinkCheck), args.Error(1)
+}
+
+func (m *mockLinkHealthRepository) RecordLinkCheck(ctx context.Context, result *model.LinkCheckResult, recheckIn time.Duration) error {
+	args := m.Called(ctx, result, recheckIn)
+	return args.Error(0)
+}
+
+func (m *mockLinkHealthRepository) GetLinkHealth(ctx context.Context, shortURL string) (*model.LinkHealth, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LinkHealth), args.Error(1)
+}
+
+func (m *mockLinkHealthRepository) ListBrokenLinks(ctx context.Context, owner string, limit int32) ([]*model.LinkHealth, error) {
+	args := m.Called(ctx, owner, limit)
+	return args.Get(0).([]*model.LinkHealth), args.Error(1)
+}
+
+func (m *mockLinkHealthRepository) ListBrokenShortURLs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestListBrokenLinks(t *testing.T) {
+	repo := new(mockLinkHealthRepository)
+	repo.On("ListBrokenLinks", mock.Anything, "owner-1", int32(DefaultBrokenLinksLimit)).
+		Return([]*model.LinkHealth{{ShortUrl: "gone", Broken: true}}, nil)
+	health := NewLinkHealthService(repo)
+
+	links, err := health.ListBrokenLinks(context.Background(), "owner-1", 0)
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+	repo.AssertExpectations(t)
+
+	_, err = health.ListBrokenLinks(context.Background(), "owner-1", MaxBrokenLinksLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidBrokenLinksLimit)
+}
+
+func TestLoadBrokenLinks(t *testing.T) {
+	repo := new(mockLinkHealthRepository)
+	repo.On("ListBrokenShortURLs", mock.Anything).Return([]string{"gone"}, nil).Once()
+	repo.On("ListBrokenShortURLs", mock.Anything).Return([]string{}, nil).Once()
+	health := NewLinkHealthService(repo)
+
+	assert.False(t, health.IsBroken("gone"), "nothing is broken before the first load")
+	require.NoError(t, health.LoadBrokenLinks(context.Background()))
+	assert.True(t, health.IsBroken("gone"))
+	assert.False(t, health.IsBroken("ok"))
+	require.NoError(t, health.LoadBrokenLinks(context.Background()))
+	assert.False(t, health.IsBroken("gone"), "repaired links are forgotten")
+}
+
+func TestResolveShortURL_Fallback(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com/gone"
+	repo := new(mockLinkHealthRepository)
+	repo.On("ListBrokenShortURLs", mock.Anything).Return([]string{"gone"}, nil)
+	health := NewLinkHealthService(repo)
+	require.NoError(t, health.LoadBrokenLinks(context.Background()))
+
+	resolve := func(t *testing.T, shortURL, fallbackURL string) (string, *model.Visitor) {
+		mockRepo := new(mockRepository)
+		mockCache := new(mockCache)
+		mockCache.On("Get", mock.Anything, shortURL).Return(&originalURL, nil)
+		mockRepo.On("IncrementClickCount", mock.Anything, shortURL).Return(nil)
+		service := NewURLService(mockRepo, mockCache, logger, WithLinkHealth(health, fallbackURL))
+
+		visitor := &model.Visitor{}
+		destination, err := service.ResolveShortURL(WithVisitor(context.Background(), visitor), shortURL)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		return destination, visitor
+	}
+
+	t.Run("broken link", func(t *testing.T) {
+		destination, visitor := resolve(t, "gone", "https://example.com/sorry")
+		assert.Equal(t, "https://example.com/sorry", destination)
+		assert.True(t, visitor.Fallback)
+	})
+
+	t.Run("healthy link", func(t *testing.T) {
+		destination, visitor := resolve(t, "ok", "https://example.com/sorry")
+		assert.Equal(t, originalURL, destination)
+		assert.False(t, visitor.Fallback)
+	})
+
+	t.Run("without a fallback URL", func(t *testing.T) {
+		destination, visitor := resolve(t, "gone", "")
+		assert.Equal(t, originalURL, destination)
+		assert.False(t, visitor.Fallback)
+	})
+}
+
+func TestGetShortURLStats_Health(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	repo := new(mockLinkHealthRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "gone").Return(&model.Url{ShortUrl: "gone"}, nil)
+	repo.On("GetLinkHealth", mock.Anything, "gone").Return(&model.LinkHealth{StatusCode: 404, Broken: true}, nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithLinkHealth(NewLinkHealthService(repo), ""))
+
+	stats, err := service.GetShortURLStats(context.Background(), "gone")
+
+	require.NoError(t, err)
+	require.NotNil(t, stats.Health)
+	assert.True(t, stats.Health.Broken)
+	repo.AssertExpectations(t)
+}
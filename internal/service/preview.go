@@ -0,0 +1,144 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	MaxPreviewTitleLength       = 300
+	MaxPreviewDescriptionLength = 1000
+	MaxPreviewImageURLLength    = 2048
+)
+
+// PreviewService manages the cards shown for links shared on chat apps and
+// social networks. Like routing rules, previews can only be managed by the
+// owner of their link.
+type PreviewService interface {
+	// SetPreview replaces the preview of a link. It needs a title,
+	// description or image.
+	SetPreview(ctx context.Context, owner string, preview *model.LinkPreview) (*model.LinkPreview, error)
+	GetPreview(ctx context.Context, owner, shortURL string) (*model.LinkPreview, error)
+	DeletePreview(ctx context.Context, owner, shortURL string) error
+	// Card returns the preview of shortURL with its empty fields filled from
+	// the metadata of the destination, or nil if the link has no preview.
+	Card(ctx context.Context, shortURL string) (*model.LinkPreview, error)
+}
+
+type previewService struct {
+	previews repository.LinkPreviewRepository
+	urls     repository.URLRepository
+	metadata repository.LinkMetadataRepository
+}
+
+func NewPreviewService(previews repository.LinkPreviewRepository, urls repository.URLRepository,
+	metadata repository.LinkMetadataRepository) PreviewService {
+	return &previewService{
+		previews: previews,
+		urls:     urls,
+		metadata: metadata,
+	}
+}
+
+func (s *previewService) SetPreview(ctx context.Context, owner string, preview *model.LinkPreview) (_ *model.LinkPreview, err error) {
+	ctx, span := tracer.Start(ctx, "previewService.SetPreview")
+	defer telemetry.End(span, &err)
+
+	if err := normalizePreview(preview); err != nil {
+		return nil, err
+	}
+	if err := checkOwner(ctx, s.urls, owner, preview.ShortUrl); err != nil {
+		return nil, err
+	}
+	if err := s.previews.SetLinkPreview(ctx, preview); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+func (s *previewService) GetPreview(ctx context.Context, owner, shortURL string) (_ *model.LinkPreview, err error) {
+	ctx, span := tracer.Start(ctx, "previewService.GetPreview")
+	defer telemetry.End(span, &err)
+
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return nil, err
+	}
+	return s.previews.GetLinkPreview(ctx, shortURL)
+}
+
+func (s *previewService) DeletePreview(ctx context.Context, owner, shortURL string) (err error) {
+	ctx, span := tracer.Start(ctx, "previewService.DeletePreview")
+	defer telemetry.End(span, &err)
+
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return err
+	}
+	return s.previews.DeleteLinkPreview(ctx, shortURL)
+}
+
+func (s *previewService) Card(ctx context.Context, shortURL string) (_ *model.LinkPreview, err error) {
+	ctx, span := tracer.Start(ctx, "previewService.Card")
+	defer telemetry.End(span, &err)
+
+	card, err := s.previews.GetLinkPreview(ctx, shortURL)
+	if errors.Is(err, repository.ErrLinkPreviewNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if card.Title != "" && card.Description != "" && card.ImageUrl != "" {
+		return card, nil
+	}
+
+	metadata, err := s.metadata.GetLinkMetadata(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		card.Title = cmp.Or(card.Title, metadata.Title)
+		card.Description = cmp.Or(card.Description, metadata.Description)
+		if image := metadata.OpenGraph["image"]; card.ImageUrl == "" && isHTTPURL(image) {
+			card.ImageUrl = image
+		}
+	}
+	return card, nil
+}
+
+// normalizePreview trims the fields of preview and validates them.
+func normalizePreview(preview *model.LinkPreview) error {
+	preview.Title = strings.TrimSpace(preview.Title)
+	preview.Description = strings.TrimSpace(preview.Description)
+	preview.ImageUrl = strings.TrimSpace(preview.ImageUrl)
+
+	if preview.Title == "" && preview.Description == "" && preview.ImageUrl == "" {
+		return ErrEmptyPreview
+	}
+	if utf8.RuneCountInString(preview.Title) > MaxPreviewTitleLength ||
+		utf8.RuneCountInString(preview.Description) > MaxPreviewDescriptionLength {
+		return ErrPreviewTooLong
+	}
+	if preview.ImageUrl != "" && (len(preview.ImageUrl) > MaxPreviewImageURLLength || !isHTTPURL(preview.ImageUrl)) {
+		return ErrInvalidPreviewImage
+	}
+	return nil
+}
+
+var (
+	ErrEmptyPreview = model.Error{
+		Message: "Preview needs a title, description or image URL",
+	}
+	ErrPreviewTooLong = model.Error{
+		Message: "Preview title must be at most 300 characters and description at most 1000",
+	}
+	ErrInvalidPreviewImage = model.Error{
+		Message: "Preview image URL must be an absolute http or https URL of at most 2048 characters",
+	}
+)
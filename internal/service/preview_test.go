@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+type mockLinkPreviewRepository struct {
+	mock.Mock
+}
+
+func (m *mockLinkPreviewRepository) SetLinkPreview(ctx context.Context, preview *model.LinkPreview) error {
+	return m.Called(ctx, preview).Error(0)
+}
+
+func (m *mockLinkPreviewRepository) GetLinkPreview(ctx context.Context, shortURL string) (*model.LinkPreview, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LinkPreview), args.Error(1)
+}
+
+func (m *mockLinkPreviewRepository) DeleteLinkPreview(ctx context.Context, shortURL string) error {
+	return m.Called(ctx, shortURL).Error(0)
+}
+
+func TestPreviewService_SetPreview(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		urls := new(mockRepository)
+		service := NewPreviewService(previews, urls, new(mockLinkMetadataRepository))
+
+		urls.On("GetURLByShortened", mock.Anything, "launch").Return(&model.Url{ShortUrl: "launch", Owner: testOwner}, nil)
+		previews.On("SetLinkPreview", mock.Anything, &model.LinkPreview{
+			ShortUrl: "launch",
+			Title:    "We launched",
+			ImageUrl: "https://example.com/launch.png",
+		}).Return(nil)
+
+		preview, err := service.SetPreview(context.Background(), testOwner, &model.LinkPreview{
+			ShortUrl: "launch",
+			Title:    "  We launched ",
+			ImageUrl: "https://example.com/launch.png",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "We launched", preview.Title)
+		previews.AssertExpectations(t)
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		urls := new(mockRepository)
+		service := NewPreviewService(new(mockLinkPreviewRepository), urls, new(mockLinkMetadataRepository))
+		urls.On("GetURLByShortened", mock.Anything, "missing").Return(nil, repository.ErrURLNotFound)
+
+		_, err := service.SetPreview(context.Background(), testOwner, &model.LinkPreview{ShortUrl: "missing", Title: "Title"})
+		assert.ErrorIs(t, err, repository.ErrURLNotFound)
+	})
+
+	t.Run("link of another owner", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		urls := new(mockRepository)
+		service := NewPreviewService(previews, urls, new(mockLinkMetadataRepository))
+		urls.On("GetURLByShortened", mock.Anything, "launch").Return(&model.Url{ShortUrl: "launch", Owner: "someone-else"}, nil)
+
+		_, err := service.SetPreview(context.Background(), testOwner, &model.LinkPreview{ShortUrl: "launch", Title: "Title"})
+		assert.ErrorIs(t, err, repository.ErrURLNotFound)
+		_, err = service.GetPreview(context.Background(), testOwner, "launch")
+		assert.ErrorIs(t, err, repository.ErrURLNotFound)
+		assert.ErrorIs(t, service.DeletePreview(context.Background(), testOwner, "launch"), repository.ErrURLNotFound)
+		previews.AssertNotCalled(t, "SetLinkPreview", mock.Anything, mock.Anything)
+		previews.AssertNotCalled(t, "DeleteLinkPreview", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		service := NewPreviewService(new(mockLinkPreviewRepository), new(mockRepository), new(mockLinkMetadataRepository))
+
+		for preview, want := range map[model.LinkPreview]error{
+			{Title: " "}:                                                   ErrEmptyPreview,
+			{Title: strings.Repeat("a", 301)}:                              ErrPreviewTooLong,
+			{Description: strings.Repeat("é", 1001)}:                       ErrPreviewTooLong,
+			{ImageUrl: "/launch.png"}:                                      ErrInvalidPreviewImage,
+			{Title: "Title", ImageUrl: "javascript:alert(1)"}:              ErrInvalidPreviewImage,
+			{ImageUrl: "https://example.com/" + strings.Repeat("a", 2048)}: ErrInvalidPreviewImage,
+		} {
+			preview.ShortUrl = "launch"
+			_, err := service.SetPreview(context.Background(), testOwner, &preview)
+			assert.ErrorIs(t, err, want)
+		}
+	})
+}
+
+func TestPreviewService_Card(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("filled from metadata", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		metadata := new(mockLinkMetadataRepository)
+		service := NewPreviewService(previews, new(mockRepository), metadata)
+
+		previews.On("GetLinkPreview", mock.Anything, "launch").Return(&model.LinkPreview{ShortUrl: "launch", Title: "We launched"}, nil)
+		metadata.On("GetLinkMetadata", mock.Anything, "launch").Return(&model.LinkMetadata{
+			Title:       "Example",
+			Description: "An example page",
+			OpenGraph:   map[string]string{"image": "https://example.com/cover.png"},
+		}, nil)
+
+		card, err := service.Card(ctx, "launch")
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.LinkPreview{
+			ShortUrl:    "launch",
+			Title:       "We launched",
+			Description: "An example page",
+			ImageUrl:    "https://example.com/cover.png",
+		}, card)
+	})
+
+	t.Run("complete preview", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		metadata := new(mockLinkMetadataRepository)
+		service := NewPreviewService(previews, new(mockRepository), metadata)
+
+		preview := &model.LinkPreview{Title: "Title", Description: "Description", ImageUrl: "https://example.com/a.png"}
+		previews.On("GetLinkPreview", mock.Anything, "launch").Return(preview, nil)
+
+		card, err := service.Card(ctx, "launch")
+
+		require.NoError(t, err)
+		assert.Equal(t, preview, card)
+		metadata.AssertNotCalled(t, "GetLinkMetadata", mock.Anything, mock.Anything)
+	})
+
+	t.Run("without metadata", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		metadata := new(mockLinkMetadataRepository)
+		service := NewPreviewService(previews, new(mockRepository), metadata)
+
+		previews.On("GetLinkPreview", mock.Anything, "launch").Return(&model.LinkPreview{Title: "We launched"}, nil)
+		metadata.On("GetLinkMetadata", mock.Anything, "launch").Return(nil, nil)
+
+		card, err := service.Card(ctx, "launch")
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.LinkPreview{Title: "We launched"}, card)
+	})
+
+	t.Run("without a preview", func(t *testing.T) {
+		previews := new(mockLinkPreviewRepository)
+		service := NewPreviewService(previews, new(mockRepository), new(mockLinkMetadataRepository))
+		previews.On("GetLinkPreview", mock.Anything, "plain").Return(nil, repository.ErrLinkPreviewNotFound)
+
+		card, err := service.Card(ctx, "plain")
+
+		require.NoError(t, err)
+		assert.Nil(t, card)
+	})
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const (
+	MaxRulesPerLink    = 50
+	MaxVariantsPerLink = 10
+	// TotalVariantWeight is what the weights of a link's variants add up to,
+	// making them percentages.
+	TotalVariantWeight = 100
+)
+
+var (
+	routingDevices = []string{model.DeviceMobile, model.DeviceTablet, model.DeviceDesktop, model.DeviceBot}
+	routingOSes    = []string{model.OSiOS, model.OSAndroid, model.OSWindows, model.OSMacOS, model.OSLinux}
+
+	languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{1,8})*$`)
+	countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+type visitorKey struct{}
+
+// WithVisitor makes ResolveShortURL apply the link's routing rules for
+// visitor.
+func WithVisitor(ctx context.Context, visitor *model.Visitor) context.Context {
+	return context.WithValue(ctx, visitorKey{}, visitor)
+}
+
+func visitorFromContext(ctx context.Context) (*model.Visitor, bool) {
+	visitor, ok := ctx.Value(visitorKey{}).(*model.Visitor)
+	return visitor, ok && visitor != nil
+}
+
+// RoutingService manages the routing rules and variants of links. Rules and
+// variants can only be changed or listed by the owner of their link; links
+// of other owners are reported as not found.
+type RoutingService interface {
+	CreateRule(ctx context.Context, owner string, rule *model.RoutingRule) (*model.RoutingRule, error)
+	ListRules(ctx context.Context, owner, shortURL string) ([]*model.RoutingRule, error)
+	DeleteRule(ctx context.Context, owner, shortURL string, id int64) error
+	// SetVariants splits the traffic of shortURL between variants. Variants
+	// whose destination is kept keep their click counts.
+	SetVariants(ctx context.Context, owner, shortURL string, variants []*model.Variant) ([]*model.Variant, error)
+	ListVariants(ctx context.Context, shortURL string) ([]*model.Variant, error)
+	// DeleteVariants sends all traffic of shortURL to its original URL again.
+	DeleteVariants(ctx context.Context, owner, shortURL string) error
+	RecordVariantClick(ctx context.Context, id int64) error
+	// Route decides where visitor is sent: to the destination of the first
+	// rule of shortURL that matches, or else to one of its variants.
+	Route(ctx context.Context, shortURL string, visitor *model.Visitor) (*model.Route, error)
+}
+
+type routingService struct {
+	rules    repository.RoutingRuleRepository
+	variants repository.VariantRepository
+	urls     repository.URLRepository
+	tx       repository.TxManager
+	cache    *cache.LRU[*linkRouting]
+	ttl      time.Duration
+	lookups  singleflight.Group
+}
+
+// linkRouting is what is cached about a link for routing its visitors.
+type linkRouting struct {
+	Rules    []*model.RoutingRule
+	Variants []*model.Variant
+}
+
+// NewRoutingService keeps the rules and variants of up to cacheSize links in
+// memory for ttl. Changes only evict the entry on the instance that made
+// them, so other instances keep applying the old rules and variants for up
+// to ttl.
+func NewRoutingService(rules repository.RoutingRuleRepository, variants repository.VariantRepository, urls repository.URLRepository,
+	tx repository.TxManager, cacheSize int, ttl time.Duration) RoutingService {
+	return &routingService{
+		rules:    rules,
+		variants: variants,
+		urls:     urls,
+		tx:       tx,
+		cache:    cache.NewLRU[*linkRouting](cacheSize),
+		ttl:      ttl,
+	}
+}
+
+func (s *routingService) CreateRule(ctx context.Context, owner string, rule *model.RoutingRule) (_ *model.RoutingRule, err error) {
+	ctx, span := tracer.Start(ctx, "routingService.CreateRule")
+	defer telemetry.End(span, &err)
+
+	if err := normalizeRule(rule); err != nil {
+		return nil, err
+	}
+	if err := checkOwner(ctx, s.urls, owner, rule.ShortUrl); err != nil {
+		return nil, err
+	}
+	existing, err := s.rules.ListRules(ctx, rule.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxRulesPerLink {
+		return nil, ErrTooManyRules
+	}
+
+	if err := s.rules.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.cache.Delete(rule.ShortUrl)
+	return rule, nil
+}
+
+func (s *routingService) ListRules(ctx context.Context, owner, shortURL string) (_ []*model.RoutingRule, err error) {
+	ctx, span := tracer.Start(ctx, "routingService.ListRules")
+	defer telemetry.End(span, &err)
+
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return nil, err
+	}
+	return s.rules.ListRules(ctx, shortURL)
+}
+
+func (s *routingService) DeleteRule(ctx context.Context, owner, shortURL string, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "routingService.DeleteRule")
+	defer telemetry.End(span, &err)
+
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return err
+	}
+	if err := s.rules.DeleteRule(ctx, shortURL, id); err != nil {
+		return err
+	}
+	s.cache.Delete(shortURL)
+	return nil
+}
+
+func (s *routingService) SetVariants(ctx context.Context, owner, shortURL string, variants []*model.Variant) (_ []*model.Variant, err error) {
+	ctx, span := tracer.Start(ctx, "routingService.SetVariants")
+	defer telemetry.End(span, &err)
+
+	if err := validateVariants(variants); err != nil {
+		return nil, err
+	}
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return nil, err
+	}
+
+	destinations := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		variant.ShortUrl = shortURL
+		destinations = append(destinations, variant.Destination)
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.variants.DeleteVariantsExcept(ctx, shortURL, destinations); err != nil {
+			return err
+		}
+		for _, variant := range variants {
+			if err := s.variants.UpsertVariant(ctx, variant); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Delete(shortURL)
+	return variants, nil
+}
+
+func (s *routingService) ListVariants(ctx context.Context, shortURL string) (_ []*model.Variant, err error) {
+	ctx, span := tracer.Start(ctx, "routingService.ListVariants")
+	defer telemetry.End(span, &err)
+
+	return s.variants.ListVariants(ctx, shortURL)
+}
+
+func (s *routingService) DeleteVariants(ctx context.Context, owner, shortURL string) (err error) {
+	ctx, span := tracer.Start(ctx, "routingService.DeleteVariants")
+	defer telemetry.End(span, &err)
+
+	if err := checkOwner(ctx, s.urls, owner, shortURL); err != nil {
+		return err
+	}
+	if err := s.variants.DeleteVariantsExcept(ctx, shortURL, nil); err != nil {
+		return err
+	}
+	s.cache.Delete(shortURL)
+	return nil
+}
+
+func (s *routingService) RecordVariantClick(ctx context.Context, id int64) error {
+	return s.variants.IncrementVariantClickCount(ctx, id)
+}
+
+func (s *routingService) Route(ctx context.Context, shortURL string, visitor *model.Visitor) (*model.Route, error) {
+	routing, err := s.cachedRouting(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &model.Route{Targeted: len(routing.Rules) > 0 || len(routing.Variants) > 0}
+	for _, rule := range routing.Rules {
+		if rule.Matches(visitor) {
+			route.Destination = rule.Destination
+			return route, nil
+		}
+	}
+	if variant := pickVariant(routing.Variants, shortURL, visitor.Key); variant != nil {
+		route.Destination = variant.Destination
+		route.VariantID = variant.ID
+	}
+	return route, nil
+}
+
+// cachedRouting returns the rules and variants of shortURL, loading them at
+// most once per ttl. Links without either are cached too, since they are the
+// common case. The result is shared and must not be modified.
+func (s *routingService) cachedRouting(ctx context.Context, shortURL string) (*linkRouting, error) {
+	if routing, ok := s.cache.Get(shortURL); ok {
+		return routing, nil
+	}
+
+	result := s.lookups.DoChan(shortURL, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LookupTimeout)
+		defer cancel()
+
+		rules, err := s.rules.ListRules(ctx, shortURL)
+		if err != nil {
+			return nil, err
+		}
+		variants, err := s.variants.ListVariants(ctx, shortURL)
+		if err != nil {
+			return nil, err
+		}
+		routing := &linkRouting{Rules: rules, Variants: variants}
+		s.cache.Set(shortURL, routing, s.ttl)
+		return routing, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*linkRouting), nil
+	}
+}
+
+// pickVariant assigns key to one of variants in proportion to their weights.
+// A key keeps its variant as long as the weights do not change; visitors
+// without a key are assigned at random.
+func pickVariant(variants []*model.Variant, shortURL, key string) *model.Variant {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	var bucket int
+	if key == "" {
+		bucket = rand.IntN(total)
+	} else {
+		hash := fnv.New64a()
+		hash.Write([]byte(shortURL + "\x00" + key))
+		bucket = int(hash.Sum64() % uint64(total))
+	}
+	for _, variant := range variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return nil
+}
+
+func validateVariants(variants []*model.Variant) error {
+	if len(variants) < 2 || len(variants) > MaxVariantsPerLink {
+		return ErrInvalidVariantCount
+	}
+	total := 0
+	destinations := make(map[string]struct{}, len(variants))
+	for _, variant := range variants {
+		if !isHTTPURL(variant.Destination) {
+			return ErrInvalidDestination
+		}
+		if _, ok := destinations[variant.Destination]; ok {
+			return ErrDuplicateVariant
+		}
+		destinations[variant.Destination] = struct{}{}
+		if variant.Weight <= 0 {
+			return ErrInvalidWeights
+		}
+		total += variant.Weight
+	}
+	if total != TotalVariantWeight {
+		return ErrInvalidWeights
+	}
+	return nil
+}
+
+// normalizeRule validates rule and brings its conditions into the form
+// visitors are described in.
+func normalizeRule(rule *model.RoutingRule) error {
+	if !isHTTPURL(rule.Destination) {
+		return ErrInvalidDestination
+	}
+
+	rule.Devices = normalizeValues(rule.Devices, strings.ToLower)
+	rule.OSes = normalizeValues(rule.OSes, strings.ToLower)
+	rule.Languages = normalizeValues(rule.Languages, strings.ToLower)
+	rule.Countries = normalizeValues(rule.Countries, strings.ToUpper)
+
+	if len(rule.Devices)+len(rule.OSes)+len(rule.Languages)+len(rule.Countries) == 0 {
+		return ErrRuleWithoutConditions
+	}
+	for _, device := range rule.Devices {
+		if !slices.Contains(routingDevices, device) {
+			return ErrInvalidRuleCondition
+		}
+	}
+	for _, os := range rule.OSes {
+		if !slices.Contains(routingOSes, os) {
+			return ErrInvalidRuleCondition
+		}
+	}
+	for _, language := range rule.Languages {
+		if !languageTag.MatchString(language) {
+			return ErrInvalidRuleCondition
+		}
+	}
+	for _, country := range rule.Countries {
+		if !countryCode.MatchString(country) {
+			return ErrInvalidRuleCondition
+		}
+	}
+	return nil
+}
+
+// checkOwner reports links that do not exist or belong to another owner
+// alike as not found, so that the codes of others cannot be probed.
+func checkOwner(ctx context.Context, urls repository.URLRepository, owner, shortURL string) error {
+	link, err := urls.GetURLByShortened(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+	if owner == "" || link.Owner != owner {
+		return repository.ErrURLNotFound
+	}
+	return nil
+}
+
+func isHTTPURL(target string) bool {
+	parsed, err := url.Parse(target)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func normalizeValues(values []string, normalize func(string) string) []string {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		normalized = append(normalized, normalize(strings.TrimSpace(value)))
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+var (
+	ErrInvalidDestination = model.Error{
+		Message: "Destination must be an absolute http or https URL",
+	}
+	ErrRuleWithoutConditions = model.Error{
+		Message: "A routing rule needs at least one condition",
+	}
+	ErrInvalidRuleCondition = model.Error{
+		Message: "Devices must be mobile, tablet, desktop or bot, operating systems ios, android, windows, macos or linux, languages tags like de or en-us and countries ISO 3166-1 alpha-2 codes",
+	}
+	ErrTooManyRules = model.Error{
+		Message: "A link can have at most 50 routing rules",
+	}
+	ErrInvalidVariantCount = model.Error{
+		Message: "A split needs between 2 and 10 variants",
+	}
+	ErrDuplicateVariant = model.Error{
+		Message: "Variants must have distinct destinations",
+	}
+	ErrInvalidWeights = model.Error{
+		Message: "Variant weights must be positive and add up to 100",
+	}
+)
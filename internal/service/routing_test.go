@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+type mockRoutingRuleRepository struct {
+	mock.Mock
+}
+
+func (m *mockRoutingRuleRepository) CreateRule(ctx context.Context, rule *model.RoutingRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *mockRoutingRuleRepository) ListRules(ctx context.Context, shortURL string) ([]*model.RoutingRule, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoutingRule), args.Error(1)
+}
+
+func (m *mockRoutingRuleRepository) DeleteRule(ctx context.Context, shortURL string, id int64) error {
+	args := m.Called(ctx, shortURL, id)
+	return args.Error(0)
+}
+
+type mockVariantRepository struct {
+	mock.Mock
+}
+
+func (m *mockVariantRepository) ListVariants(ctx context.Context, shortURL string) ([]*model.Variant, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Variant), args.Error(1)
+}
+
+func (m *mockVariantRepository) UpsertVariant(ctx context.Context, variant *model.Variant) error {
+	args := m.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (m *mockVariantRepository) DeleteVariantsExcept(ctx context.Context, shortURL string, destinations []string) error {
+	args := m.Called(ctx, shortURL, destinations)
+	return args.Error(0)
+}
+
+func (m *mockVariantRepository) IncrementVariantClickCount(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// noVariants returns a VariantRepository for links that are not split.
+func noVariants() *mockVariantRepository {
+	variants := new(mockVariantRepository)
+	variants.On("ListVariants", mock.Anything, mock.Anything).Return([]*model.Variant{}, nil)
+	return variants
+}
+
+// testOwner owns the links of the routing tests.
+const testOwner = "owner-1"
+
+func newTestRoutingService(rules repository.RoutingRuleRepository, variants repository.VariantRepository, urls repository.URLRepository) RoutingService {
+	return NewRoutingService(rules, variants, urls, repository.NewNoopTxManager(), 10, time.Minute)
+}
+
+var appStoreRules = []*model.RoutingRule{
+	{ID: 1, ShortUrl: "app", OSes: []string{model.OSiOS}, Destination: "https://apps.apple.com/app/id1"},
+	{ID: 2, ShortUrl: "app", OSes: []string{model.OSAndroid}, Destination: "https://play.google.com/store/apps/details?id=app"},
+	{ID: 3, ShortUrl: "app", Countries: []string{"DE", "AT"}, Languages: []string{"de"}, Destination: "https://example.com/de"},
+}
+
+func TestRoute(t *testing.T) {
+	rules := new(mockRoutingRuleRepository)
+	rules.On("ListRules", mock.Anything, "app").Return(appStoreRules, nil).Once()
+	routing := newTestRoutingService(rules, noVariants(), new(mockRepository))
+
+	tests := []struct {
+		name        string
+		visitor     model.Visitor
+		destination string
+	}{
+		{"ios", model.Visitor{Device: model.DeviceMobile, OS: model.OSiOS, Country: "DE"}, "https://apps.apple.com/app/id1"},
+		{"android", model.Visitor{Device: model.DeviceTablet, OS: model.OSAndroid}, "https://play.google.com/store/apps/details?id=app"},
+		{"regional language", model.Visitor{OS: model.OSWindows, Language: "de-at", Country: "AT"}, "https://example.com/de"},
+		{"wrong country", model.Visitor{OS: model.OSWindows, Language: "de", Country: "CH"}, ""},
+		{"unknown visitor", model.Visitor{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := routing.Route(context.Background(), "app", &tt.visitor)
+
+			require.NoError(t, err)
+			assert.True(t, route.Targeted)
+			assert.Equal(t, tt.destination, route.Destination)
+		})
+	}
+	rules.AssertExpectations(t)
+}
+
+func TestRoute_CachesLinksWithoutRouting(t *testing.T) {
+	rules := new(mockRoutingRuleRepository)
+	rules.On("ListRules", mock.Anything, "plain").Return([]*model.RoutingRule{}, nil).Once()
+	routing := newTestRoutingService(rules, noVariants(), new(mockRepository))
+
+	for range 3 {
+		route, err := routing.Route(context.Background(), "plain", &model.Visitor{OS: model.OSiOS})
+		require.NoError(t, err)
+		assert.False(t, route.Targeted)
+		assert.Empty(t, route.Destination)
+	}
+	rules.AssertExpectations(t)
+}
+
+func TestRoute_SharedLoadOutlivesCancelledCaller(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loadErr := make(chan error, 1)
+	rules := new(mockRoutingRuleRepository)
+	rules.On("ListRules", mock.Anything, "app").Return(appStoreRules, nil).Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+		loadErr <- args.Get(0).(context.Context).Err()
+	}).Once()
+	routing := newTestRoutingService(rules, noVariants(), new(mockRepository))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := routing.Route(ctx, "app", &model.Visitor{OS: model.OSiOS})
+		first <- err
+	}()
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	second := make(chan *model.Route, 1)
+	go func() {
+		route, err := routing.Route(context.Background(), "app", &model.Visitor{OS: model.OSiOS})
+		assert.NoError(t, err)
+		second <- route
+	}()
+	close(release)
+
+	assert.Equal(t, "https://apps.apple.com/app/id1", (<-second).Destination)
+	assert.NoError(t, <-loadErr, "the load does not inherit the cancellation of the first caller")
+	rules.AssertExpectations(t)
+}
+
+func TestCreateRule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rules := new(mockRoutingRuleRepository)
+		urls := new(mockRepository)
+		urls.On("GetURLByShortened", mock.Anything, "app").Return(&model.Url{ShortUrl: "app", Owner: testOwner}, nil)
+		rules.On("ListRules", mock.Anything, "app").Return([]*model.RoutingRule{}, nil).Twice()
+		rules.On("CreateRule", mock.Anything, mock.AnythingOfType("*model.RoutingRule")).Return(nil)
+		routing := newTestRoutingService(rules, noVariants(), urls)
+
+		route, err := routing.Route(context.Background(), "app", &model.Visitor{OS: model.OSiOS})
+		require.NoError(t, err)
+		assert.False(t, route.Targeted)
+
+		rule, err := routing.CreateRule(context.Background(), testOwner, &model.RoutingRule{
+			ShortUrl:    "app",
+			OSes:        []string{"iOS", " ios"},
+			Languages:   []string{"DE-at"},
+			Countries:   []string{"at"},
+			Destination: "https://apps.apple.com/app/id1",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{model.OSiOS}, rule.OSes)
+		assert.Equal(t, []string{"de-at"}, rule.Languages)
+		assert.Equal(t, []string{"AT"}, rule.Countries)
+
+		rules.On("ListRules", mock.Anything, "app").Return([]*model.RoutingRule{rule}, nil).Once()
+		route, err = routing.Route(context.Background(), "app",
+			&model.Visitor{OS: model.OSiOS, Language: "de-at", Country: "AT"})
+		require.NoError(t, err)
+		assert.True(t, route.Targeted, "creating a rule invalidates the cached rules")
+		assert.Equal(t, rule.Destination, route.Destination)
+		rules.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name     string
+		rule     model.RoutingRule
+		expected error
+	}{
+		{"relative destination", model.RoutingRule{OSes: []string{model.OSiOS}, Destination: "/ios"}, ErrInvalidDestination},
+		{"no conditions", model.RoutingRule{Destination: "https://example.com"}, ErrRuleWithoutConditions},
+		{"unknown device", model.RoutingRule{Devices: []string{"watch"}, Destination: "https://example.com"}, ErrInvalidRuleCondition},
+		{"unknown os", model.RoutingRule{OSes: []string{"beos"}, Destination: "https://example.com"}, ErrInvalidRuleCondition},
+		{"invalid language", model.RoutingRule{Languages: []string{"german"}, Destination: "https://example.com"}, ErrInvalidRuleCondition},
+		{"invalid country", model.RoutingRule{Countries: []string{"DEU"}, Destination: "https://example.com"}, ErrInvalidRuleCondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := new(mockRoutingRuleRepository)
+			tt.rule.ShortUrl = "app"
+
+			_, err := newTestRoutingService(rules, noVariants(), new(mockRepository)).CreateRule(context.Background(), testOwner, &tt.rule)
+
+			assert.ErrorIs(t, err, tt.expected)
+			rules.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("too many rules", func(t *testing.T) {
+		rules := new(mockRoutingRuleRepository)
+		urls := new(mockRepository)
+		urls.On("GetURLByShortened", mock.Anything, "app").Return(&model.Url{ShortUrl: "app", Owner: testOwner}, nil)
+		rules.On("ListRules", mock.Anything, "app").Return(make([]*model.RoutingRule, MaxRulesPerLink), nil)
+
+		_, err := newTestRoutingService(rules, noVariants(), urls).CreateRule(context.Background(), testOwner,
+			&model.RoutingRule{ShortUrl: "app", OSes: []string{model.OSiOS}, Destination: "https://example.com"})
+
+		assert.ErrorIs(t, err, ErrTooManyRules)
+		rules.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		urls := new(mockRepository)
+		urls.On("GetURLByShortened", mock.Anything, "missing").Return(nil, repository.ErrURLNotFound)
+
+		_, err := newTestRoutingService(new(mockRoutingRuleRepository), noVariants(), urls).CreateRule(context.Background(), testOwner,
+			&model.RoutingRule{ShortUrl: "missing", OSes: []string{model.OSiOS}, Destination: "https://example.com"})
+
+		assert.ErrorIs(t, err, repository.ErrURLNotFound)
+	})
+
+	t.Run("link of another owner", func(t *testing.T) {
+		rules := new(mockRoutingRuleRepository)
+		urls := new(mockRepository)
+		urls.On("GetURLByShortened", mock.Anything, "app").Return(&model.Url{ShortUrl: "app", Owner: "someone-else"}, nil)
+		routing := newTestRoutingService(rules, noVariants(), urls)
+
+		for _, owner := range []string{testOwner, ""} {
+			_, err := routing.CreateRule(context.Background(), owner,
+				&model.RoutingRule{ShortUrl: "app", OSes: []string{model.OSiOS}, Destination: "https://example.com"})
+			assert.ErrorIs(t, err, repository.ErrURLNotFound, owner)
+			assert.ErrorIs(t, routing.DeleteRule(context.Background(), owner, "app", 1), repository.ErrURLNotFound, owner)
+		}
+		rules.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+		rules.AssertNotCalled(t, "DeleteRule", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestResolveShortURL_AppliesRoutingRules(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	rules := new(mockRoutingRuleRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	mockCache.On("Get", mock.Anything, "app").Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, "app").Return(nil)
+	rules.On("ListRules", mock.Anything, "app").Return(appStoreRules, nil)
+	service := NewURLService(mockRepo, mockCache, logger,
+		WithRouting(newTestRoutingService(rules, noVariants(), mockRepo)))
+
+	ios := &model.Visitor{Device: model.DeviceMobile, OS: model.OSiOS}
+	resolved, err := service.ResolveShortURL(WithVisitor(context.Background(), ios), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "https://apps.apple.com/app/id1", resolved)
+	assert.True(t, ios.Routed)
+
+	desktop := &model.Visitor{Device: model.DeviceDesktop, OS: model.OSLinux}
+	resolved, err = service.ResolveShortURL(WithVisitor(context.Background(), desktop), "app")
+	require.NoError(t, err)
+	assert.Equal(t, originalURL, resolved)
+	assert.True(t, desktop.Routed)
+
+	resolved, err = service.ResolveShortURL(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, originalURL, resolved, "rules only apply to requests that describe a visitor")
+
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestResolveShortURL_IgnoresRoutingFailures(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	rules := new(mockRoutingRuleRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	mockCache.On("Get", mock.Anything, "app").Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, "app").Return(nil)
+	rules.On("ListRules", mock.Anything, "app").Return(nil, errors.New("connection refused"))
+	service := NewURLService(mockRepo, mockCache, logger,
+		WithRouting(newTestRoutingService(rules, noVariants(), mockRepo)))
+
+	visitor := &model.Visitor{OS: model.OSiOS}
+	resolved, err := service.ResolveShortURL(WithVisitor(context.Background(), visitor), "app")
+
+	require.NoError(t, err)
+	assert.Equal(t, originalURL, resolved)
+	assert.False(t, visitor.Routed)
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestRoute_SplitsTraffic(t *testing.T) {
+	rules := new(mockRoutingRuleRepository)
+	rules.On("ListRules", mock.Anything, "ab").Return(appStoreRules[:1], nil)
+	variants := new(mockVariantRepository)
+	variants.On("ListVariants", mock.Anything, "ab").Return([]*model.Variant{
+		{ID: 1, Destination: "https://example.com/a", Weight: 70},
+		{ID: 2, Destination: "https://example.com/b", Weight: 30},
+	}, nil).Once()
+	routing := newTestRoutingService(rules, variants, new(mockRepository))
+
+	counts := make(map[int64]int)
+	for i := range 2000 {
+		route, err := routing.Route(context.Background(), "ab", &model.Visitor{Key: fmt.Sprintf("visitor-%d", i)})
+		require.NoError(t, err)
+		assert.True(t, route.Targeted)
+		counts[route.VariantID]++
+	}
+	assert.InDelta(t, 1400, counts[1], 100)
+	assert.InDelta(t, 600, counts[2], 100)
+
+	first, err := routing.Route(context.Background(), "ab", &model.Visitor{Key: "sticky"})
+	require.NoError(t, err)
+	for range 10 {
+		again, err := routing.Route(context.Background(), "ab", &model.Visitor{Key: "sticky"})
+		require.NoError(t, err)
+		assert.Equal(t, first.VariantID, again.VariantID, "a visitor keeps their variant")
+	}
+
+	route, err := routing.Route(context.Background(), "ab", &model.Visitor{OS: model.OSiOS, Key: "sticky"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://apps.apple.com/app/id1", route.Destination, "routing rules take precedence over the split")
+	assert.Zero(t, route.VariantID)
+	variants.AssertExpectations(t)
+}
+
+func TestSetVariants(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		urls := new(mockRepository)
+		urls.On("GetURLByShortened", mock.Anything, "ab").Return(&model.Url{ShortUrl: "ab", Owner: testOwner}, nil)
+		variants := new(mockVariantRepository)
+		variants.On("DeleteVariantsExcept", mock.Anything, "ab", []string{"https://example.com/a", "https://example.com/b"}).Return(nil)
+		variants.On("UpsertVariant", mock.Anything, mock.AnythingOfType("*model.Variant")).Return(nil).Twice()
+
+		saved, err := newTestRoutingService(new(mockRoutingRuleRepository), variants, urls).SetVariants(context.Background(), testOwner, "ab",
+			[]*model.Variant{
+				{Destination: "https://example.com/a", Weight: 50},
+				{Destination: "https://example.com/b", Weight: 50},
+			})
+
+		require.NoError(t, err)
+		require.Len(t, saved, 2)
+		assert.Equal(t, "ab", saved[0].ShortUrl)
+		variants.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name     string
+		variants []*model.Variant
+		expected error
+	}{
+		{"single variant", []*model.Variant{{Destination: "https://example.com/a", Weight: 100}}, ErrInvalidVariantCount},
+		{"weights below 100", []*model.Variant{
+			{Destination: "https://example.com/a", Weight: 50},
+			{Destination: "https://example.com/b", Weight: 40},
+		}, ErrInvalidWeights},
+		{"zero weight", []*model.Variant{
+			{Destination: "https://example.com/a", Weight: 100},
+			{Destination: "https://example.com/b", Weight: 0},
+		}, ErrInvalidWeights},
+		{"duplicate destination", []*model.Variant{
+			{Destination: "https://example.com/a", Weight: 50},
+			{Destination: "https://example.com/a", Weight: 50},
+		}, ErrDuplicateVariant},
+		{"relative destination", []*model.Variant{
+			{Destination: "/a", Weight: 50},
+			{Destination: "https://example.com/b", Weight: 50},
+		}, ErrInvalidDestination},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants := new(mockVariantRepository)
+
+			_, err := newTestRoutingService(new(mockRoutingRuleRepository), variants, new(mockRepository)).
+				SetVariants(context.Background(), testOwner, "ab", tt.variants)
+
+			assert.ErrorIs(t, err, tt.expected)
+			variants.AssertNotCalled(t, "UpsertVariant", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResolveShortURL_RecordsVariantClicks(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	rules := new(mockRoutingRuleRepository)
+	variants := new(mockVariantRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	originalURL := "https://example.com"
+
+	mockCache.On("Get", mock.Anything, "ab").Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, "ab").Return(nil)
+	rules.On("ListRules", mock.Anything, "ab").Return([]*model.RoutingRule{}, nil)
+	variants.On("ListVariants", mock.Anything, "ab").Return([]*model.Variant{
+		{ID: 7, Destination: "https://example.com/b", Weight: 100},
+	}, nil)
+	variants.On("IncrementVariantClickCount", mock.Anything, int64(7)).Return(nil)
+	service := NewURLService(mockRepo, mockCache, logger, WithRouting(newTestRoutingService(rules, variants, mockRepo)))
+
+	visitor := &model.Visitor{Key: "visitor-1"}
+	resolved, err := service.ResolveShortURL(WithVisitor(context.Background(), visitor), "ab")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b", resolved)
+	assert.True(t, visitor.Routed)
+	time.Sleep(10 * time.Millisecond)
+	variants.AssertExpectations(t)
+}
+
+func TestGetShortURLStats_IncludesVariants(t *testing.T) {
+	mockRepo := new(mockRepository)
+	variants := new(mockVariantRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "ab").Return(&model.Url{ShortUrl: "ab", ClickCount: 3}, nil)
+	variants.On("ListVariants", mock.Anything, "ab").Return([]*model.Variant{
+		{ID: 1, Destination: "https://example.com/a", Weight: 50, ClickCount: 2},
+		{ID: 2, Destination: "https://example.com/b", Weight: 50, ClickCount: 1},
+	}, nil)
+	service := NewURLService(mockRepo, new(mockCache), logger,
+		WithRouting(newTestRoutingService(new(mockRoutingRuleRepository), variants, mockRepo)))
+
+	stats, err := service.GetShortURLStats(context.Background(), "ab")
+
+	require.NoError(t, err)
+	require.Len(t, stats.Variants, 2)
+	assert.Equal(t, int64(2), stats.Variants[0].ClickCount)
+}
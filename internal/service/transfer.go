@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/cache"
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/transfer"
+)
+
+const (
+	ExportPageSize  = 500
+	MaxImportErrors = 100
+	// MaxShortCodeLength is the width of the short_url column.
+	MaxShortCodeLength = 10
+)
+
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictFail      ConflictPolicy = "fail"
+)
+
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch ConflictPolicy(value) {
+	case "", ConflictSkip:
+		return ConflictSkip, nil
+	case ConflictOverwrite:
+		return ConflictOverwrite, nil
+	case ConflictFail:
+		return ConflictFail, nil
+	default:
+		return "", ErrInvalidConflictPolicy
+	}
+}
+
+type ImportOptions struct {
+	Policy ConflictPolicy
+	DryRun bool
+}
+
+type TransferService interface {
+	ExportURLs(ctx context.Context, fn func(url *model.Url) error) error
+	ImportURLs(ctx context.Context, decoder transfer.Decoder, opts ImportOptions) (*model.ImportReport, error)
+}
+
+type transferService struct {
+	repository repository.URLRepository
+	cache      cache.URLCache
+	logger     *slog.Logger
+	tx         repository.TxManager
+	events     EventPublisher
+}
+
+type TransferServiceOption func(*transferService)
+
+// WithTransferEvents publishes a link.created or link.updated event for every
+// imported link, in the transaction that writes it.
+func WithTransferEvents(tx repository.TxManager, events EventPublisher) TransferServiceOption {
+	return func(s *transferService) {
+		s.tx = tx
+		s.events = events
+	}
+}
+
+// WithTransferTx writes imports with the fail policy in a single transaction
+// of tx. Without it a failed write can still leave earlier rows behind.
+func WithTransferTx(tx repository.TxManager) TransferServiceOption {
+	return func(s *transferService) {
+		s.tx = tx
+	}
+}
+
+func NewTransferService(repo repository.URLRepository, cache cache.URLCache, logger *slog.Logger, opts ...TransferServiceOption) TransferService {
+	s := &transferService{
+		repository: repo,
+		cache:      cache,
+		logger:     logger,
+		tx:         repository.NewNoopTxManager(),
+		events:     NewNoopEventPublisher(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *transferService) ExportURLs(ctx context.Context, fn func(url *model.Url) error) error {
+	after := ""
+	for {
+		urls, err := s.repository.ListURLsAfter(ctx, after, ExportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, url := range urls {
+			if err := fn(url); err != nil {
+				return err
+			}
+		}
+
+		if len(urls) < ExportPageSize {
+			return nil
+		}
+		after = urls[len(urls)-1].ShortUrl
+	}
+}
+
+// importRow is a validated record waiting to be written.
+type importRow struct {
+	index  int
+	url    *model.Url
+	exists bool
+}
+
+// ImportURLs writes each record in its own transaction, except with the fail
+// policy: then every record is checked before anything is written, and all of
+// them are written in a single transaction, so that a conflict or a failed
+// write leaves nothing behind.
+func (s *transferService) ImportURLs(ctx context.Context, decoder transfer.Decoder, opts ImportOptions) (*model.ImportReport, error) {
+	report := &model.ImportReport{DryRun: opts.DryRun}
+	atomic := opts.Policy == ConflictFail && !opts.DryRun
+	seen := make(map[string]struct{})
+	var pending []importRow
+
+	for index := 1; ; index++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		url, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Total++
+		if errors.Is(err, transfer.ErrInvalidRecord) {
+			report.AddError(index, "", err.Error(), MaxImportErrors)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		if err := validateImportedURL(url); err != nil {
+			report.AddError(index, url.ShortUrl, err.Error(), MaxImportErrors)
+			continue
+		}
+		if _, ok := seen[url.ShortUrl]; ok {
+			report.AddError(index, url.ShortUrl, ErrDuplicateShortCode.Error(), MaxImportErrors)
+			continue
+		}
+		seen[url.ShortUrl] = struct{}{}
+
+		_, err = s.repository.GetURLByShortened(ctx, url.ShortUrl)
+		if err != nil && !errors.Is(err, repository.ErrURLNotFound) {
+			return report, err
+		}
+		row := importRow{index: index, url: url, exists: err == nil}
+
+		switch {
+		case row.exists && opts.Policy == ConflictFail:
+			report.AddError(index, url.ShortUrl, repository.ErrURLAlreadyExists.Error(), MaxImportErrors)
+			report.Created, report.Overwritten = 0, 0
+			report.Aborted = true
+			return report, nil
+		case row.exists && opts.Policy == ConflictSkip:
+			report.Skipped++
+			continue
+		}
+
+		switch {
+		case opts.DryRun:
+			countImported(report, row.exists)
+		case atomic:
+			pending = append(pending, row)
+		default:
+			if err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+				return s.importRow(ctx, row)
+			}); err != nil {
+				report.AddError(index, url.ShortUrl, err.Error(), MaxImportErrors)
+				continue
+			}
+			s.evict(ctx, url.ShortUrl)
+			countImported(report, row.exists)
+		}
+	}
+
+	if !atomic || len(pending) == 0 {
+		return report, nil
+	}
+
+	var failed *importRow
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range pending {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.importRow(ctx, pending[i]); err != nil {
+				failed = &pending[i]
+				return err
+			}
+		}
+		return nil
+	})
+	if failed != nil {
+		report.AddError(failed.index, failed.url.ShortUrl, err.Error(), MaxImportErrors)
+		report.Aborted = true
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+
+	for _, row := range pending {
+		s.evict(ctx, row.url.ShortUrl)
+		countImported(report, row.exists)
+	}
+	return report, nil
+}
+
+func countImported(report *model.ImportReport, exists bool) {
+	if exists {
+		report.Overwritten++
+	} else {
+		report.Created++
+	}
+}
+
+func (s *transferService) importRow(ctx context.Context, row importRow) error {
+	eventType := model.EventLinkCreated
+	if row.exists {
+		eventType = model.EventLinkUpdated
+	}
+	if err := s.repository.ImportURL(ctx, row.url, row.exists); err != nil {
+		return err
+	}
+	return s.events.Publish(ctx, eventType, row.url.ShortUrl, linkEventData{OriginalURL: row.url.OriginalUrl})
+}
+
+func (s *transferService) evict(ctx context.Context, shortURL string) {
+	if err := s.cache.Delete(ctx, shortURL); cacheFailed(err) {
+		s.logger.Error("Failed to evict URL from cache", "shortURL", shortURL, "error", err)
+	}
+}
+
+func validateImportedURL(url *model.Url) error {
+	if url.ShortUrl == "" || len(url.ShortUrl) > MaxShortCodeLength || strings.Contains(url.ShortUrl, "/") {
+		return ErrInvalidShortCode
+	}
+	if isReservedAlias(url.ShortUrl) {
+		return ErrAliasReserved
+	}
+	if !strings.HasPrefix(url.OriginalUrl, "http://") && !strings.HasPrefix(url.OriginalUrl, "https://") {
+		return ErrInvalidOriginalURL
+	}
+	if url.ClickCount < 0 {
+		return ErrInvalidClickCount
+	}
+	for _, ts := range []string{url.CreatedAt, url.UpdatedAt} {
+		if ts == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, ts); err != nil {
+			return ErrInvalidTimestamp
+		}
+	}
+	return nil
+}
+
+var (
+	ErrInvalidConflictPolicy = model.Error{
+		Message: "Conflict policy must be one of skip, overwrite or fail",
+	}
+	ErrInvalidShortCode = model.Error{
+		Message: "Short code must be 1 to 10 characters long and must not contain slashes",
+	}
+	ErrDuplicateShortCode = model.Error{
+		Message: "Short code appears earlier in the file",
+	}
+	ErrInvalidOriginalURL = model.Error{
+		Message: "Original URL must start with http:// or https://",
+	}
+	ErrInvalidClickCount = model.Error{
+		Message: "Click count must not be negative",
+	}
+	ErrInvalidTimestamp = model.Error{
+		Message: "Timestamps must be in RFC 3339 format",
+	}
+)
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/transfer"
+)
+
+func newTestTransferService() (TransferService, *mockRepository, *mockCache) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewTransferService(mockRepo, mockCache, logger), mockRepo, mockCache
+}
+
+func ndjsonDecoder(lines ...string) transfer.Decoder {
+	return transfer.NewDecoder(strings.NewReader(strings.Join(lines, "\n")), transfer.FormatNDJSON)
+}
+
+func TestExportURLs_Paginates(t *testing.T) {
+	service, mockRepo, _ := newTestTransferService()
+
+	firstPage := make([]*model.Url, ExportPageSize)
+	for i := range firstPage {
+		firstPage[i] = &model.Url{ShortUrl: "code"}
+	}
+	firstPage[len(firstPage)-1] = &model.Url{ShortUrl: "last"}
+	secondPage := []*model.Url{{ShortUrl: "zzz"}}
+
+	mockRepo.On("ListURLsAfter", mock.Anything, "", int32(ExportPageSize)).Return(firstPage, nil)
+	mockRepo.On("ListURLsAfter", mock.Anything, "last", int32(ExportPageSize)).Return(secondPage, nil)
+
+	exported := 0
+	err := service.ExportURLs(context.Background(), func(url *model.Url) error {
+		exported++
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ExportPageSize+1, exported)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportURLs_StopsOnCallbackError(t *testing.T) {
+	service, mockRepo, _ := newTestTransferService()
+
+	mockRepo.On("ListURLsAfter", mock.Anything, "", int32(ExportPageSize)).
+		Return([]*model.Url{{ShortUrl: "a"}, {ShortUrl: "b"}}, nil)
+
+	writeErr := errors.New("broken pipe")
+	err := service.ExportURLs(context.Background(), func(url *model.Url) error {
+		return writeErr
+	})
+
+	assert.ErrorIs(t, err, writeErr)
+}
+
+func TestImportURLs_CreatesNewURLs(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	url := &model.Url{OriginalUrl: "https://google.com", ShortUrl: "ggl", ClickCount: 7}
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("ImportURL", mock.Anything, url, false).Return(nil)
+	mockCache.On("Delete", mock.Anything, "ggl").Return(nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(`{"short_url":"ggl","original_url":"https://google.com","click_count":7}`),
+		ImportOptions{Policy: ConflictSkip})
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.ImportReport{Total: 1, Created: 1}, report)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestImportURLs_SkipsConflicts(t *testing.T) {
+	service, mockRepo, _ := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(&model.Url{ShortUrl: "ggl"}, nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(`{"short_url":"ggl","original_url":"https://google.com"}`),
+		ImportOptions{Policy: ConflictSkip})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Skipped)
+	mockRepo.AssertNotCalled(t, "ImportURL", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportURLs_OverwritesConflictsAndEvictsCache(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(&model.Url{ShortUrl: "ggl"}, nil)
+	mockRepo.On("ImportURL", mock.Anything, mock.Anything, true).Return(nil)
+	mockCache.On("Delete", mock.Anything, "ggl").Return(nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(`{"short_url":"ggl","original_url":"https://google.com"}`),
+		ImportOptions{Policy: ConflictOverwrite})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Overwritten)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestImportURLs_FailPolicyAborts(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "new").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(&model.Url{ShortUrl: "ggl"}, nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(
+			`{"short_url":"new","original_url":"https://new.com"}`,
+			`{"short_url":"ggl","original_url":"https://google.com"}`,
+			`{"short_url":"never","original_url":"https://never.com"}`,
+		),
+		ImportOptions{Policy: ConflictFail})
+
+	require.NoError(t, err)
+	assert.True(t, report.Aborted)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 0, report.Created)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Record)
+	mockRepo.AssertNotCalled(t, "GetURLByShortened", mock.Anything, "never")
+	mockRepo.AssertNotCalled(t, "ImportURL", mock.Anything, mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestImportURLs_FailPolicyWritesAfterScan(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "a").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("GetURLByShortened", mock.Anything, "b").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("ImportURL", mock.Anything, mock.Anything, false).Return(nil)
+	mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(
+			`{"short_url":"a","original_url":"https://a.com"}`,
+			`{"short_url":"b","original_url":"https://b.com"}`,
+		),
+		ImportOptions{Policy: ConflictFail})
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.ImportReport{Total: 2, Created: 2}, report)
+	mockRepo.AssertNumberOfCalls(t, "ImportURL", 2)
+	mockCache.AssertNumberOfCalls(t, "Delete", 2)
+}
+
+func TestImportURLs_FailPolicyWriteErrorAborts(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, mock.Anything).Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("ImportURL", mock.Anything, mock.MatchedBy(func(url *model.Url) bool {
+		return url.ShortUrl == "a"
+	}), false).Return(nil)
+	mockRepo.On("ImportURL", mock.Anything, mock.MatchedBy(func(url *model.Url) bool {
+		return url.ShortUrl == "b"
+	}), false).Return(repository.ErrURLAlreadyExists)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(
+			`{"short_url":"a","original_url":"https://a.com"}`,
+			`{"short_url":"b","original_url":"https://b.com"}`,
+		),
+		ImportOptions{Policy: ConflictFail})
+
+	require.NoError(t, err)
+	assert.True(t, report.Aborted)
+	assert.Equal(t, 0, report.Created)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Record)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestImportURLs_LookupErrorStopsImport(t *testing.T) {
+	service, mockRepo, _ := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(nil, errors.New("connection refused"))
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(`{"short_url":"ggl","original_url":"https://google.com"}`),
+		ImportOptions{Policy: ConflictSkip})
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 1, report.Total)
+	mockRepo.AssertNotCalled(t, "ImportURL", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportURLs_RejectsDuplicateShortCodes(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		service, mockRepo, mockCache := newTestTransferService()
+
+		mockRepo.On("GetURLByShortened", mock.Anything, "dup").Return(nil, repository.ErrURLNotFound)
+		mockRepo.On("ImportURL", mock.Anything, mock.Anything, false).Return(nil)
+		mockCache.On("Delete", mock.Anything, "dup").Return(nil)
+
+		report, err := service.ImportURLs(context.Background(),
+			ndjsonDecoder(
+				`{"short_url":"dup","original_url":"https://first.com"}`,
+				`{"short_url":"dup","original_url":"https://second.com"}`,
+			),
+			ImportOptions{Policy: ConflictOverwrite, DryRun: dryRun})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, ErrDuplicateShortCode.Error(), report.Errors[0].Message)
+		mockRepo.AssertNumberOfCalls(t, "GetURLByShortened", 1)
+	}
+}
+
+func TestImportURLs_DryRunDoesNotWrite(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "new").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("GetURLByShortened", mock.Anything, "ggl").Return(&model.Url{ShortUrl: "ggl"}, nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(
+			`{"short_url":"new","original_url":"https://new.com"}`,
+			`{"short_url":"ggl","original_url":"https://google.com"}`,
+		),
+		ImportOptions{Policy: ConflictOverwrite, DryRun: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.ImportReport{DryRun: true, Total: 2, Created: 1, Overwritten: 1}, report)
+	mockRepo.AssertNotCalled(t, "ImportURL", mock.Anything, mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestImportURLs_ReportsInvalidRecords(t *testing.T) {
+	service, mockRepo, mockCache := newTestTransferService()
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "ok").Return(nil, repository.ErrURLNotFound)
+	mockRepo.On("ImportURL", mock.Anything, mock.Anything, false).Return(nil)
+	mockCache.On("Delete", mock.Anything, "ok").Return(nil)
+
+	report, err := service.ImportURLs(context.Background(),
+		ndjsonDecoder(
+			`not json`,
+			`{"short_url":"","original_url":"https://google.com"}`,
+			`{"short_url":"elevenchars","original_url":"https://google.com"}`,
+			`{"short_url":"api","original_url":"https://google.com"}`,
+			`{"short_url":"ftp","original_url":"ftp://google.com"}`,
+			`{"short_url":"ts","original_url":"https://google.com","created_at":"yesterday"}`,
+			`{"short_url":"ok","original_url":"https://google.com"}`,
+		),
+		ImportOptions{Policy: ConflictSkip})
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Total)
+	assert.Equal(t, 6, report.Failed)
+	assert.Equal(t, 1, report.Created)
+	require.Len(t, report.Errors, 6)
+	assert.Equal(t, ErrInvalidShortCode.Error(), report.Errors[1].Message)
+	assert.Equal(t, ErrInvalidShortCode.Error(), report.Errors[2].Message)
+	assert.Equal(t, ErrAliasReserved.Error(), report.Errors[3].Message)
+	assert.Equal(t, ErrInvalidOriginalURL.Error(), report.Errors[4].Message)
+	assert.Equal(t, ErrInvalidTimestamp.Error(), report.Errors[5].Message)
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictSkip, policy)
+
+	policy, err = ParseConflictPolicy("overwrite")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictOverwrite, policy)
+
+	_, err = ParseConflictPolicy("merge")
+	assert.ErrorIs(t, err, ErrInvalidConflictPolicy)
+}
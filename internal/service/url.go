@@ -2,43 +2,164 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
 	db "github.com/unwale/url-shortener/db/sqlc"
 	"github.com/unwale/url-shortener/internal/domain/cache"
 	"github.com/unwale/url-shortener/internal/domain/model"
 	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+	"github.com/unwale/url-shortener/internal/telemetry"
 )
 
 const (
 	CacheExpiration = 24 * time.Hour
+	// NegativeCacheExpiration is how long an unknown code is remembered, which
+	// keeps scans of random codes away from the database.
+	NegativeCacheExpiration = 30 * time.Second
+	// LookupTimeout bounds a database lookup that is shared by concurrent
+	// resolves of the same code.
+	LookupTimeout = 5 * time.Second
+	MaxListLimit  = 1000
+	// TopClickValues is how many values of every breakdown the stats of a
+	// link include.
+	TopClickValues = 10
 )
 
+var tracer = otel.Tracer("github.com/unwale/url-shortener/internal/service")
+
+// reservedAliases are paths served by the application itself that would
+// otherwise be shadowed by the redirect route.
+var reservedAliases = map[string]struct{}{
+	"api":     {},
+	"healthz": {},
+	"metrics": {},
+	"readyz":  {},
+}
+
+func isReservedAlias(alias string) bool {
+	_, reserved := reservedAliases[alias]
+	return reserved || strings.HasPrefix(alias, "api/")
+}
+
 type URLService interface {
 	CreateShortURL(ctx context.Context, originalURL, alias string) (string, error)
 	ResolveShortURL(ctx context.Context, shortURL string) (string, error)
 	GetShortURLStats(ctx context.Context, shortURL string) (*model.Url, error)
+	DeleteShortURL(ctx context.Context, shortURL string) error
+	ListShortURLs(ctx context.Context, limit, offset int) ([]*model.Url, error)
 }
 
 type urlService struct {
 	repository repository.URLRepository
 	cache      cache.URLCache
 	logger     *slog.Logger
+	lookups    singleflight.Group
+	tx         repository.TxManager
+	events     EventPublisher
+	routing    RoutingService
+	analytics  repository.AnalyticsRepository
+	visitors   repository.VisitorRepository
+	secret     []byte
+	health     LinkHealthService
+	fallback   string
+	fetcher    MetadataFetcher
+	metadata   repository.LinkMetadataRepository
+}
+
+// MetadataFetcher reads the metadata of the pages links point to in the
+// background.
+type MetadataFetcher interface {
+	Enqueue(shortURL, destination string)
+}
+
+type URLServiceOption func(*urlService)
+
+// WithURLEvents publishes link lifecycle events, each in the transaction of
+// the change it describes.
+func WithURLEvents(tx repository.TxManager, events EventPublisher) URLServiceOption {
+	return func(s *urlService) {
+		s.tx = tx
+		s.events = events
+	}
+}
+
+// WithRouting applies the routing rules of a link when ResolveShortURL is
+// called with a visitor.
+func WithRouting(routing RoutingService) URLServiceOption {
+	return func(s *urlService) {
+		s.routing = routing
+	}
+}
+
+// WithAnalytics breaks the clicks of links down by referrer, browser,
+// operating system and device, and counts the resolves by bots.
+func WithAnalytics(analytics repository.AnalyticsRepository) URLServiceOption {
+	return func(s *urlService) {
+		s.analytics = analytics
+	}
+}
+
+// WithUniqueVisitors counts the unique visitors of links. Visitors are
+// counted by an HMAC of their key with secret, which differs between links
+// and cannot be traced back to an address without the secret.
+func WithUniqueVisitors(visitors repository.VisitorRepository, secret string) URLServiceOption {
+	return func(s *urlService) {
+		s.visitors = visitors
+		s.secret = []byte(secret)
+	}
 }
 
-func NewURLService(repo repository.URLRepository, cache cache.URLCache, logger *slog.Logger) URLService {
-	return &urlService{
+// WithLinkHealth reports the health of destinations in stats. With a
+// fallbackURL, visitors of broken links are sent there instead, unless a
+// routing rule or variant sends them elsewhere.
+func WithLinkHealth(health LinkHealthService, fallbackURL string) URLServiceOption {
+	return func(s *urlService) {
+		s.health = health
+		s.fallback = fallbackURL
+	}
+}
+
+// WithLinkMetadata fetches the title, description, favicon and OpenGraph
+// properties of the destinations of created links, and reports them in
+// stats. A nil fetcher only reports what was fetched before.
+func WithLinkMetadata(fetcher MetadataFetcher, metadata repository.LinkMetadataRepository) URLServiceOption {
+	return func(s *urlService) {
+		s.fetcher = fetcher
+		s.metadata = metadata
+	}
+}
+
+func NewURLService(repo repository.URLRepository, cache cache.URLCache, logger *slog.Logger, opts ...URLServiceOption) URLService {
+	s := &urlService{
 		repository: repo,
 		cache:      cache,
 		logger:     logger,
+		tx:         repository.NewNoopTxManager(),
+		events:     NewNoopEventPublisher(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-func (s *urlService) CreateShortURL(ctx context.Context, originalURL, alias string) (string, error) {
+func (s *urlService) CreateShortURL(ctx context.Context, originalURL, alias string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "urlService.CreateShortURL")
+	defer telemetry.End(span, &err)
+
 	if !strings.HasPrefix(originalURL, "http://") && !strings.HasPrefix(originalURL, "https://") {
 		originalURL = "http://" + originalURL
 	}
@@ -49,7 +170,7 @@ func (s *urlService) CreateShortURL(ctx context.Context, originalURL, alias stri
 		if len(alias) < 4 || len(alias) > 20 {
 			return "", ErrInvalidAliasFormat
 		}
-		if strings.HasPrefix(alias, "api/") {
+		if isReservedAlias(alias) {
 			return "", ErrAliasReserved
 		}
 		shortURL = alias
@@ -59,58 +180,301 @@ func (s *urlService) CreateShortURL(ctx context.Context, originalURL, alias stri
 		shortURL = hex.EncodeToString(hash[:])[:8]
 	}
 
-	model, err := s.repository.CreateURL(ctx, &db.CreateUrlParams{
-		OriginalUrl: originalURL,
-		ShortUrl:    shortURL})
+	owner := ownerFromContext(ctx)
+	var created *model.Url
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.repository.CreateURL(ctx, &db.CreateUrlParams{
+			OriginalUrl: originalURL,
+			ShortUrl:    shortURL,
+			Owner:       pgtype.Text{String: owner, Valid: owner != ""},
+		})
+		if err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, model.EventLinkCreated, created.ShortUrl, linkEventData{OriginalURL: created.OriginalUrl})
+	})
 	if err != nil {
 		return "", err
 	}
-	return model.ShortUrl, nil
+
+	if err := s.cache.Delete(ctx, shortURL); cacheFailed(err) {
+		s.logger.Error("Failed to evict URL from cache", "shortURL", shortURL, "error", err)
+	}
+	if s.fetcher != nil {
+		s.fetcher.Enqueue(created.ShortUrl, created.OriginalUrl)
+	}
+	return created.ShortUrl, nil
 }
 
-func (s *urlService) ResolveShortURL(ctx context.Context, shortURL string) (string, error) {
+func (s *urlService) ResolveShortURL(ctx context.Context, shortURL string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "urlService.ResolveShortURL", trace.WithAttributes(
+		attribute.String("short_url", shortURL),
+	))
+	defer telemetry.End(span, &err)
+
 	originalUrl, err := s.cache.Get(ctx, shortURL)
-	if err == nil {
-		go func() {
-			backgroundCtx := context.Background()
-			if err := s.repository.IncrementClickCount(backgroundCtx, shortURL); err != nil {
-				s.logger.Error("Failed to increment click count", "shortURL", shortURL, "error", err)
-			}
-		}()
-		return *originalUrl, nil
+	switch {
+	case err == nil:
+		metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheHit).Inc()
+		go s.recordClick(context.WithoutCancel(ctx), shortURL)
+		return s.applyFallback(ctx, shortURL, *originalUrl, s.route(ctx, shortURL, *originalUrl)), nil
+	case errors.Is(err, cache.ErrCachedNotFound):
+		metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheNegativeHit).Inc()
+		return "", repository.ErrURLNotFound
+	case errors.Is(err, cache.ErrCacheMiss):
+		metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheMiss).Inc()
+	case errors.Is(err, cache.ErrCacheUnavailable):
+		metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheBypass).Inc()
+	default:
+		metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheError).Inc()
 	}
 
-	url, err := s.repository.GetURLByShortened(ctx, shortURL)
+	url, err := s.lookup(ctx, shortURL)
 	if err != nil {
 		return "", err
 	}
 
-	go func() {
-		backgroundCtx := context.Background()
-		if err := s.repository.IncrementClickCount(backgroundCtx, shortURL); err != nil {
-			s.logger.Error("Failed to increment click count", "shortURL", shortURL, "error", err)
+	go s.recordClick(context.WithoutCancel(ctx), shortURL)
+	return s.applyFallback(ctx, shortURL, url, s.route(ctx, shortURL, url)), nil
+}
+
+// route returns where the visitor in ctx is sent: the destination of the
+// first matching routing rule, the variant the visitor is assigned to, or the
+// link's original URL. Routing that cannot be loaded is skipped rather than
+// failing the redirect.
+func (s *urlService) route(ctx context.Context, shortURL, originalURL string) string {
+	visitor, ok := visitorFromContext(ctx)
+	if s.routing == nil || !ok {
+		return originalURL
+	}
+	route, err := s.routing.Route(ctx, shortURL, visitor)
+	if err != nil {
+		s.logger.Error("Failed to load routing rules", "shortURL", shortURL, "error", err)
+		return originalURL
+	}
+	visitor.Routed = route.Targeted
+	if route.VariantID != 0 {
+		metrics.SplitRedirectsTotal.Inc()
+		go s.recordVariantClick(context.WithoutCancel(ctx), shortURL, route.VariantID)
+	} else if route.Destination != "" {
+		metrics.TargetedRedirectsTotal.Inc()
+	}
+	if route.Destination == "" {
+		return originalURL
+	}
+	return route.Destination
+}
+
+// applyFallback returns the fallback URL instead of the original URL of a broken
+// link.
+func (s *urlService) applyFallback(ctx context.Context, shortURL, originalURL, destination string) string {
+	if s.fallback == "" || destination != originalURL || !s.health.IsBroken(shortURL) {
+		return destination
+	}
+	metrics.FallbackRedirectsTotal.Inc()
+	if visitor, ok := visitorFromContext(ctx); ok {
+		visitor.Fallback = true
+	}
+	return s.fallback
+}
+
+// lookup loads shortURL from the repository and caches the outcome. Concurrent
+// lookups of the same code share a single database query, which runs detached
+// from any one caller so that a cancelled request does not fail the others.
+func (s *urlService) lookup(ctx context.Context, shortURL string) (string, error) {
+	result := s.lookups.DoChan(shortURL, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LookupTimeout)
+		defer cancel()
+
+		url, err := s.repository.GetURLByShortened(ctx, shortURL)
+		if errors.Is(err, repository.ErrURLNotFound) {
+			if err := s.cache.SetNotFound(ctx, shortURL, NegativeCacheExpiration); cacheFailed(err) {
+				s.logger.Error("Failed to cache unknown URL", "shortURL", shortURL, "error", err)
+			}
+			return "", err
+		}
+		if err != nil {
+			return "", err
 		}
-		if err := s.cache.Set(backgroundCtx, shortURL, url.OriginalUrl, CacheExpiration); err != nil {
+
+		if err := s.cache.Set(ctx, shortURL, url.OriginalUrl, CacheExpiration); cacheFailed(err) {
 			s.logger.Error("Failed to cache URL", "shortURL", shortURL, "error", err)
 		}
-	}()
+		return url.OriginalUrl, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// cacheFailed reports whether a cache error is worth logging. Calls skipped by
+// the circuit breaker are not, as it logs when it opens.
+func cacheFailed(err error) bool {
+	return err != nil && !errors.Is(err, cache.ErrCacheUnavailable)
+}
+
+// recordClick counts a resolve of shortURL. Resolves by bots are left out of
+// the click count and only show up in the analytics.
+func (s *urlService) recordClick(ctx context.Context, shortURL string) {
+	visitor, ok := visitorFromContext(ctx)
+	if ok && visitor.Bot {
+		metrics.BotClicksTotal.Inc()
+	} else {
+		s.incrementClickCount(ctx, shortURL)
+	}
+	if !ok {
+		return
+	}
+	if s.visitors != nil && !visitor.Bot && !visitor.OptedOut && visitor.Key != "" {
+		if err := s.visitors.AddVisitor(ctx, shortURL, s.visitorHash(shortURL, visitor.Key), time.Now()); err != nil {
+			s.logger.Error("Failed to count unique visitor", "shortURL", shortURL, "error", err)
+		}
+	}
+	if s.analytics == nil {
+		return
+	}
+
+	click := &model.Click{
+		ShortUrl: shortURL,
+		Bot:      visitor.Bot,
+		Referrer: valueOr(visitor.Referrer, "direct"),
+		Browser:  valueOr(visitor.Browser, "other"),
+		OS:       valueOr(visitor.OS, "other"),
+		Device:   valueOr(visitor.Device, "other"),
+	}
+	if err := s.analytics.RecordClick(ctx, click); err != nil {
+		s.logger.Error("Failed to record click analytics", "shortURL", shortURL, "error", err)
+	}
+}
+
+func (s *urlService) visitorHash(shortURL, key string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(shortURL + "\x00" + key))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func (s *urlService) incrementClickCount(ctx context.Context, shortURL string) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.IncrementClickCount(ctx, shortURL); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, model.EventLinkClicked, shortURL, nil)
+	})
+	if err != nil {
+		metrics.ClickIncrementFailuresTotal.Inc()
+		s.logger.Error("Failed to increment click count", "shortURL", shortURL, "error", err)
+	}
+}
 
-	return url.OriginalUrl, nil
+func (s *urlService) recordVariantClick(ctx context.Context, shortURL string, id int64) {
+	if err := s.routing.RecordVariantClick(ctx, id); err != nil {
+		metrics.ClickIncrementFailuresTotal.Inc()
+		s.logger.Error("Failed to increment variant click count", "shortURL", shortURL, "variant", id, "error", err)
+	}
 }
 
-func (s *urlService) GetShortURLStats(ctx context.Context, shortURL string) (*model.Url, error) {
+func (s *urlService) GetShortURLStats(ctx context.Context, shortURL string) (_ *model.Url, err error) {
+	ctx, span := tracer.Start(ctx, "urlService.GetShortURLStats")
+	defer telemetry.End(span, &err)
+
 	url, err := s.repository.GetURLByShortened(ctx, shortURL)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.Url{
+	stats := &model.Url{
 		OriginalUrl: url.OriginalUrl,
 		ShortUrl:    url.ShortUrl,
 		ClickCount:  url.ClickCount,
 		CreatedAt:   url.CreatedAt,
 		UpdatedAt:   url.UpdatedAt,
-	}, nil
+	}
+	if s.routing != nil {
+		if stats.Variants, err = s.routing.ListVariants(ctx, shortURL); err != nil {
+			return nil, err
+		}
+	}
+	if s.analytics != nil {
+		if stats.Analytics, err = s.analytics.GetClickAnalytics(ctx, shortURL, TopClickValues); err != nil {
+			return nil, err
+		}
+	}
+	if s.visitors != nil {
+		// The counts live in Redis, so the stats are still served while it
+		// is unavailable, just without them.
+		visitors, err := s.visitors.GetUniqueVisitors(ctx, shortURL, time.Now())
+		if err != nil {
+			s.logger.Error("Failed to count unique visitors", "shortURL", shortURL, "error", err)
+		}
+		stats.UniqueVisitors = visitors
+	}
+	if s.health != nil {
+		if stats.Health, err = s.health.GetLinkHealth(ctx, shortURL); err != nil {
+			return nil, err
+		}
+	}
+	if s.metadata != nil {
+		if stats.Metadata, err = s.metadata.GetLinkMetadata(ctx, shortURL); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func (s *urlService) DeleteShortURL(ctx context.Context, shortURL string) (err error) {
+	ctx, span := tracer.Start(ctx, "urlService.DeleteShortURL")
+	defer telemetry.End(span, &err)
+
+	// The event is recorded first so that it can still be attributed to the
+	// link's owner; a failed delete rolls it back.
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.events.Publish(ctx, model.EventLinkDeleted, shortURL, nil); err != nil {
+			return err
+		}
+		return s.repository.DeleteURL(ctx, shortURL)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(ctx, shortURL); cacheFailed(err) {
+		s.logger.Error("Failed to evict URL from cache", "shortURL", shortURL, "error", err)
+	}
+	if s.visitors != nil {
+		if err := s.visitors.DeleteVisitors(ctx, shortURL, time.Now()); err != nil {
+			s.logger.Error("Failed to delete unique visitor counts", "shortURL", shortURL, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *urlService) ListShortURLs(ctx context.Context, limit, offset int) (_ []*model.Url, err error) {
+	ctx, span := tracer.Start(ctx, "urlService.ListShortURLs")
+	defer telemetry.End(span, &err)
+
+	if limit <= 0 || limit > MaxListLimit {
+		return nil, ErrInvalidPagination
+	}
+	if offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	return s.repository.ListURLs(ctx, int32(limit), int32(offset))
 }
 
 var (
@@ -120,4 +484,7 @@ var (
 	ErrAliasReserved = model.Error{
 		Message: "Alias is reserved and cannot be used",
 	}
+	ErrInvalidPagination = model.Error{
+		Message: "Limit must be between 1 and 1000 and offset must not be negative",
+	}
 )
@@ -2,17 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	db "github.com/unwale/url-shortener/db/sqlc"
 	"github.com/unwale/url-shortener/internal/domain/cache"
 	"github.com/unwale/url-shortener/internal/domain/model"
 	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
 )
 
 type mockRepository struct {
@@ -40,6 +45,40 @@ func (m *mockRepository) IncrementClickCount(ctx context.Context, shortened stri
 	return args.Error(0)
 }
 
+func (m *mockRepository) DeleteURL(ctx context.Context, shortened string) error {
+	args := m.Called(ctx, shortened)
+	return args.Error(0)
+}
+
+func (m *mockRepository) ListURLs(ctx context.Context, limit, offset int32) ([]*model.Url, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Url), args.Error(1)
+}
+
+func (m *mockRepository) ListURLsAfter(ctx context.Context, after string, limit int32) ([]*model.Url, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Url), args.Error(1)
+}
+
+func (m *mockRepository) ListTopURLs(ctx context.Context, limit int32) ([]*model.Url, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Url), args.Error(1)
+}
+
+func (m *mockRepository) ImportURL(ctx context.Context, url *model.Url, overwrite bool) error {
+	args := m.Called(ctx, url, overwrite)
+	return args.Error(0)
+}
+
 type mockCache struct {
 	mock.Mock
 }
@@ -52,11 +91,39 @@ func (m *mockCache) Get(ctx context.Context, key string) (*string, error) {
 	return args.Get(0).(*string), args.Error(1)
 }
 
+func (m *mockCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *mockCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
 	args := m.Called(ctx, key, value, expiration)
 	return args.Error(0)
 }
 
+func (m *mockCache) MSet(ctx context.Context, entries map[string]string, expiration time.Duration) error {
+	args := m.Called(ctx, entries, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Warm(ctx context.Context, entries map[string]string, expiration time.Duration) (int, error) {
+	args := m.Called(ctx, entries, expiration)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
 func TestCreateShortURL_Success_WithAlias(t *testing.T) {
 	mockRepo := new(mockRepository)
 	mockCache := new(mockCache)
@@ -72,6 +139,7 @@ func TestCreateShortURL_Success_WithAlias(t *testing.T) {
 		OriginalUrl: originalURL,
 		ShortUrl:    alias,
 	}).Return(expectedModel, nil)
+	mockCache.On("Delete", mock.Anything, alias).Return(nil)
 
 	shortURL, err := service.CreateShortURL(context.Background(), originalURL, alias)
 
@@ -81,6 +149,26 @@ func TestCreateShortURL_Success_WithAlias(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateShortURL_RecordsOwner(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	mockRepo.On("CreateURL", mock.Anything, &db.CreateUrlParams{
+		OriginalUrl: "https://www.google.com",
+		ShortUrl:    "my-google",
+		Owner:       pgtype.Text{String: "owner-1", Valid: true},
+	}).Return(&model.Url{OriginalUrl: "https://www.google.com", ShortUrl: "my-google"}, nil)
+	mockCache.On("Delete", mock.Anything, "my-google").Return(nil)
+
+	_, err := service.CreateShortURL(WithOwner(context.Background(), "owner-1"), "https://www.google.com", "my-google")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestCreateURL_Success_NoAlias(t *testing.T) {
 	mockRepo := new(mockRepository)
 	mockCache := new(mockCache)
@@ -95,6 +183,7 @@ func TestCreateURL_Success_NoAlias(t *testing.T) {
 		OriginalUrl: originalURL,
 		ShortUrl:    "ac6bb669",
 	}).Return(expectedModel, nil)
+	mockCache.On("Delete", mock.Anything, "ac6bb669").Return(nil)
 
 	shortURL, err := service.CreateShortURL(context.Background(), originalURL, "")
 
@@ -127,6 +216,21 @@ func TestCreateShortURL_Failure_AliasAlreadyExists(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateShortURL_Failure_ReservedAlias(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL, err := service.CreateShortURL(context.Background(), "https://www.google.com", "healthz")
+
+	assert.ErrorIs(t, err, ErrAliasReserved)
+	assert.Equal(t, "", shortURL)
+
+	mockRepo.AssertNotCalled(t, "CreateURL", mock.Anything, mock.Anything)
+}
+
 func TestResolveShortURL_Success_CacheMiss(t *testing.T) {
 	mockRepo := new(mockRepository)
 	mockCache := new(mockCache)
@@ -179,6 +283,154 @@ func TestResolveShortURL_Success_CacheHit(t *testing.T) {
 	mockRepo.AssertNotCalled(t, "GetURLByShortened", mock.Anything, shortURL)
 }
 
+func TestResolveShortURL_RecordsMetrics(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL := "ac6bb669"
+	originalURL := "https://www.google.com"
+
+	hits := testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheHit))
+	failures := testutil.ToFloat64(metrics.ClickIncrementFailuresTotal)
+
+	mockCache.On("Get", mock.Anything, shortURL).Return(&originalURL, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, shortURL).Return(repository.ErrURLNotFound)
+
+	_, err := service.ResolveShortURL(context.Background(), shortURL)
+
+	time.Sleep(10 * time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheHit)))
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.ClickIncrementFailuresTotal))
+}
+
+func TestResolveShortURL_CacheUnavailable_FallsBackToRepository(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL := "ac6bb669"
+	originalURL := "https://www.google.com"
+
+	bypasses := testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheBypass))
+	mockCache.On("Get", mock.Anything, shortURL).Return(nil, cache.ErrCacheUnavailable)
+	mockRepo.On("GetURLByShortened", mock.Anything, shortURL).Return(&model.Url{OriginalUrl: originalURL}, nil)
+	mockRepo.On("IncrementClickCount", mock.Anything, shortURL).Return(nil)
+	mockCache.On("Set", mock.Anything, shortURL, originalURL, CacheExpiration).Return(cache.ErrCacheUnavailable)
+
+	resolvedURL, err := service.ResolveShortURL(context.Background(), shortURL)
+
+	time.Sleep(10 * time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, originalURL, resolvedURL)
+	assert.Equal(t, bypasses+1, testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheBypass)))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveShortURL_NotFound_CachesNegativeEntry(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	mockCache.On("Get", mock.Anything, "unknown").Return(nil, cache.ErrCacheMiss)
+	mockRepo.On("GetURLByShortened", mock.Anything, "unknown").Return(nil, repository.ErrURLNotFound)
+	mockCache.On("SetNotFound", mock.Anything, "unknown", NegativeCacheExpiration).Return(nil)
+
+	_, err := service.ResolveShortURL(context.Background(), "unknown")
+
+	assert.ErrorIs(t, err, repository.ErrURLNotFound)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "IncrementClickCount", mock.Anything, mock.Anything)
+}
+
+func TestResolveShortURL_NegativeCacheHit(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	negativeHits := testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheNegativeHit))
+	mockCache.On("Get", mock.Anything, "unknown").Return(nil, cache.ErrCachedNotFound)
+
+	_, err := service.ResolveShortURL(context.Background(), "unknown")
+
+	assert.ErrorIs(t, err, repository.ErrURLNotFound)
+	assert.Equal(t, negativeHits+1, testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues(metrics.CacheNegativeHit)))
+	mockRepo.AssertNotCalled(t, "GetURLByShortened", mock.Anything, mock.Anything)
+}
+
+func TestResolveShortURL_RepositoryError_NotCachedAsNotFound(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	dbErr := errors.New("connection refused")
+	mockCache.On("Get", mock.Anything, "ac6bb669").Return(nil, cache.ErrCacheMiss)
+	mockRepo.On("GetURLByShortened", mock.Anything, "ac6bb669").Return(nil, dbErr)
+
+	_, err := service.ResolveShortURL(context.Background(), "ac6bb669")
+
+	assert.ErrorIs(t, err, dbErr)
+	mockCache.AssertNotCalled(t, "SetNotFound", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveShortURL_CoalescesConcurrentMisses(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL := "ac6bb669"
+	originalURL := "https://www.google.com"
+	const callers = 10
+
+	release := make(chan struct{})
+	mockCache.On("Get", mock.Anything, shortURL).Return(nil, cache.ErrCacheMiss)
+	mockRepo.On("GetURLByShortened", mock.Anything, shortURL).
+		Run(func(mock.Arguments) { <-release }).
+		Return(&model.Url{OriginalUrl: originalURL}, nil).
+		Once()
+	mockRepo.On("IncrementClickCount", mock.Anything, shortURL).Return(nil)
+	mockCache.On("Set", mock.Anything, shortURL, originalURL, CacheExpiration).Return(nil).Once()
+
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url, err := service.ResolveShortURL(context.Background(), shortURL)
+			assert.NoError(t, err)
+			results <- url
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for url := range results {
+		assert.Equal(t, originalURL, url)
+	}
+	mockRepo.AssertNumberOfCalls(t, "GetURLByShortened", 1)
+	mockCache.AssertNumberOfCalls(t, "Set", 1)
+}
+
 func TestGetShortURLStats_Success(t *testing.T) {
 	mockRepo := new(mockRepository)
 	mockCache := new(mockCache)
@@ -223,3 +475,154 @@ func TestGetShortURLStats_Failure_NotFound(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeleteShortURL_Success(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL := "ac6bb669"
+
+	mockRepo.On("DeleteURL", mock.Anything, shortURL).Return(nil)
+	mockCache.On("Delete", mock.Anything, shortURL).Return(nil)
+
+	err := service.DeleteShortURL(context.Background(), shortURL)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestDeleteShortURL_Failure_NotFound(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	shortURL := "ac6bb669"
+
+	mockRepo.On("DeleteURL", mock.Anything, shortURL).Return(repository.ErrURLNotFound)
+
+	err := service.DeleteShortURL(context.Background(), shortURL)
+
+	assert.ErrorIs(t, err, repository.ErrURLNotFound)
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, shortURL)
+}
+
+func TestListShortURLs_Success(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	expected := []*model.Url{
+		{OriginalUrl: "https://www.google.com", ShortUrl: "ac6bb669"},
+	}
+	mockRepo.On("ListURLs", mock.Anything, int32(10), int32(20)).Return(expected, nil)
+
+	urls, err := service.ListShortURLs(context.Background(), 10, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, urls)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListShortURLs_Failure_InvalidPagination(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger)
+
+	_, err := service.ListShortURLs(context.Background(), 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidPagination)
+
+	_, err = service.ListShortURLs(context.Background(), MaxListLimit+1, 0)
+	assert.ErrorIs(t, err, ErrInvalidPagination)
+
+	_, err = service.ListShortURLs(context.Background(), 10, -1)
+	assert.ErrorIs(t, err, ErrInvalidPagination)
+
+	mockRepo.AssertNotCalled(t, "ListURLs")
+}
+
+type mockMetadataFetcher struct {
+	mock.Mock
+}
+
+func (m *mockMetadataFetcher) Enqueue(shortURL, destination string) {
+	m.Called(shortURL, destination)
+}
+
+type mockLinkMetadataRepository struct {
+	mock.Mock
+}
+
+func (m *mockLinkMetadataRepository) SaveLinkMetadata(ctx context.Context, metadata *model.LinkMetadata) error {
+	return m.Called(ctx, metadata).Error(0)
+}
+
+func (m *mockLinkMetadataRepository) GetLinkMetadata(ctx context.Context, shortURL string) (*model.LinkMetadata, error) {
+	args := m.Called(ctx, shortURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LinkMetadata), args.Error(1)
+}
+
+func TestCreateShortURL_EnqueuesMetadataFetch(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	fetcher := new(mockMetadataFetcher)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger, WithLinkMetadata(fetcher, new(mockLinkMetadataRepository)))
+
+	mockRepo.On("CreateURL", mock.Anything, &db.CreateUrlParams{
+		OriginalUrl: "http://example.com",
+		ShortUrl:    "example",
+	}).Return(&model.Url{OriginalUrl: "http://example.com", ShortUrl: "example"}, nil).Once()
+	mockRepo.On("CreateURL", mock.Anything, mock.Anything).Return(nil, repository.ErrURLAlreadyExists)
+	mockCache.On("Delete", mock.Anything, "example").Return(nil)
+	fetcher.On("Enqueue", "example", "http://example.com").Once()
+
+	_, err := service.CreateShortURL(context.Background(), "example.com", "example")
+	assert.NoError(t, err)
+
+	_, err = service.CreateShortURL(context.Background(), "example.com", "taken")
+	assert.Error(t, err)
+
+	fetcher.AssertExpectations(t)
+}
+
+func TestGetShortURLStats_Metadata(t *testing.T) {
+	mockRepo := new(mockRepository)
+	mockCache := new(mockCache)
+	metadata := new(mockLinkMetadataRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewURLService(mockRepo, mockCache, logger, WithLinkMetadata(nil, metadata))
+
+	mockRepo.On("GetURLByShortened", mock.Anything, "example").Return(&model.Url{ShortUrl: "example"}, nil)
+	mockRepo.On("GetURLByShortened", mock.Anything, "unread").Return(&model.Url{ShortUrl: "unread"}, nil)
+	metadata.On("GetLinkMetadata", mock.Anything, "example").Return(&model.LinkMetadata{Title: "Example Domain"}, nil)
+	metadata.On("GetLinkMetadata", mock.Anything, "unread").Return(nil, nil)
+
+	stats, err := service.GetShortURLStats(context.Background(), "example")
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats.Metadata) {
+		assert.Equal(t, "Example Domain", stats.Metadata.Title)
+	}
+
+	stats, err = service.GetShortURLStats(context.Background(), "unread")
+	assert.NoError(t, err)
+	assert.Nil(t, stats.Metadata)
+}
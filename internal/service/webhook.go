@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/safehttp"
+	"github.com/unwale/url-shortener/internal/telemetry"
+)
+
+const DefaultDeliveryLimit = 50
+
+// WebhookEventTypes are the events a webhook can subscribe to.
+var WebhookEventTypes = []string{
+	model.EventLinkCreated,
+	model.EventLinkUpdated,
+	model.EventLinkDeleted,
+	model.EventLinkClicked,
+}
+
+type ownerKey struct{}
+
+// WithOwner attributes the links created with ctx to owner.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+func ownerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, owner, url string, eventTypes []string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context, owner string) ([]*model.Webhook, error)
+	GetWebhook(ctx context.Context, owner string, id int64) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, owner string, id int64) error
+	ListDeliveries(ctx context.Context, owner string, webhookID int64, status string, limit int) ([]*model.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, owner string, webhookID, deliveryID int64) error
+}
+
+type webhookService struct {
+	repository repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{
+		repository: repo,
+	}
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, owner, target string, eventTypes []string) (_ *model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.CreateWebhook")
+	defer telemetry.End(span, &err)
+
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	if !publicHost(parsed.Hostname()) {
+		return nil, ErrWebhookURLNotPublic
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return nil, ErrInvalidEventType
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	hook := &model.Webhook{
+		Owner:      owner,
+		URL:        target,
+		Secret:     secret,
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(eventTypes))),
+	}
+	if err := s.repository.CreateWebhook(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// publicHost rejects hosts that are internal on their face. Names that resolve
+// to internal addresses are only caught when deliveries are dialed.
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return safehttp.Public(addr)
+	}
+	return true
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, owner string) (_ []*model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.ListWebhooks")
+	defer telemetry.End(span, &err)
+
+	return s.repository.ListWebhooks(ctx, owner)
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, owner string, id int64) (_ *model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.GetWebhook")
+	defer telemetry.End(span, &err)
+
+	return s.repository.GetWebhook(ctx, owner, id)
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, owner string, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.DeleteWebhook")
+	defer telemetry.End(span, &err)
+
+	return s.repository.DeleteWebhook(ctx, owner, id)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, owner string, webhookID int64, status string, limit int) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.ListDeliveries")
+	defer telemetry.End(span, &err)
+
+	switch status {
+	case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead:
+	default:
+		return nil, ErrInvalidDeliveryStatus
+	}
+	if limit == 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit < 0 || limit > MaxListLimit {
+		return nil, ErrInvalidPagination
+	}
+
+	// Checking the webhook first keeps other owners' delivery logs hidden.
+	if _, err := s.repository.GetWebhook(ctx, owner, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListDeliveries(ctx, webhookID, status, int32(limit))
+}
+
+func (s *webhookService) RetryDelivery(ctx context.Context, owner string, webhookID, deliveryID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "webhookService.RetryDelivery")
+	defer telemetry.End(span, &err)
+
+	if _, err := s.repository.GetWebhook(ctx, owner, webhookID); err != nil {
+		return err
+	}
+	return s.repository.RetryDelivery(ctx, webhookID, deliveryID)
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+var (
+	ErrInvalidWebhookURL = model.Error{
+		Message: "Webhook URL must be an absolute http or https URL",
+	}
+	ErrWebhookURLNotPublic = model.Error{
+		Message: "Webhook URL must point to a public address",
+	}
+	ErrInvalidEventType = model.Error{
+		Message: "Unknown event type",
+	}
+	ErrInvalidDeliveryStatus = model.Error{
+		Message: "Status must be one of pending, delivered or dead",
+	}
+)
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+)
+
+type mockWebhookRepository struct {
+	repository.WebhookRepository
+	mock.Mock
+}
+
+func (m *mockWebhookRepository) CreateWebhook(ctx context.Context, hook *model.Webhook) error {
+	args := m.Called(ctx, hook)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) GetWebhook(ctx context.Context, owner string, id int64) (*model.Webhook, error) {
+	args := m.Called(ctx, owner, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, status string, limit int32) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, status, limit)
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) RetryDelivery(ctx context.Context, webhookID, id int64) error {
+	args := m.Called(ctx, webhookID, id)
+	return args.Error(0)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockWebhookRepository)
+		repo.On("CreateWebhook", mock.Anything, mock.AnythingOfType("*model.Webhook")).Return(nil)
+
+		hook, err := NewWebhookService(repo).CreateWebhook(context.Background(), "owner-1", "https://example.com/hook",
+			[]string{model.EventLinkDeleted, model.EventLinkCreated, model.EventLinkDeleted})
+
+		require.NoError(t, err)
+		assert.Equal(t, "owner-1", hook.Owner)
+		assert.Equal(t, []string{model.EventLinkCreated, model.EventLinkDeleted}, hook.EventTypes)
+		assert.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+		assert.Len(t, hook.Secret, len("whsec_")+64)
+		repo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		expected   error
+	}{
+		{"relative url", "/hook", nil, ErrInvalidWebhookURL},
+		{"unsupported scheme", "ftp://example.com", nil, ErrInvalidWebhookURL},
+		{"metadata address", "http://169.254.169.254/latest", nil, ErrWebhookURLNotPublic},
+		{"loopback address", "http://[::1]:8080/hook", nil, ErrWebhookURLNotPublic},
+		{"private address", "https://10.0.0.5/hook", nil, ErrWebhookURLNotPublic},
+		{"localhost", "http://localhost:9000/hook", nil, ErrWebhookURLNotPublic},
+		{"unknown event type", "https://example.com", []string{"link.expired"}, ErrInvalidEventType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockWebhookRepository)
+
+			_, err := NewWebhookService(repo).CreateWebhook(context.Background(), "owner-1", tt.url, tt.eventTypes)
+
+			assert.ErrorIs(t, err, tt.expected)
+			repo.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		repo := new(mockWebhookRepository)
+		repo.On("GetWebhook", mock.Anything, "owner-1", int64(7)).Return(&model.Webhook{ID: 7}, nil)
+		repo.On("ListDeliveries", mock.Anything, int64(7), model.DeliveryDead, int32(DefaultDeliveryLimit)).
+			Return([]*model.WebhookDelivery{{ID: 1}}, nil)
+
+		deliveries, err := NewWebhookService(repo).ListDeliveries(context.Background(), "owner-1", 7, model.DeliveryDead, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("other owner's webhook", func(t *testing.T) {
+		repo := new(mockWebhookRepository)
+		repo.On("GetWebhook", mock.Anything, "owner-2", int64(7)).Return(nil, repository.ErrWebhookNotFound)
+
+		_, err := NewWebhookService(repo).ListDeliveries(context.Background(), "owner-2", 7, "", 10)
+
+		assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+		repo.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := NewWebhookService(new(mockWebhookRepository)).ListDeliveries(context.Background(), "owner-1", 7, "lost", 10)
+		assert.ErrorIs(t, err, ErrInvalidDeliveryStatus)
+	})
+}
+
+func TestRetryDelivery(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	repo.On("GetWebhook", mock.Anything, "owner-1", int64(7)).Return(&model.Webhook{ID: 7}, nil)
+	repo.On("RetryDelivery", mock.Anything, int64(7), int64(3)).Return(repository.ErrDeliveryNotRetryable)
+
+	err := NewWebhookService(repo).RetryDelivery(context.Background(), "owner-1", 7, 3)
+
+	assert.ErrorIs(t, err, repository.ErrDeliveryNotRetryable)
+	repo.AssertExpectations(t)
+}
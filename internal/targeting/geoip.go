@@ -0,0 +1,138 @@
+package targeting
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// GeoDB maps IP addresses to countries using a local database of address
+// ranges, so that lookups need no network calls.
+type GeoDB struct {
+	ranges []ipRange
+}
+
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// OpenGeoDB loads a CSV country database from path. See ReadGeoDB for the
+// format.
+func OpenGeoDB(path string) (*GeoDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	return ReadGeoDB(f)
+}
+
+// ReadGeoDB reads a CSV country database. Every record is either
+// "network,country" with a CIDR network or "first,last,country" with an
+// inclusive address range, as in the freely available DB-IP country lite
+// database. Lines starting with # and a header line are skipped, and ranges
+// must not overlap.
+func ReadGeoDB(r io.Reader) (*GeoDB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.ReuseRecord = true
+
+	db := &GeoDB{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := parseRange(record)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+		}
+		if entry.country != "" && entry.country != "ZZ" {
+			db.ranges = append(db.ranges, entry)
+		}
+	}
+
+	slices.SortFunc(db.ranges, func(a, b ipRange) int {
+		return a.start.Compare(b.start)
+	})
+	return db, nil
+}
+
+func parseRange(record []string) (ipRange, error) {
+	switch len(record) {
+	case 2:
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		prefix = prefix.Masked()
+		return ipRange{start: prefix.Addr(), end: lastAddr(prefix), country: country(record[1])}, nil
+	case 3:
+		start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return ipRange{}, fmt.Errorf("invalid range %s-%s", start, end)
+		}
+		return ipRange{start: start, end: end, country: country(record[2])}, nil
+	default:
+		return ipRange{}, fmt.Errorf("expected 2 or 3 fields, got %d", len(record))
+	}
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country addr is
+// located in, or "" if it is unknown.
+func (db *GeoDB) Country(addr netip.Addr) string {
+	if db == nil || !addr.IsValid() {
+		return ""
+	}
+	addr = addr.Unmap()
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r ipRange, addr netip.Addr) int {
+		return r.start.Compare(addr)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || addr.Is4() != db.ranges[i].start.Is4() || db.ranges[i].end.Less(addr) {
+		return ""
+	}
+	return db.ranges[i].country
+}
+
+// Len returns the number of address ranges in the database.
+func (db *GeoDB) Len() int {
+	return len(db.ranges)
+}
+
+func country(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
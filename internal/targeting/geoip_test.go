@@ -0,0 +1,53 @@
+package targeting
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGeoDB = `# test database
+network,country
+1.0.0.0/24,au
+2.0.0.0,2.15.255.255,FR
+2a02:2e0::,2a02:2e0:ffff:ffff:ffff:ffff:ffff:ffff,DE
+10.0.0.0/8,ZZ
+`
+
+func TestGeoDB(t *testing.T) {
+	db, err := ReadGeoDB(strings.NewReader(testGeoDB))
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.Len())
+
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{"1.0.0.0", "AU"},
+		{"1.0.0.255", "AU"},
+		{"1.0.1.0", ""},
+		{"2.10.0.1", "FR"},
+		{"::ffff:2.10.0.1", "FR"},
+		{"2a02:2e0::1", "DE"},
+		{"10.1.2.3", ""},
+		{"0.0.0.1", ""},
+		{"::1", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, db.Country(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+
+	var missing *GeoDB
+	assert.Empty(t, missing.Country(netip.MustParseAddr("1.0.0.1")))
+}
+
+func TestReadGeoDB_Invalid(t *testing.T) {
+	_, err := ReadGeoDB(strings.NewReader("1.0.0.0/24,AU\nnot-an-ip,FR\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = ReadGeoDB(strings.NewReader("1.0.0.0/24,AU\n2.0.0.9,2.0.0.1,FR\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package targeting
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PreferredLanguage returns the lowercased language tag with the highest
+// quality in an Accept-Language header, or "" when there is none. Ties go to
+// the tag listed first.
+func PreferredLanguage(acceptLanguage string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality > bestQuality {
+			best, bestQuality = tag, quality
+		}
+	}
+	return best
+}
@@ -0,0 +1,25 @@
+package targeting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"de-AT,de;q=0.9,en;q=0.8", "de-at"},
+		{"en;q=0.5, fr", "fr"},
+		{"fr;q=0.8, it;q=0.8", "fr"},
+		{"*, es;q=0.1", "es"},
+		{"nl;q=0, pl;q=bad", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PreferredLanguage(tt.header), tt.header)
+	}
+}
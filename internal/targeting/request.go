@@ -0,0 +1,38 @@
+package targeting
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// prefetchHeaders are sent by browsers that load a link speculatively,
+// before or without the user following it.
+var prefetchHeaders = []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"}
+
+// IsPrefetch reports whether h belongs to a prefetch or preview request.
+func IsPrefetch(h http.Header) bool {
+	for _, name := range prefetchHeaders {
+		value := strings.ToLower(h.Get(name))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "preview") || strings.Contains(value, "prerender") {
+			return true
+		}
+	}
+	return false
+}
+
+// OptsOut reports whether h asks not to be tracked, with either Do Not Track
+// or Global Privacy Control.
+func OptsOut(h http.Header) bool {
+	return strings.TrimSpace(h.Get("DNT")) == "1" || strings.TrimSpace(h.Get("Sec-GPC")) == "1"
+}
+
+// ReferrerDomain returns the host of a Referer header without a leading
+// "www.", or "" if there is none.
+func ReferrerDomain(referer string) string {
+	parsed, err := url.Parse(referer)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
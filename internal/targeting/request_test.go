@@ -0,0 +1,41 @@
+package targeting
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPrefetch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected bool
+	}{
+		{"chrome speculation rules", http.Header{"Sec-Purpose": {"prefetch;prerender"}}, true},
+		{"purpose", http.Header{"Purpose": {"prefetch"}}, true},
+		{"safari preview", http.Header{"X-Purpose": {"preview"}}, true},
+		{"firefox", http.Header{"X-Moz": {"prefetch"}}, true},
+		{"navigation", http.Header{"Accept": {"text/html"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsPrefetch(tt.header))
+		})
+	}
+}
+
+func TestOptsOut(t *testing.T) {
+	assert.True(t, OptsOut(http.Header{"Dnt": {"1"}}))
+	assert.True(t, OptsOut(http.Header{"Sec-Gpc": {"1"}}))
+	assert.False(t, OptsOut(http.Header{"Dnt": {"0"}}))
+	assert.False(t, OptsOut(http.Header{}))
+}
+
+func TestReferrerDomain(t *testing.T) {
+	assert.Equal(t, "news.ycombinator.com", ReferrerDomain("https://news.ycombinator.com/item?id=1"))
+	assert.Equal(t, "example.com", ReferrerDomain("https://WWW.Example.com:8443/path"))
+	assert.Equal(t, "", ReferrerDomain(""))
+	assert.Equal(t, "", ReferrerDomain("not a url"))
+}
@@ -0,0 +1,107 @@
+package targeting
+
+import (
+	"strings"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+// botMarkers identify crawlers, the link unfurlers of chat apps and social
+// networks, headless browsers and HTTP libraries.
+var botMarkers = []string{
+	"bot", "crawler", "spider", "slurp", "preview", "facebookexternalhit", "facebookcatalog",
+	"slack-imgproxy", "whatsapp", "embedly", "iframely", "outbrain", "vkshare",
+	"headlesschrome", "phantomjs", "lighthouse",
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client", "okhttp", "java/", "libwww-perl",
+}
+
+// unfurlerMarkers identify the crawlers of chat apps and social networks that
+// build a card for links shared there. iMessage identifies as both
+// facebookexternalhit and Twitterbot.
+var unfurlerMarkers = []string{
+	"slackbot", "twitterbot", "facebookexternalhit", "linkedinbot", "discordbot", "telegrambot",
+	"whatsapp", "skypeuripreview", "microsoftpreview", "pinterestbot", "redditbot", "mastodon",
+	"vkshare", "embedly", "iframely",
+}
+
+// browserMarkers map User-Agent tokens to browsers, most specific first:
+// Edge, Opera and Samsung Internet also claim to be Chrome, and Chrome claims
+// to be Safari.
+var browserMarkers = []struct {
+	token   string
+	browser string
+}{
+	{"edg", model.BrowserEdge},
+	{"opr/", model.BrowserOpera},
+	{"opera", model.BrowserOpera},
+	{"samsungbrowser", model.BrowserSamsung},
+	{"firefox", model.BrowserFirefox},
+	{"fxios", model.BrowserFirefox},
+	{"chrome", model.BrowserChrome},
+	{"crios", model.BrowserChrome},
+	{"safari", model.BrowserSafari},
+}
+
+// ParseUserAgent classifies a User-Agent header by device class and operating
+// system. It looks for well-known tokens only, which is enough to tell phones,
+// tablets and desktops apart; unknown agents get empty values.
+func ParseUserAgent(userAgent string) (device, os string) {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "", ""
+	}
+
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		os = model.OSiOS
+	case strings.Contains(ua, "android"):
+		os = model.OSAndroid
+	case strings.Contains(ua, "windows"):
+		os = model.OSWindows
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		os = model.OSMacOS
+	case strings.Contains(ua, "linux"), strings.Contains(ua, "x11"):
+		os = model.OSLinux
+	}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return model.DeviceBot, os
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		os == model.OSAndroid && !strings.Contains(ua, "mobile"):
+		device = model.DeviceTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"):
+		device = model.DeviceMobile
+	case os != "":
+		device = model.DeviceDesktop
+	}
+	return device, os
+}
+
+// IsUnfurler reports whether a User-Agent header belongs to a crawler that
+// builds a link preview card, rather than one that indexes pages.
+func IsUnfurler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range unfurlerMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseBrowser returns the browser a User-Agent header belongs to, or "" if
+// it is not a well-known one.
+func ParseBrowser(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range browserMarkers {
+		if strings.Contains(ua, marker.token) {
+			return marker.browser
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package targeting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		device    string
+		os        string
+	}{
+		{"iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", model.DeviceMobile, model.OSiOS},
+		{"ipad", "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", model.DeviceTablet, model.OSiOS},
+		{"android phone", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36", model.DeviceMobile, model.OSAndroid},
+		{"android tablet", "Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", model.DeviceTablet, model.OSAndroid},
+		{"windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", model.DeviceDesktop, model.OSWindows},
+		{"mac", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15", model.DeviceDesktop, model.OSMacOS},
+		{"linux", "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", model.DeviceDesktop, model.OSLinux},
+		{"crawler", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", model.DeviceBot, ""},
+		{"link unfurler", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", model.DeviceBot, ""},
+		{"http library", "curl/8.4.0", model.DeviceBot, ""},
+		{"unknown", "Lynx/2.8.9rel.1 libwww-FM/2.14", "", ""},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, os := ParseUserAgent(tt.userAgent)
+			assert.Equal(t, tt.device, device)
+			assert.Equal(t, tt.os, os)
+		})
+	}
+}
+
+func TestParseBrowser(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		browser   string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36 Edg/120.0", model.BrowserEdge},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36 OPR/106.0", model.BrowserOpera},
+		{"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 SamsungBrowser/23.0 Chrome/115.0 Mobile Safari/537.36", model.BrowserSamsung},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", model.BrowserFirefox},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 CriOS/120.0 Mobile/15E148 Safari/604.1", model.BrowserChrome},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", model.BrowserChrome},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15", model.BrowserSafari},
+		{"Lynx/2.8.9rel.1 libwww-FM/2.14", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.browser, ParseBrowser(tt.userAgent), tt.userAgent)
+	}
+}
+
+func TestIsUnfurler(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		unfurler  bool
+	}{
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"Twitterbot/1.0", true},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"LinkedInBot/1.0 (compatible; Mozilla/5.0; Apache-HttpClient +http://www.linkedin.com)", true},
+		{"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", true},
+		{"TelegramBot (like TwitterBot)", true},
+		{"WhatsApp/2.23.20.0", true},
+		{"http.rb/5.1.1 (Mastodon/4.2.0; +https://mastodon.social/)", true},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", false},
+		{"curl/8.4.0", false},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.unfurler, IsUnfurler(tt.userAgent), tt.userAgent)
+	}
+}
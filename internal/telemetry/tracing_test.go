@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/unwale/url-shortener/internal/config"
+)
+
+func TestSetupTracing(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		shutdown, err := SetupTracing(context.Background(), &config.Config{TracingExporter: ExporterNone})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		shutdown, err := SetupTracing(context.Background(), &config.Config{
+			TracingExporter:    ExporterStdout,
+			TracingSampleRatio: 1,
+		})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unsupported exporter", func(t *testing.T) {
+		_, err := SetupTracing(context.Background(), &config.Config{TracingExporter: "zipkin"})
+		assert.Error(t, err)
+	})
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, okSpan := tracer.Start(context.Background(), "ok")
+	var noErr error
+	End(okSpan, &noErr)
+
+	_, failedSpan := tracer.Start(context.Background(), "failed")
+	err := errors.New("boom")
+	End(failedSpan, &err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+}
@@ -0,0 +1,243 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+
+	maxLineSize = 1 << 20
+)
+
+var csvHeader = []string{"short_url", "original_url", "click_count", "created_at", "updated_at", "owner"}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format, expected csv or ndjson")
+	ErrInvalidRecord     = errors.New("invalid record")
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+type record struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	ClickCount  int64  `json:"click_count"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	Owner       string `json:"owner,omitempty"`
+}
+
+func fromModel(url *model.Url) record {
+	return record{
+		ShortURL:    url.ShortUrl,
+		OriginalURL: url.OriginalUrl,
+		ClickCount:  url.ClickCount,
+		CreatedAt:   url.CreatedAt,
+		UpdatedAt:   url.UpdatedAt,
+		Owner:       url.Owner,
+	}
+}
+
+func (r record) toModel() *model.Url {
+	return &model.Url{
+		OriginalUrl: r.OriginalURL,
+		ShortUrl:    r.ShortURL,
+		ClickCount:  r.ClickCount,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		Owner:       r.Owner,
+	}
+}
+
+type Encoder interface {
+	Encode(url *model.Url) error
+	Flush() error
+}
+
+func NewEncoder(w io.Writer, format Format) Encoder {
+	if format == FormatCSV {
+		return &csvEncoder{writer: csv.NewWriter(w)}
+	}
+	return &ndjsonEncoder{writer: bufio.NewWriter(w)}
+}
+
+type csvEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(url *model.Url) error {
+	if !e.headerWritten {
+		if err := e.writer.Write(csvHeader); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	r := fromModel(url)
+	return e.writer.Write([]string{
+		r.ShortURL,
+		r.OriginalURL,
+		strconv.FormatInt(r.ClickCount, 10),
+		r.CreatedAt,
+		r.UpdatedAt,
+		r.Owner,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	if !e.headerWritten {
+		if err := e.writer.Write(csvHeader); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonEncoder struct {
+	writer *bufio.Writer
+}
+
+func (e *ndjsonEncoder) Encode(url *model.Url) error {
+	line, err := json.Marshal(fromModel(url))
+	if err != nil {
+		return err
+	}
+	if _, err := e.writer.Write(line); err != nil {
+		return err
+	}
+	return e.writer.WriteByte('\n')
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+// Decoder reads links one at a time and returns io.EOF once the input is
+// exhausted. Errors wrapping ErrInvalidRecord only affect the current record,
+// so callers may keep decoding after them.
+type Decoder interface {
+	Decode() (*model.Url, error)
+}
+
+func NewDecoder(r io.Reader, format Format) Decoder {
+	if format == FormatCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		return &csvDecoder{reader: reader}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &ndjsonDecoder{scanner: scanner}
+}
+
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func (d *csvDecoder) Decode() (*model.Url, error) {
+	if d.columns == nil {
+		header, err := d.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		d.columns = make(map[string]int, len(header))
+		for i, name := range header {
+			d.columns[name] = i
+		}
+		for _, name := range []string{"short_url", "original_url"} {
+			if _, ok := d.columns[name]; !ok {
+				return nil, fmt.Errorf("csv header is missing column %q", name)
+			}
+		}
+	}
+
+	fields, err := d.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		return nil, err
+	}
+
+	field := func(name string) string {
+		i, ok := d.columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return fields[i]
+	}
+
+	r := record{
+		ShortURL:    field("short_url"),
+		OriginalURL: field("original_url"),
+		CreatedAt:   field("created_at"),
+		UpdatedAt:   field("updated_at"),
+		Owner:       field("owner"),
+	}
+	if clicks := field("click_count"); clicks != "" {
+		r.ClickCount, err = strconv.ParseInt(clicks, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: click_count %q is not a number", ErrInvalidRecord, clicks)
+		}
+	}
+	return r.toModel(), nil
+}
+
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *ndjsonDecoder) Decode() (*model.Url, error) {
+	for d.scanner.Scan() {
+		line := d.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		return r.toModel(), nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,122 @@
+package transfer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+)
+
+var testURLs = []*model.Url{
+	{
+		OriginalUrl: "https://google.com",
+		ShortUrl:    "ac6bb669",
+		ClickCount:  42,
+		CreatedAt:   "2025-01-02T03:04:05Z",
+		UpdatedAt:   "2025-02-03T04:05:06Z",
+		Owner:       "1f2e3d4c5b6a7988",
+	},
+	{
+		OriginalUrl: "https://example.com/?q=a,b",
+		ShortUrl:    "exmpl",
+	},
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("ndjson")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder := NewEncoder(&buf, format)
+			for _, url := range testURLs {
+				require.NoError(t, encoder.Encode(url))
+			}
+			require.NoError(t, encoder.Flush())
+
+			decoder := NewDecoder(&buf, format)
+			for _, expected := range testURLs {
+				url, err := decoder.Decode()
+				require.NoError(t, err)
+				assert.Equal(t, expected, url)
+			}
+
+			_, err := decoder.Decode()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestCSVEncoder_EmptyExportHasHeader(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf, FormatCSV)
+	require.NoError(t, encoder.Flush())
+
+	assert.Equal(t, "short_url,original_url,click_count,created_at,updated_at,owner\n", buf.String())
+}
+
+func TestCSVDecoder(t *testing.T) {
+	t.Run("columns in any order", func(t *testing.T) {
+		input := "original_url,short_url\nhttps://google.com,ggl\n"
+		decoder := NewDecoder(strings.NewReader(input), FormatCSV)
+
+		url, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, &model.Url{OriginalUrl: "https://google.com", ShortUrl: "ggl"}, url)
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		decoder := NewDecoder(strings.NewReader("short_url\nggl\n"), FormatCSV)
+
+		_, err := decoder.Decode()
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidRecord)
+	})
+
+	t.Run("invalid click count is recoverable", func(t *testing.T) {
+		input := "short_url,original_url,click_count\nggl,https://google.com,many\nyh,https://yahoo.com,3\n"
+		decoder := NewDecoder(strings.NewReader(input), FormatCSV)
+
+		_, err := decoder.Decode()
+		assert.ErrorIs(t, err, ErrInvalidRecord)
+
+		url, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), url.ClickCount)
+	})
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	input := "{\"short_url\":\"ggl\",\"original_url\":\"https://google.com\"}\n\nnot json\n{\"short_url\":\"yh\",\"original_url\":\"https://yahoo.com\"}\n"
+	decoder := NewDecoder(strings.NewReader(input), FormatNDJSON)
+
+	url, err := decoder.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "ggl", url.ShortUrl)
+
+	_, err = decoder.Decode()
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	url, err = decoder.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "yh", url.ShortUrl)
+
+	_, err = decoder.Decode()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/outbox"
+)
+
+// Dispatcher is an outbox sink that fans events out to the webhooks
+// registered by the owner of the link. It only enqueues deliveries, which the
+// Worker sends, so a slow receiver does not hold up the outbox.
+type Dispatcher struct {
+	repo repository.WebhookRepository
+}
+
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+// Send enqueues event for every subscribed webhook. Enqueueing is idempotent,
+// so the outbox may safely send the same event again.
+func (d *Dispatcher) Send(ctx context.Context, event *model.Event) error {
+	if event.Owner == "" {
+		return nil
+	}
+	hooks, err := d.repo.ListSubscribedWebhooks(ctx, event.Owner, event.Type)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(outbox.NewMessage(event))
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if err := d.repo.EnqueueDelivery(ctx, hook.ID, event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
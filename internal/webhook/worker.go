@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/metrics"
+	"github.com/unwale/url-shortener/internal/safehttp"
+)
+
+// DefaultLease is how long a worker reserves a claimed batch by default.
+const DefaultLease = time.Minute
+
+const (
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = time.Hour
+	// maxResponseBytes bounds how much of a response is read before the
+	// connection is reused.
+	maxResponseBytes = 64 << 10
+)
+
+// errBlockedDestination is recorded for webhooks on internal addresses, so
+// that the delivery log does not reveal what is listening there.
+var errBlockedDestination = errors.New("blocked destination")
+
+type WorkerOptions struct {
+	BatchSize    int32
+	PollInterval time.Duration
+	// Lease is how long a claimed batch is reserved for this worker. It must
+	// comfortably exceed the time it takes to send a batch.
+	Lease time.Duration
+	// MaxAttempts is how often a delivery is tried before it is marked dead.
+	MaxAttempts int
+}
+
+// Worker sends enqueued deliveries to their webhooks, signing each request
+// with the webhook's secret. Failed deliveries are retried with exponential
+// backoff until MaxAttempts, after which they are dead until retried through
+// the API.
+type Worker struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	opts   WorkerOptions
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewWorker returns a worker that sends deliveries with client, which should be
+// one from safehttp.NewClient so that webhooks cannot reach internal services.
+func NewWorker(repo repository.WebhookRepository, client *http.Client, opts WorkerOptions, logger *slog.Logger) *Worker {
+	return &Worker{
+		repo:   repo,
+		client: client,
+		opts:   opts,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run sends deliveries until ctx is cancelled. Several workers may share the
+// same queue; each claims its own batches.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := w.DeliverBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Error("Failed to deliver webhooks", "error", err)
+		}
+
+		// Keep draining while there is a backlog.
+		if sent < int(w.opts.BatchSize) || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// DeliverBatch claims one batch of due deliveries and sends it, returning how
+// many deliveries were claimed. Failed sends are scheduled for retry rather
+// than returned.
+func (w *Worker) DeliverBatch(ctx context.Context) (int, error) {
+	deliveries, err := w.repo.ClaimDeliveries(ctx, w.opts.BatchSize, w.opts.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, delivery := range deliveries {
+		if err := w.deliver(ctx, delivery); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(deliveries), errors.Join(errs...)
+}
+
+func (w *Worker) deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	status, err := w.send(ctx, delivery)
+	switch {
+	case err == nil:
+		metrics.WebhookAttemptsTotal.WithLabelValues(metrics.WebhookDelivered).Inc()
+		return w.repo.RecordAttempt(ctx, delivery.ID, model.DeliveryDelivered, status, "", 0)
+	case delivery.Attempts >= w.opts.MaxAttempts:
+		metrics.WebhookAttemptsTotal.WithLabelValues(metrics.WebhookDead).Inc()
+		w.logger.Warn("Giving up on webhook delivery", "id", delivery.ID, "webhook_id", delivery.WebhookID,
+			"attempts", delivery.Attempts, "error", err)
+		return w.repo.RecordAttempt(ctx, delivery.ID, model.DeliveryDead, status, err.Error(), 0)
+	default:
+		metrics.WebhookAttemptsTotal.WithLabelValues(metrics.WebhookFailed).Inc()
+		w.logger.Debug("Failed to deliver webhook", "id", delivery.ID, "webhook_id", delivery.WebhookID,
+			"attempts", delivery.Attempts, "error", err)
+		return w.repo.RecordAttempt(ctx, delivery.ID, model.DeliveryPending, status, err.Error(), retryDelay(delivery.Attempts))
+	}
+}
+
+// send POSTs the delivery and returns the response status, if any.
+func (w *Worker) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := w.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if errors.Is(err, safehttp.ErrNonPublicAddress) {
+		return 0, errBlockedDestination
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()                                                 //nolint:errcheck
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes)) // allow the connection to be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay doubles with every attempt, from minRetryDelay up to
+// maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
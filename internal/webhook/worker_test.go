@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unwale/url-shortener/internal/domain/model"
+	"github.com/unwale/url-shortener/internal/domain/repository"
+	"github.com/unwale/url-shortener/internal/safehttp"
+)
+
+// memoryWebhooks is a WebhookRepository that keeps webhooks and deliveries in
+// memory and ignores leases and retry delays.
+type memoryWebhooks struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	hooks      []*model.Webhook
+	deliveries []*model.WebhookDelivery
+	retries    map[int64]time.Duration
+}
+
+func newMemoryWebhooks(hooks ...*model.Webhook) *memoryWebhooks {
+	return &memoryWebhooks{
+		hooks:   hooks,
+		retries: make(map[int64]time.Duration),
+	}
+}
+
+func (m *memoryWebhooks) ListSubscribedWebhooks(_ context.Context, owner, eventType string) ([]*model.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var hooks []*model.Webhook
+	for _, hook := range m.hooks {
+		if hook.Owner == owner && (len(hook.EventTypes) == 0 || slices.Contains(hook.EventTypes, eventType)) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (m *memoryWebhooks) EnqueueDelivery(_ context.Context, webhookID int64, event *model.Event, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID && delivery.EventID == event.ID {
+			return nil
+		}
+	}
+	m.deliveries = append(m.deliveries, &model.WebhookDelivery{
+		ID:        int64(len(m.deliveries) + 1),
+		WebhookID: webhookID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Status:    model.DeliveryPending,
+	})
+	return nil
+}
+
+func (m *memoryWebhooks) ClaimDeliveries(_ context.Context, limit int32, _ time.Duration) ([]*model.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []*model.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if len(claimed) == int(limit) {
+			break
+		}
+		if delivery.Status != model.DeliveryPending {
+			continue
+		}
+		delivery.Attempts++
+		for _, hook := range m.hooks {
+			if hook.ID == delivery.WebhookID {
+				delivery.URL, delivery.Secret = hook.URL, hook.Secret
+			}
+		}
+		claimed = append(claimed, delivery)
+	}
+	return claimed, nil
+}
+
+func (m *memoryWebhooks) RecordAttempt(_ context.Context, id int64, status string, responseStatus int, cause string, retryIn time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery := m.deliveries[id-1]
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = cause
+	m.retries[id] = retryIn
+	return nil
+}
+
+var testEvent = &model.Event{
+	ID:         42,
+	Type:       model.EventLinkCreated,
+	ShortUrl:   "exmpl",
+	Owner:      "owner-1",
+	Data:       json.RawMessage(`{"original_url":"https://google.com"}`),
+	OccurredAt: "2025-01-02T03:04:05Z",
+}
+
+func newTestWorker(repo repository.WebhookRepository, client *http.Client) *Worker {
+	w := NewWorker(repo, client, WorkerOptions{
+		BatchSize:    10,
+		PollInterval: time.Millisecond,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return w
+}
+
+func TestDispatcher(t *testing.T) {
+	repo := newMemoryWebhooks(
+		&model.Webhook{ID: 1, Owner: "owner-1"},
+		&model.Webhook{ID: 2, Owner: "owner-1", EventTypes: []string{model.EventLinkDeleted}},
+		&model.Webhook{ID: 3, Owner: "owner-2"},
+	)
+	dispatcher := NewDispatcher(repo)
+
+	require.NoError(t, dispatcher.Send(context.Background(), testEvent))
+	require.NoError(t, dispatcher.Send(context.Background(), testEvent))
+	require.NoError(t, dispatcher.Send(context.Background(), &model.Event{ID: 43, Type: model.EventLinkCreated}))
+
+	require.Len(t, repo.deliveries, 1, "only subscribed webhooks of the owner, once per event")
+	assert.Equal(t, int64(1), repo.deliveries[0].WebhookID)
+	assert.JSONEq(t, `{"id":42,"type":"link.created","short_url":"exmpl","occurred_at":"2025-01-02T03:04:05Z","data":{"original_url":"https://google.com"}}`,
+		string(repo.deliveries[0].Payload))
+}
+
+func TestWorker_DeliversSignedRequests(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := newMemoryWebhooks(&model.Webhook{ID: 1, Owner: "owner-1", URL: server.URL, Secret: "whsec_test"})
+	require.NoError(t, NewDispatcher(repo).Send(context.Background(), testEvent))
+
+	sent, err := newTestWorker(repo, server.Client()).DeliverBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.NotNil(t, received)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, model.EventLinkCreated, received.Header.Get(EventHeader))
+	assert.Equal(t, "1", received.Header.Get(DeliveryHeader))
+	assert.Equal(t, "1700000000", received.Header.Get(TimestampHeader))
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("whsec_test", timestamp, body, received.Header.Get(SignatureHeader)))
+	assert.False(t, Verify("whsec_other", timestamp, body, received.Header.Get(SignatureHeader)))
+	assert.False(t, Verify("whsec_test", timestamp+1, body, received.Header.Get(SignatureHeader)))
+
+	delivery := repo.deliveries[0]
+	assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+}
+
+func TestWorker_RetriesUntilDead(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newMemoryWebhooks(&model.Webhook{ID: 1, Owner: "owner-1", URL: server.URL, Secret: "whsec_test"})
+	require.NoError(t, NewDispatcher(repo).Send(context.Background(), testEvent))
+	worker := newTestWorker(repo, server.Client())
+
+	var delays []time.Duration
+	for range 3 {
+		_, err := worker.DeliverBatch(context.Background())
+		require.NoError(t, err)
+		delays = append(delays, repo.retries[1])
+	}
+
+	delivery := repo.deliveries[0]
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, model.DeliveryDead, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "status 503")
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 0}, delays)
+
+	sent, err := worker.DeliverBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent, "dead deliveries are not retried")
+}
+
+func TestWorker_UnreachableReceiver(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	repo := newMemoryWebhooks(&model.Webhook{ID: 1, Owner: "owner-1", URL: server.URL, Secret: "whsec_test"})
+	require.NoError(t, NewDispatcher(repo).Send(context.Background(), testEvent))
+
+	_, err := newTestWorker(repo, http.DefaultClient).DeliverBatch(context.Background())
+
+	require.NoError(t, err)
+	delivery := repo.deliveries[0]
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.ResponseStatus)
+	assert.NotEmpty(t, delivery.LastError)
+}
+
+func TestWorker_BlockedDestination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	repo := newMemoryWebhooks(&model.Webhook{ID: 1, Owner: "owner-1", URL: server.URL, Secret: "whsec_test"})
+	require.NoError(t, NewDispatcher(repo).Send(context.Background(), testEvent))
+
+	_, err := newTestWorker(repo, safehttp.NewClient(time.Second)).DeliverBatch(context.Background())
+
+	require.NoError(t, err)
+	delivery := repo.deliveries[0]
+	assert.Zero(t, delivery.ResponseStatus, "the test server listens on a loopback address")
+	assert.Equal(t, errBlockedDestination.Error(), delivery.LastError)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 10*time.Second, retryDelay(1))
+	assert.Equal(t, 40*time.Second, retryDelay(3))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}